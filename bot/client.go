@@ -5,6 +5,7 @@ import (
 
 	"github.com/disgoorg/log"
 	"github.com/disgoorg/snowflake/v2"
+	"github.com/gorilla/websocket"
 
 	"github.com/disgoorg/disgo/cache"
 	"github.com/disgoorg/disgo/discord"
@@ -27,6 +28,10 @@ type Client interface {
 	// Close will clean up all disgo internals and close the discord gracefully.
 	Close(ctx context.Context)
 
+	// CloseResumable does the same as Close, but keeps the gateway sessions, so they can be resumed by the next Client which is opened with the same gateway.SessionStore.
+	// Use it to restart the bot without identifying again, for example during a deployment.
+	CloseResumable(ctx context.Context)
+
 	// Token returns the configured bot token.
 	Token() string
 
@@ -54,6 +59,10 @@ type Client interface {
 	// VoiceManager returns the voice.Manager used by the Client.
 	VoiceManager() voice.Manager
 
+	// RequiredIntents returns the minimal gateway.Intents needed to receive all Event(s) the EventListener(s) listen to and to fill the enabled cache.Flags.
+	// Only Event(s) TypedEventListener(s) explicitly listen to are taken into account, EventListener(s) which don't implement TypedEventListener are ignored.
+	RequiredIntents() gateway.Intents
+
 	// OpenGateway connects to the configured gateway.Gateway.
 	OpenGateway(ctx context.Context) error
 
@@ -102,6 +111,13 @@ type Client interface {
 	// MemberChunkingManager returns the MemberChunkingManager used by the Client.
 	MemberChunkingManager() MemberChunkingManager
 
+	// ReadyTracker returns the ReadyTracker used by the Client.
+	ReadyTracker() ReadyTracker
+
+	// WaitReady waits until all shards are READY and their guilds are loaded or timed out.
+	// Open the gateway.Gateway or sharding.ShardManager first. If the context is done before, its error is returned.
+	WaitReady(ctx context.Context) error
+
 	// OpenHTTPServer starts the configured HTTPServer used for interactions over webhooks.
 	OpenHTTPServer() error
 
@@ -132,6 +148,12 @@ type clientImpl struct {
 	caches cache.Caches
 
 	memberChunkingManager MemberChunkingManager
+
+	readyTracker ReadyTracker
+
+	requiredIntentsFunc func(client Client) gateway.Intents
+	// shardManagerIntents are the configured gateway.Intents of the shards, if the Client built the ShardManager
+	shardManagerIntents *gateway.Intents
 }
 
 func (c *clientImpl) Logger() log.Logger {
@@ -139,17 +161,33 @@ func (c *clientImpl) Logger() log.Logger {
 }
 
 func (c *clientImpl) Close(ctx context.Context) {
+	c.close(ctx, false)
+}
+
+func (c *clientImpl) CloseResumable(ctx context.Context) {
+	c.close(ctx, true)
+}
+
+func (c *clientImpl) close(ctx context.Context, resumable bool) {
 	if c.voiceManager != nil {
 		c.voiceManager.Close(ctx)
 	}
 	if c.gateway != nil {
-		c.gateway.Close(ctx)
+		if resumable {
+			c.gateway.CloseWithCode(ctx, websocket.CloseServiceRestart, "Restarting")
+		} else {
+			c.gateway.Close(ctx)
+		}
 	}
 	if c.restServices != nil {
 		c.restServices.Close(ctx)
 	}
 	if c.shardManager != nil {
-		c.shardManager.Close(ctx)
+		if resumable {
+			c.shardManager.CloseResumable(ctx)
+		} else {
+			c.shardManager.Close(ctx)
+		}
 	}
 	if c.httpServer != nil {
 		c.httpServer.Close(ctx)
@@ -195,10 +233,25 @@ func (c *clientImpl) VoiceManager() voice.Manager {
 	return c.voiceManager
 }
 
+func (c *clientImpl) RequiredIntents() gateway.Intents {
+	if c.requiredIntentsFunc == nil {
+		return gateway.IntentsNone
+	}
+	return c.requiredIntentsFunc(c)
+}
+
+// checkIntents logs a warning if the given gateway.Intents are missing any of the RequiredIntents.
+func (c *clientImpl) checkIntents(intents gateway.Intents) {
+	if missing := c.RequiredIntents().Remove(intents); missing != gateway.IntentsNone {
+		c.logger.Warnf("configured intents %d are missing intents %d required by the registered event listeners or cache flags", intents, missing)
+	}
+}
+
 func (c *clientImpl) OpenGateway(ctx context.Context) error {
 	if c.gateway == nil {
 		return discord.ErrNoGateway
 	}
+	c.checkIntents(c.gateway.Intents())
 	return c.gateway.Open(ctx)
 }
 
@@ -214,7 +267,17 @@ func (c *clientImpl) OpenShardManager(ctx context.Context) error {
 	if c.shardManager == nil {
 		return discord.ErrNoShardManager
 	}
+	if c.shardManagerIntents != nil {
+		c.checkIntents(*c.shardManagerIntents)
+	}
 	c.shardManager.Open(ctx)
+	if c.shardManagerIntents == nil {
+		// the intents of a ShardManager which was not built by the Client are only known from its shards
+		for _, shard := range c.shardManager.Shards() {
+			c.checkIntents(shard.Intents())
+			break
+		}
+	}
 	return nil
 }
 
@@ -312,6 +375,14 @@ func (c *clientImpl) MemberChunkingManager() MemberChunkingManager {
 	return c.memberChunkingManager
 }
 
+func (c *clientImpl) ReadyTracker() ReadyTracker {
+	return c.readyTracker
+}
+
+func (c *clientImpl) WaitReady(ctx context.Context) error {
+	return c.readyTracker.WaitReady(ctx)
+}
+
 func (c *clientImpl) OpenHTTPServer() error {
 	if c.httpServer == nil {
 		return discord.ErrNoHTTPServer
@@ -2,8 +2,10 @@ package bot
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/disgoorg/log"
+	"github.com/disgoorg/snowflake/v2"
 
 	"github.com/disgoorg/disgo/cache"
 	"github.com/disgoorg/disgo/discord"
@@ -21,6 +23,7 @@ func DefaultConfig(gatewayHandlers map[gateway.EventType]GatewayEventHandler, ht
 		Logger:                 log.Default(),
 		EventManagerConfigOpts: []EventManagerConfigOpt{WithGatewayHandlers(gatewayHandlers), WithHTTPServerHandler(httpHandler)},
 		MemberChunkingFilter:   MemberChunkingFilterNone,
+		GuildLoadTimeout:       time.Minute,
 	}
 }
 
@@ -35,14 +38,20 @@ type Config struct {
 	EventManager           EventManager
 	EventManagerConfigOpts []EventManagerConfigOpt
 
+	LazyEventDecoding      bool
+	GatewayEventFilterFunc func(client Client) gateway.EventFilterFunc
+	RequiredIntentsFunc    func(client Client) gateway.Intents
+
 	VoiceManager           voice.Manager
 	VoiceManagerConfigOpts []voice.ManagerConfigOpt
 
 	Gateway           gateway.Gateway
 	GatewayConfigOpts []gateway.ConfigOpt
+	GatewayCreateFunc gateway.CreateFunc
 
 	ShardManager           sharding.ShardManager
 	ShardManagerConfigOpts []sharding.ConfigOpt
+	ShardManagerCreateFunc sharding.CreateFunc
 
 	HTTPServer           httpserver.Server
 	PublicKey            string
@@ -53,6 +62,9 @@ type Config struct {
 
 	MemberChunkingManager MemberChunkingManager
 	MemberChunkingFilter  MemberChunkingFilter
+
+	ReadyTracker     ReadyTracker
+	GuildLoadTimeout time.Duration
 }
 
 // ConfigOpt is a type alias for a function that takes a Config and is used to configure your Client.
@@ -124,6 +136,29 @@ func WithEventListenerChan[E Event](c chan<- E) ConfigOpt {
 	return WithEventListeners(NewListenerChan(c))
 }
 
+// WithLazyEventDecoding lets the gateway.Gateway only decode dispatches which are needed by the enabled cache.Flags or
+// are emitted as Event(s) an EventListener listens to. All other dispatches are skipped without being decoded.
+// Only EventListener(s) implementing TypedEventListener, like the ones created by NewListenerFunc or events.ListenerAdapter, allow dispatches to be skipped.
+func WithLazyEventDecoding() ConfigOpt {
+	return func(config *Config) {
+		config.LazyEventDecoding = true
+	}
+}
+
+// WithGatewayEventFilterFunc lets you override the default gateway.EventFilterFunc used for lazy event decoding.
+func WithGatewayEventFilterFunc(gatewayEventFilterFunc func(client Client) gateway.EventFilterFunc) ConfigOpt {
+	return func(config *Config) {
+		config.GatewayEventFilterFunc = gatewayEventFilterFunc
+	}
+}
+
+// WithRequiredIntentsFunc lets you override the func used to compute the gateway.Intents returned by Client.RequiredIntents.
+func WithRequiredIntentsFunc(requiredIntentsFunc func(client Client) gateway.Intents) ConfigOpt {
+	return func(config *Config) {
+		config.RequiredIntentsFunc = requiredIntentsFunc
+	}
+}
+
 // WithGateway lets you inject your own gateway.Gateway.
 func WithGateway(gateway gateway.Gateway) ConfigOpt {
 	return func(config *Config) {
@@ -145,6 +180,14 @@ func WithGatewayConfigOpts(opts ...gateway.ConfigOpt) ConfigOpt {
 	}
 }
 
+// WithGatewayCreateFunc lets you create the gateway.Gateway with your own gateway.CreateFunc.
+// The gateway URL is not fetched from Discord in this case, so the gateway.CreateFunc has to provide it if it needs one.
+func WithGatewayCreateFunc(gatewayCreateFunc gateway.CreateFunc) ConfigOpt {
+	return func(config *Config) {
+		config.GatewayCreateFunc = gatewayCreateFunc
+	}
+}
+
 // WithShardManager lets you inject your own sharding.ShardManager.
 func WithShardManager(shardManager sharding.ShardManager) ConfigOpt {
 	return func(config *Config) {
@@ -159,6 +202,14 @@ func WithDefaultShardManager() ConfigOpt {
 	}
 }
 
+// WithShardManagerCreateFunc lets you create the sharding.ShardManager with your own sharding.CreateFunc, like sharding.NewClusterManager.
+// The sharding.ConfigOpt(s) are passed to it. With a sharding.ClusterManager, the default cache.Caches are partitioned per sharding.Cluster.
+func WithShardManagerCreateFunc(shardManagerCreateFunc sharding.CreateFunc) ConfigOpt {
+	return func(config *Config) {
+		config.ShardManagerCreateFunc = shardManagerCreateFunc
+	}
+}
+
 // WithShardManagerConfigOpts lets you configure the default sharding.ShardManager.
 func WithShardManagerConfigOpts(opts ...sharding.ConfigOpt) ConfigOpt {
 	return func(config *Config) {
@@ -209,6 +260,21 @@ func WithMemberChunkingFilter(memberChunkingFilter MemberChunkingFilter) ConfigO
 	}
 }
 
+// WithReadyTracker lets you inject your own ReadyTracker.
+func WithReadyTracker(readyTracker ReadyTracker) ConfigOpt {
+	return func(config *Config) {
+		config.ReadyTracker = readyTracker
+	}
+}
+
+// WithGuildLoadTimeout sets how long the default ReadyTracker waits for the guilds of a shard to load after its READY. 0 waits forever.
+// Guilds which did not load in time are reported as unavailable by events.ShardReady and no longer delay events.GuildsReady.
+func WithGuildLoadTimeout(guildLoadTimeout time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.GuildLoadTimeout = guildLoadTimeout
+	}
+}
+
 // BuildClient creates a new Client instance with the given token, Config, gateway handlers, http handlers os, name, github & version.
 func BuildClient(token string, config Config, gatewayEventHandlerFunc func(client Client) gateway.EventHandlerFunc, httpServerEventHandlerFunc func(client Client) httpserver.EventHandlerFunc, os string, name string, github string, version string) (Client, error) {
 	if token == "" {
@@ -219,8 +285,9 @@ func BuildClient(token string, config Config, gatewayEventHandlerFunc func(clien
 		return nil, fmt.Errorf("error while getting application id from token: %w", err)
 	}
 	client := &clientImpl{
-		token:  token,
-		logger: config.Logger,
+		token:               token,
+		logger:              config.Logger,
+		requiredIntentsFunc: config.RequiredIntentsFunc,
 	}
 
 	client.applicationID = *id
@@ -253,15 +320,24 @@ func BuildClient(token string, config Config, gatewayEventHandlerFunc func(clien
 	}
 	client.eventManager = config.EventManager
 
-	if config.Gateway == nil && len(config.GatewayConfigOpts) > 0 {
-		var gatewayRs *discord.Gateway
-		gatewayRs, err = client.restServices.GetGateway()
-		if err != nil {
-			return nil, err
+	var gatewayEventFilterOpts []gateway.ConfigOpt
+	if config.LazyEventDecoding && config.GatewayEventFilterFunc != nil {
+		gatewayEventFilterOpts = append(gatewayEventFilterOpts, gateway.WithEventFilter(config.GatewayEventFilterFunc(client)))
+	}
+
+	if config.Gateway == nil && (len(config.GatewayConfigOpts) > 0 || config.GatewayCreateFunc != nil) {
+		gatewayCreateFunc := config.GatewayCreateFunc
+		if gatewayCreateFunc == nil {
+			var gatewayRs *discord.Gateway
+			gatewayRs, err = client.restServices.GetGateway()
+			if err != nil {
+				return nil, err
+			}
+			gatewayCreateFunc = gateway.New
+			config.GatewayConfigOpts = append([]gateway.ConfigOpt{gateway.WithURL(gatewayRs.URL)}, config.GatewayConfigOpts...)
 		}
 
 		config.GatewayConfigOpts = append([]gateway.ConfigOpt{
-			gateway.WithURL(gatewayRs.URL),
 			gateway.WithLogger(client.logger),
 			gateway.WithOS(os),
 			gateway.WithBrowser(name),
@@ -269,13 +345,13 @@ func BuildClient(token string, config Config, gatewayEventHandlerFunc func(clien
 			func(config *gateway.Config) {
 				config.RateRateLimiterConfigOpts = append([]gateway.RateLimiterConfigOpt{gateway.WithRateLimiterLogger(client.logger)}, config.RateRateLimiterConfigOpts...)
 			},
-		}, config.GatewayConfigOpts...)
+		}, append(gatewayEventFilterOpts, config.GatewayConfigOpts...)...)
 
-		config.Gateway = gateway.New(token, gatewayEventHandlerFunc(client), nil, config.GatewayConfigOpts...)
+		config.Gateway = gatewayCreateFunc(token, gatewayEventHandlerFunc(client), nil, config.GatewayConfigOpts...)
 	}
 	client.gateway = config.Gateway
 
-	if config.ShardManager == nil && len(config.ShardManagerConfigOpts) > 0 {
+	if config.ShardManager == nil && (len(config.ShardManagerConfigOpts) > 0 || config.ShardManagerCreateFunc != nil) {
 		var gatewayBotRs *discord.GatewayBot
 		gatewayBotRs, err = client.restServices.GetGatewayBot()
 		if err != nil {
@@ -283,7 +359,7 @@ func BuildClient(token string, config Config, gatewayEventHandlerFunc func(clien
 		}
 
 		shardIDs := make([]int, gatewayBotRs.Shards)
-		for i := 0; i < gatewayBotRs.Shards-1; i++ {
+		for i := 0; i < gatewayBotRs.Shards; i++ {
 			shardIDs[i] = i
 		}
 
@@ -300,13 +376,31 @@ func BuildClient(token string, config Config, gatewayEventHandlerFunc func(clien
 					config.RateRateLimiterConfigOpts = append([]gateway.RateLimiterConfigOpt{gateway.WithRateLimiterLogger(client.logger)}, config.RateRateLimiterConfigOpts...)
 				},
 			),
+			sharding.WithGatewayConfigOpts(gatewayEventFilterOpts...),
 			sharding.WithLogger(client.logger),
+			sharding.WithSessionStartLimit(gatewayBotRs.SessionStartLimit),
 			func(config *sharding.Config) {
-				config.RateRateLimiterConfigOpts = append([]sharding.RateLimiterConfigOpt{sharding.WithRateLimiterLogger(client.logger), sharding.WithMaxConcurrency(gatewayBotRs.SessionStartLimit.MaxConcurrency)}, config.RateRateLimiterConfigOpts...)
+				config.RateRateLimiterConfigOpts = append([]sharding.RateLimiterConfigOpt{sharding.WithRateLimiterLogger(client.logger)}, config.RateRateLimiterConfigOpts...)
+				config.IdentifyBudgetConfigOpts = append([]sharding.IdentifyBudgetConfigOpt{sharding.WithIdentifyBudgetLogger(client.logger)}, config.IdentifyBudgetConfigOpts...)
 			},
 		}, config.ShardManagerConfigOpts...)
 
-		config.ShardManager = sharding.New(token, gatewayEventHandlerFunc(client), config.ShardManagerConfigOpts...)
+		// build the config once, so the opts are not applied again & the intents the shards are going to identify with can be checked before the shards are opened
+		shardManagerConfig := sharding.DefaultConfig()
+		shardManagerConfig.Apply(config.ShardManagerConfigOpts)
+		gatewayConfig := gateway.DefaultConfig()
+		for _, opt := range shardManagerConfig.GatewayConfigOpts {
+			opt(gatewayConfig)
+		}
+		client.shardManagerIntents = &gatewayConfig.Intents
+
+		shardManagerCreateFunc := config.ShardManagerCreateFunc
+		if shardManagerCreateFunc == nil {
+			shardManagerCreateFunc = sharding.New
+		}
+		config.ShardManager = shardManagerCreateFunc(token, gatewayEventHandlerFunc(client), func(config *sharding.Config) {
+			*config = *shardManagerConfig
+		})
 	}
 	client.shardManager = config.ShardManager
 
@@ -324,8 +418,26 @@ func BuildClient(token string, config Config, gatewayEventHandlerFunc func(clien
 	}
 	client.memberChunkingManager = config.MemberChunkingManager
 
+	if config.ReadyTracker == nil {
+		config.ReadyTracker = NewReadyTracker(client, config.Logger, config.GuildLoadTimeout)
+	}
+	client.readyTracker = config.ReadyTracker
+
 	if config.Caches == nil {
-		config.Caches = cache.New(config.CacheConfigOpts...)
+		cacheConfigOpts := config.CacheConfigOpts
+		if clusterManager, ok := config.ShardManager.(sharding.ClusterManager); ok {
+			// give every cluster its own cache partition, so clusters don't contend for the same cache locks. Configured partitions take precedence.
+			var partitions int
+			for clusterID := range clusterManager.Clusters() {
+				if clusterID >= partitions {
+					partitions = clusterID + 1
+				}
+			}
+			cacheConfigOpts = append([]cache.ConfigOpt{cache.WithPartitions(partitions, func(groupID snowflake.ID) int {
+				return clusterManager.ClusterIDByGuild(groupID)
+			})}, cacheConfigOpts...)
+		}
+		config.Caches = cache.New(cacheConfigOpts...)
 	}
 	client.caches = config.Caches
 
@@ -1,8 +1,10 @@
 package bot
 
 import (
+	"reflect"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 
 	"github.com/disgoorg/disgo/gateway"
 	"github.com/disgoorg/disgo/httpserver"
@@ -15,10 +17,12 @@ func NewEventManager(client Client, opts ...EventManagerConfigOpt) EventManager
 	config := DefaultEventManagerConfig()
 	config.Apply(opts)
 
-	return &eventManagerImpl{
+	m := &eventManagerImpl{
 		client: client,
 		config: *config,
 	}
+	m.updateListenerTypes()
+	return m
 }
 
 // EventManager lets you listen for specific events triggered by raw gateway events
@@ -37,6 +41,15 @@ type EventManager interface {
 
 	// DispatchEvent dispatches a new Event to the Client's EventListener(s)
 	DispatchEvent(event Event)
+
+	// ListensTo returns whether any EventListener may receive Event(s) of the given type.
+	// EventListener(s) which don't implement TypedEventListener receive all Event(s).
+	ListensTo(eventType reflect.Type) bool
+
+	// ListensToTyped returns whether any TypedEventListener explicitly listens to Event(s) of the given type.
+	// Unlike ListensTo, EventListener(s) which don't implement TypedEventListener and event types which match every Event, like Event itself, are ignored,
+	// as they don't tell which Event(s) are actually needed.
+	ListensToTyped(eventType reflect.Type) bool
 }
 
 // EventListener is used to create new EventListener to listen to events
@@ -44,6 +57,16 @@ type EventListener interface {
 	OnEvent(event Event)
 }
 
+// TypedEventListener is an EventListener which only receives Event(s) of the types returned by EventTypes.
+// This lets the EventManager know which Event(s) are listened to.
+type TypedEventListener interface {
+	EventListener
+
+	// EventTypes returns the types of the Event(s) the EventListener receives.
+	// Interface types match all Event(s) implementing them.
+	EventTypes() []reflect.Type
+}
+
 // NewListenerFunc returns a new EventListener for the given func(e E)
 func NewListenerFunc[E Event](f func(e E)) EventListener {
 	return &listenerFunc[E]{f: f}
@@ -59,6 +82,10 @@ func (l *listenerFunc[E]) OnEvent(e Event) {
 	}
 }
 
+func (l *listenerFunc[E]) EventTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf((*E)(nil)).Elem()}
+}
+
 // NewListenerChan returns a new EventListener for the given chan<- Event
 func NewListenerChan[E Event](c chan<- E) EventListener {
 	return &listenerChan[E]{c: c}
@@ -74,6 +101,10 @@ func (l *listenerChan[E]) OnEvent(e Event) {
 	}
 }
 
+func (l *listenerChan[E]) EventTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf((*E)(nil)).Elem()}
+}
+
 // Event the basic interface each event implement
 type Event interface {
 	Client() Client
@@ -106,6 +137,29 @@ func (h *genericGatewayEventHandler[T]) HandleGatewayEvent(client Client, sequen
 	}
 }
 
+// NewCustomGatewayEventHandler returns a new GatewayEventHandler for a gateway.EventType unknown to disgo and the handler func.
+// The gateway.EventType needs a decoder registered with gateway.RegisterEventDecoder or gateway.RegisterEventType which decodes it into T.
+func NewCustomGatewayEventHandler[T any](eventType gateway.EventType, handleFunc func(client Client, sequenceNumber int, shardID int, event T)) GatewayEventHandler {
+	return &customGatewayEventHandler[T]{eventType: eventType, handleFunc: handleFunc}
+}
+
+type customGatewayEventHandler[T any] struct {
+	eventType  gateway.EventType
+	handleFunc func(client Client, sequenceNumber int, shardID int, event T)
+}
+
+func (h *customGatewayEventHandler[T]) EventType() gateway.EventType {
+	return h.eventType
+}
+
+func (h *customGatewayEventHandler[T]) HandleGatewayEvent(client Client, sequenceNumber int, shardID int, event gateway.EventData) {
+	if e, ok := event.(gateway.EventCustom); ok {
+		if data, ok := e.Data.(T); ok {
+			h.handleFunc(client, sequenceNumber, shardID, data)
+		}
+	}
+}
+
 // HTTPServerEventHandler is used to handle HTTP Event(s)
 type HTTPServerEventHandler interface {
 	HandleHTTPEvent(client Client, respondFunc httpserver.RespondFunc, event httpserver.EventInteractionCreate)
@@ -114,7 +168,9 @@ type HTTPServerEventHandler interface {
 type eventManagerImpl struct {
 	client          Client
 	eventListenerMu sync.Mutex
-	config          EventManagerConfig
+	// listenerTypes holds the *listenerTypes of the current EventListener(s), so ListensTo doesn't need to lock eventListenerMu
+	listenerTypes atomic.Value
+	config        EventManagerConfig
 
 	mu sync.Mutex
 }
@@ -165,6 +221,7 @@ func (e *eventManagerImpl) AddEventListeners(listeners ...EventListener) {
 	e.eventListenerMu.Lock()
 	defer e.eventListenerMu.Unlock()
 	e.config.EventListeners = append(e.config.EventListeners, listeners...)
+	e.updateListenerTypes()
 }
 
 func (e *eventManagerImpl) RemoveEventListeners(listeners ...EventListener) {
@@ -178,4 +235,65 @@ func (e *eventManagerImpl) RemoveEventListeners(listeners ...EventListener) {
 			}
 		}
 	}
+	e.updateListenerTypes()
+}
+
+// listenerTypes is an immutable snapshot of the Event types the EventListener(s) receive.
+type listenerTypes struct {
+	// all is true if any EventListener receives all Event(s).
+	all   bool
+	types []reflect.Type
+	// listensTo caches the result of ListensTo per Event type.
+	listensTo sync.Map
+}
+
+// updateListenerTypes replaces the listenerTypes snapshot. eventListenerMu has to be held unless the EventManager is not shared yet.
+func (e *eventManagerImpl) updateListenerTypes() {
+	types := &listenerTypes{}
+	for _, listener := range e.config.EventListeners {
+		typedListener, ok := listener.(TypedEventListener)
+		if !ok {
+			types.all = true
+			continue
+		}
+		types.types = append(types.types, typedListener.EventTypes()...)
+	}
+	e.listenerTypes.Store(types)
+}
+
+func (e *eventManagerImpl) ListensTo(eventType reflect.Type) bool {
+	types := e.listenerTypes.Load().(*listenerTypes)
+	if types.all {
+		return true
+	}
+	if listensTo, ok := types.listensTo.Load(eventType); ok {
+		return listensTo.(bool)
+	}
+
+	listensTo := false
+	for _, listenerEventType := range types.types {
+		if eventType == listenerEventType || (listenerEventType.Kind() == reflect.Interface && eventType.Implements(listenerEventType)) {
+			listensTo = true
+			break
+		}
+	}
+	types.listensTo.Store(eventType, listensTo)
+	return listensTo
+}
+
+// eventInterfaceType is the type of the Event interface.
+var eventInterfaceType = reflect.TypeOf((*Event)(nil)).Elem()
+
+func (e *eventManagerImpl) ListensToTyped(eventType reflect.Type) bool {
+	types := e.listenerTypes.Load().(*listenerTypes)
+	for _, listenerEventType := range types.types {
+		if eventType == listenerEventType {
+			return true
+		}
+		// interfaces which every Event implements don't tell anything about the needed Event(s)
+		if listenerEventType.Kind() == reflect.Interface && !eventInterfaceType.Implements(listenerEventType) && eventType.Implements(listenerEventType) {
+			return true
+		}
+	}
+	return false
 }
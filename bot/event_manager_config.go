@@ -77,3 +77,16 @@ func WithHTTPServerHandler(handler HTTPServerEventHandler) EventManagerConfigOpt
 		config.HTTPServerHandler = handler
 	}
 }
+
+// WithCustomGatewayHandlers adds the given GatewayEventHandler(s) to the GatewayEventHandler(s) in the EventManagerConfig.
+// Use NewCustomGatewayEventHandler to handle gateway.EventType(s) unknown to disgo.
+func WithCustomGatewayHandlers(handlers ...GatewayEventHandler) EventManagerConfigOpt {
+	return func(config *EventManagerConfig) {
+		if config.GatewayHandlers == nil {
+			config.GatewayHandlers = map[gateway.EventType]GatewayEventHandler{}
+		}
+		for _, handler := range handlers {
+			config.GatewayHandlers[handler.EventType()] = handler
+		}
+	}
+}
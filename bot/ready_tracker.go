@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/log"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var _ ReadyTracker = (*readyTrackerImpl)(nil)
+
+// NewReadyTracker returns a new ReadyTracker which waits at most guildLoadTimeout for the guilds of a shard to load after its READY.
+func NewReadyTracker(client Client, logger log.Logger, guildLoadTimeout time.Duration) ReadyTracker {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &readyTrackerImpl{
+		client:           client,
+		logger:           logger,
+		guildLoadTimeout: guildLoadTimeout,
+		shards:           map[int]*readyShard{},
+		ready:            make(chan struct{}),
+	}
+}
+
+// ReadyTracker tracks when the shards of a Client are READY and their guilds are loaded.
+// It passes gateway.EventShardReady for every shard and gateway.EventAllShardsReady once for all shards to the EventManager.
+type ReadyTracker interface {
+	// HandleReady handles the gateway.EventReady of a shard with the IDs of its unready guilds.
+	HandleReady(shardID int, guildIDs []snowflake.ID)
+
+	// HandleResumed handles the gateway.EventResumed of a shard. A shard which resumed a stored session without receiving a READY first is ready, as Discord does not send its guilds again.
+	HandleResumed(shardID int)
+
+	// HandleGuildLoaded handles the first GUILD_CREATE of an unready guild of a shard.
+	HandleGuildLoaded(shardID int, guildID snowflake.ID)
+
+	// WaitReady waits until all shards are READY and their guilds are loaded or timed out.
+	// If the context is done before, its error is returned.
+	WaitReady(ctx context.Context) error
+}
+
+type readyTrackerImpl struct {
+	client           Client
+	logger           log.Logger
+	guildLoadTimeout time.Duration
+
+	mu       sync.Mutex
+	shards   map[int]*readyShard
+	allReady bool
+	ready    chan struct{}
+}
+
+type readyShard struct {
+	// shardCount is the shard count the shard had when it received its READY, it changes when resharding.
+	shardCount          int
+	pendingGuildIDs     map[snowflake.ID]struct{}
+	unavailableGuildIDs []snowflake.ID
+	ready               bool
+	timer               *time.Timer
+}
+
+func (t *readyTrackerImpl) HandleReady(shardID int, guildIDs []snowflake.ID) {
+	t.mu.Lock()
+	if shard, ok := t.shards[shardID]; ok && shard.timer != nil {
+		shard.timer.Stop()
+	}
+	shard := &readyShard{
+		shardCount:      t.shardCount(shardID),
+		pendingGuildIDs: make(map[snowflake.ID]struct{}, len(guildIDs)),
+	}
+	for _, guildID := range guildIDs {
+		shard.pendingGuildIDs[guildID] = struct{}{}
+	}
+	t.shards[shardID] = shard
+	if len(shard.pendingGuildIDs) > 0 && t.guildLoadTimeout > 0 {
+		shard.timer = time.AfterFunc(t.guildLoadTimeout, func() {
+			t.timeout(shardID, shard)
+		})
+	}
+	events := t.check(shardID, shard)
+	t.mu.Unlock()
+
+	t.dispatch(shardID, events)
+}
+
+func (t *readyTrackerImpl) HandleResumed(shardID int) {
+	t.mu.Lock()
+	if _, ok := t.shards[shardID]; ok {
+		// the guilds of a shard which received a READY are still loaded or timed out as usual
+		t.mu.Unlock()
+		return
+	}
+	shard := &readyShard{shardCount: t.shardCount(shardID)}
+	t.shards[shardID] = shard
+	events := t.check(shardID, shard)
+	t.mu.Unlock()
+
+	t.dispatch(shardID, events)
+}
+
+func (t *readyTrackerImpl) HandleGuildLoaded(shardID int, guildID snowflake.ID) {
+	t.mu.Lock()
+	shard, ok := t.shards[shardID]
+	if !ok || shard.ready {
+		t.mu.Unlock()
+		return
+	}
+	delete(shard.pendingGuildIDs, guildID)
+	events := t.check(shardID, shard)
+	t.mu.Unlock()
+
+	t.dispatch(shardID, events)
+}
+
+func (t *readyTrackerImpl) WaitReady(ctx context.Context) error {
+	if !t.client.HasGateway() && !t.client.HasShardManager() {
+		return discord.ErrNoGatewayOrShardManager
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.ready:
+		return nil
+	}
+}
+
+// timeout marks the remaining guilds of the shard as unavailable once the guild load timeout passed.
+func (t *readyTrackerImpl) timeout(shardID int, shard *readyShard) {
+	t.mu.Lock()
+	if t.shards[shardID] != shard || shard.ready {
+		t.mu.Unlock()
+		return
+	}
+	for guildID := range shard.pendingGuildIDs {
+		shard.unavailableGuildIDs = append(shard.unavailableGuildIDs, guildID)
+	}
+	t.logger.Warnf("%d guilds of shard %d did not load within %s", len(shard.unavailableGuildIDs), shardID, t.guildLoadTimeout)
+	shard.pendingGuildIDs = nil
+	events := t.check(shardID, shard)
+	t.mu.Unlock()
+
+	t.dispatch(shardID, events)
+}
+
+// check marks the shard as ready once all of its guilds are loaded and returns the events to dispatch. mu must be held.
+func (t *readyTrackerImpl) check(shardID int, shard *readyShard) []gateway.EventData {
+	if len(shard.pendingGuildIDs) > 0 {
+		return nil
+	}
+	shard.ready = true
+	if shard.timer != nil {
+		shard.timer.Stop()
+	}
+	events := []gateway.EventData{gateway.EventShardReady{UnavailableGuildIDs: shard.unavailableGuildIDs}}
+
+	if t.allReady {
+		return events
+	}
+	var unavailableGuildIDs []snowflake.ID
+	for _, id := range t.shardIDs() {
+		s, ok := t.shards[id]
+		if ok && s.shardCount == t.shardCount(id) {
+			if !s.ready {
+				return events
+			}
+			unavailableGuildIDs = append(unavailableGuildIDs, s.unavailableGuildIDs...)
+			continue
+		}
+		// the ShardManager only swaps in resharded shards once their guilds are loaded, their READY is not passed on
+		if !t.resharded(id) {
+			return events
+		}
+	}
+	t.allReady = true
+	close(t.ready)
+	return append(events, gateway.EventAllShardsReady{UnavailableGuildIDs: unavailableGuildIDs})
+}
+
+// shardIDs returns the IDs of all shards of the Client.
+func (t *readyTrackerImpl) shardIDs() []int {
+	if t.client.HasShardManager() {
+		return t.client.ShardManager().ShardIDs()
+	}
+	if t.client.HasGateway() {
+		return []int{t.client.Gateway().ShardID()}
+	}
+	return nil
+}
+
+// shardCount returns the current shard count of the given shard of the Client.
+func (t *readyTrackerImpl) shardCount(shardID int) int {
+	if t.client.HasShardManager() {
+		if shard := t.client.ShardManager().Shard(shardID); shard != nil {
+			return shard.ShardCount()
+		}
+		return 0
+	}
+	if t.client.HasGateway() {
+		return t.client.Gateway().ShardCount()
+	}
+	return 0
+}
+
+// resharded returns whether the given shard belongs to a new shard set, as READY(s) of shards with another shard count were tracked. mu must be held.
+func (t *readyTrackerImpl) resharded(shardID int) bool {
+	shardCount := t.shardCount(shardID)
+	for _, shard := range t.shards {
+		if shard.shardCount != shardCount {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch passes the events to the EventManager without holding mu, as listeners might call WaitReady.
+func (t *readyTrackerImpl) dispatch(shardID int, events []gateway.EventData) {
+	for _, event := range events {
+		switch event.(type) {
+		case gateway.EventShardReady:
+			t.client.EventManager().HandleGatewayEvent(gateway.EventTypeShardReady, 0, shardID, event)
+		case gateway.EventAllShardsReady:
+			t.client.EventManager().HandleGatewayEvent(gateway.EventTypeAllShardsReady, 0, shardID, event)
+		}
+	}
+}
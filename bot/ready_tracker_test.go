@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+type readyTrackerGateway struct {
+	gateway.Gateway
+}
+
+func (readyTrackerGateway) ShardID() int    { return 0 }
+func (readyTrackerGateway) ShardCount() int { return 1 }
+
+type readyTrackerEventManager struct {
+	EventManager
+	mu     sync.Mutex
+	events []gateway.EventData
+}
+
+func (m *readyTrackerEventManager) HandleGatewayEvent(_ gateway.EventType, _ int, _ int, event gateway.EventData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *readyTrackerEventManager) Events() []gateway.EventData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]gateway.EventData(nil), m.events...)
+}
+
+type readyTrackerClient struct {
+	Client
+	eventManager *readyTrackerEventManager
+}
+
+func (c *readyTrackerClient) HasGateway() bool           { return true }
+func (c *readyTrackerClient) HasShardManager() bool      { return false }
+func (c *readyTrackerClient) Gateway() gateway.Gateway   { return readyTrackerGateway{} }
+func (c *readyTrackerClient) EventManager() EventManager { return c.eventManager }
+
+func newTestReadyTracker(guildLoadTimeout time.Duration) (ReadyTracker, *readyTrackerEventManager) {
+	eventManager := &readyTrackerEventManager{}
+	return NewReadyTracker(&readyTrackerClient{eventManager: eventManager}, nil, guildLoadTimeout), eventManager
+}
+
+func assertReady(t *testing.T, tracker ReadyTracker) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, tracker.WaitReady(ctx))
+}
+
+func TestReadyTrackerReady(t *testing.T) {
+	tracker, eventManager := newTestReadyTracker(time.Minute)
+
+	tracker.HandleReady(0, []snowflake.ID{1, 2})
+	tracker.HandleGuildLoaded(0, 1)
+	assert.Empty(t, eventManager.Events())
+
+	tracker.HandleGuildLoaded(0, 2)
+	assertReady(t, tracker)
+	assert.Equal(t, []gateway.EventData{
+		gateway.EventShardReady{},
+		gateway.EventAllShardsReady{},
+	}, eventManager.Events())
+}
+
+func TestReadyTrackerResumed(t *testing.T) {
+	tracker, eventManager := newTestReadyTracker(time.Minute)
+
+	// a shard resuming a stored session never receives a READY
+	tracker.HandleResumed(0)
+	assertReady(t, tracker)
+	assert.Equal(t, []gateway.EventData{
+		gateway.EventShardReady{},
+		gateway.EventAllShardsReady{},
+	}, eventManager.Events())
+
+	// resuming after a READY keeps waiting for the guilds of the READY
+	tracker, eventManager = newTestReadyTracker(time.Minute)
+	tracker.HandleReady(0, []snowflake.ID{1})
+	tracker.HandleResumed(0)
+	assert.Empty(t, eventManager.Events())
+}
+
+func TestReadyTrackerGuildLoadTimeout(t *testing.T) {
+	tracker, eventManager := newTestReadyTracker(50 * time.Millisecond)
+
+	tracker.HandleReady(0, []snowflake.ID{1, 2})
+	tracker.HandleGuildLoaded(0, 1)
+	assertReady(t, tracker)
+	assert.Equal(t, []gateway.EventData{
+		gateway.EventShardReady{UnavailableGuildIDs: []snowflake.ID{2}},
+		gateway.EventAllShardsReady{UnavailableGuildIDs: []snowflake.ID{2}},
+	}, eventManager.Events())
+}
@@ -29,6 +29,11 @@ func DefaultConfig() *Config {
 type Config struct {
 	CacheFlags Flags
 
+	// Partitions is the number of partitions the default GroupedCache(s) are split into. Values below 2 disable partitioning.
+	Partitions int
+	// PartitionFunc decides which partition a guild is stored in. Defaults to PartitionByGuild(Partitions).
+	PartitionFunc PartitionFunc
+
 	SelfUserCache SelfUserCache
 
 	GuildCache       GuildCache
@@ -76,6 +81,9 @@ func (c *Config) Apply(opts []ConfigOpt) {
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.Partitions > 1 && c.PartitionFunc == nil {
+		c.PartitionFunc = PartitionByGuild(c.Partitions)
+	}
 	if c.SelfUserCache == nil {
 		c.SelfUserCache = NewSelfUserCache()
 	}
@@ -86,35 +94,47 @@ func (c *Config) Apply(opts []ConfigOpt) {
 		c.ChannelCache = NewChannelCache(NewCache[discord.GuildChannel](c.CacheFlags, FlagChannels, c.ChannelCachePolicy))
 	}
 	if c.StageInstanceCache == nil {
-		c.StageInstanceCache = NewStageInstanceCache(NewGroupedCache[discord.StageInstance](c.CacheFlags, FlagStageInstances, c.StageInstanceCachePolicy))
+		c.StageInstanceCache = NewStageInstanceCache(newGroupedCache[discord.StageInstance](c, FlagStageInstances, c.StageInstanceCachePolicy))
 	}
 	if c.GuildScheduledEventCache == nil {
-		c.GuildScheduledEventCache = NewGuildScheduledEventCache(NewGroupedCache[discord.GuildScheduledEvent](c.CacheFlags, FlagGuildScheduledEvents, c.GuildScheduledEventCachePolicy))
+		c.GuildScheduledEventCache = NewGuildScheduledEventCache(newGroupedCache[discord.GuildScheduledEvent](c, FlagGuildScheduledEvents, c.GuildScheduledEventCachePolicy))
 	}
 	if c.RoleCache == nil {
-		c.RoleCache = NewRoleCache(NewGroupedCache[discord.Role](c.CacheFlags, FlagRoles, c.RoleCachePolicy))
+		c.RoleCache = NewRoleCache(newGroupedCache[discord.Role](c, FlagRoles, c.RoleCachePolicy))
 	}
 	if c.MemberCache == nil {
-		c.MemberCache = NewMemberCache(NewGroupedCache[discord.Member](c.CacheFlags, FlagMembers, c.MemberCachePolicy))
+		c.MemberCache = NewMemberCache(newGroupedCache[discord.Member](c, FlagMembers, c.MemberCachePolicy))
 	}
 	if c.ThreadMemberCache == nil {
-		c.ThreadMemberCache = NewThreadMemberCache(NewGroupedCache[discord.ThreadMember](c.CacheFlags, FlagThreadMembers, c.ThreadMemberCachePolicy))
+		c.ThreadMemberCache = NewThreadMemberCache(newGroupedCache[discord.ThreadMember](c, FlagThreadMembers, c.ThreadMemberCachePolicy))
 	}
 	if c.PresenceCache == nil {
-		c.PresenceCache = NewPresenceCache(NewGroupedCache[discord.Presence](c.CacheFlags, FlagPresences, c.PresenceCachePolicy))
+		c.PresenceCache = NewPresenceCache(newGroupedCache[discord.Presence](c, FlagPresences, c.PresenceCachePolicy))
 	}
 	if c.VoiceStateCache == nil {
-		c.VoiceStateCache = NewVoiceStateCache(NewGroupedCache[discord.VoiceState](c.CacheFlags, FlagVoiceStates, c.VoiceStateCachePolicy))
+		c.VoiceStateCache = NewVoiceStateCache(newGroupedCache[discord.VoiceState](c, FlagVoiceStates, c.VoiceStateCachePolicy))
 	}
 	if c.MessageCache == nil {
-		c.MessageCache = NewMessageCache(NewGroupedCache[discord.Message](c.CacheFlags, FlagMessages, c.MessageCachePolicy))
+		c.MessageCache = NewMessageCache(newGroupedCache[discord.Message](c, FlagMessages, c.MessageCachePolicy))
 	}
 	if c.EmojiCache == nil {
-		c.EmojiCache = NewEmojiCache(NewGroupedCache[discord.Emoji](c.CacheFlags, FlagEmojis, c.EmojiCachePolicy))
+		c.EmojiCache = NewEmojiCache(newGroupedCache[discord.Emoji](c, FlagEmojis, c.EmojiCachePolicy))
 	}
 	if c.StickerCache == nil {
-		c.StickerCache = NewStickerCache(NewGroupedCache[discord.Sticker](c.CacheFlags, FlagStickers, c.StickerCachePolicy))
+		c.StickerCache = NewStickerCache(newGroupedCache[discord.Sticker](c, FlagStickers, c.StickerCachePolicy))
+	}
+}
+
+// newGroupedCache returns a new default GroupedCache which is partitioned if configured.
+func newGroupedCache[T any](c *Config, neededFlags Flags, policy Policy[T]) GroupedCache[T] {
+	if c.Partitions <= 1 {
+		return NewGroupedCache[T](c.CacheFlags, neededFlags, policy)
 	}
+	partitions := make([]GroupedCache[T], c.Partitions)
+	for i := range partitions {
+		partitions[i] = NewGroupedCache[T](c.CacheFlags, neededFlags, policy)
+	}
+	return NewPartitionedGroupedCache[T](c.PartitionFunc, partitions...)
 }
 
 // WithCaches sets the Flags of the Config.
@@ -124,6 +144,15 @@ func WithCaches(flags ...Flags) ConfigOpt {
 	}
 }
 
+// WithPartitions splits the default GroupedCache(s) into the given number of partitions, so guilds in different partitions do not contend for the same lock.
+// partitionFunc decides which partition a guild is stored in, nil defaults to PartitionByGuild(partitions).
+func WithPartitions(partitions int, partitionFunc PartitionFunc) ConfigOpt {
+	return func(config *Config) {
+		config.Partitions = partitions
+		config.PartitionFunc = partitionFunc
+	}
+}
+
 // WithGuildCachePolicy sets the Policy[discord.Guild] of the Config.
 func WithGuildCachePolicy(policy Policy[discord.Guild]) ConfigOpt {
 	return func(config *Config) {
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// PartitionFunc returns the partition of the given groupID in the range [0, partitions).
+// Results outside of the range are wrapped into it.
+// The groupID is the guild ID for most caches, the channel ID for messages and the thread ID for thread members.
+type PartitionFunc func(groupID snowflake.ID) int
+
+// PartitionByGuild returns a PartitionFunc which distributes guilds over the given number of partitions the same way Discord distributes guilds over shards.
+// With as many partitions as shards, every shard gets its own partition. Less than 1 partition is treated as 1 partition.
+func PartitionByGuild(partitions int) PartitionFunc {
+	if partitions < 1 {
+		partitions = 1
+	}
+	return func(groupID snowflake.ID) int {
+		return int((uint64(groupID) >> 22) % uint64(partitions))
+	}
+}
+
+var _ GroupedCache[any] = (*partitionedGroupedCache[any])(nil)
+
+// NewPartitionedGroupedCache returns a GroupedCache which stores every group in one of the given GroupedCache(s) chosen by the PartitionFunc.
+// As every partition has its own lock, writes to one partition do not block reads & writes to other partitions.
+func NewPartitionedGroupedCache[T any](partitionFunc PartitionFunc, partitions ...GroupedCache[T]) GroupedCache[T] {
+	return &partitionedGroupedCache[T]{
+		partitionFunc: partitionFunc,
+		partitions:    partitions,
+	}
+}
+
+type partitionedGroupedCache[T any] struct {
+	partitionFunc PartitionFunc
+	partitions    []GroupedCache[T]
+}
+
+func (c *partitionedGroupedCache[T]) partition(groupID snowflake.ID) GroupedCache[T] {
+	i := c.partitionFunc(groupID) % len(c.partitions)
+	if i < 0 {
+		i += len(c.partitions)
+	}
+	return c.partitions[i]
+}
+
+func (c *partitionedGroupedCache[T]) Get(groupID snowflake.ID, id snowflake.ID) (T, bool) {
+	return c.partition(groupID).Get(groupID, id)
+}
+
+func (c *partitionedGroupedCache[T]) Put(groupID snowflake.ID, id snowflake.ID, entity T) {
+	c.partition(groupID).Put(groupID, id, entity)
+}
+
+func (c *partitionedGroupedCache[T]) Remove(groupID snowflake.ID, id snowflake.ID) (T, bool) {
+	return c.partition(groupID).Remove(groupID, id)
+}
+
+func (c *partitionedGroupedCache[T]) GroupRemove(groupID snowflake.ID) {
+	c.partition(groupID).GroupRemove(groupID)
+}
+
+func (c *partitionedGroupedCache[T]) RemoveIf(filterFunc GroupedFilterFunc[T]) {
+	for _, partition := range c.partitions {
+		partition.RemoveIf(filterFunc)
+	}
+}
+
+func (c *partitionedGroupedCache[T]) GroupRemoveIf(groupID snowflake.ID, filterFunc GroupedFilterFunc[T]) {
+	c.partition(groupID).GroupRemoveIf(groupID, filterFunc)
+}
+
+func (c *partitionedGroupedCache[T]) Len() int {
+	var totalLen int
+	for _, partition := range c.partitions {
+		totalLen += partition.Len()
+	}
+	return totalLen
+}
+
+func (c *partitionedGroupedCache[T]) GroupLen(groupID snowflake.ID) int {
+	return c.partition(groupID).GroupLen(groupID)
+}
+
+func (c *partitionedGroupedCache[T]) ForEach(forEachFunc func(groupID snowflake.ID, entity T)) {
+	for _, partition := range c.partitions {
+		partition.ForEach(forEachFunc)
+	}
+}
+
+func (c *partitionedGroupedCache[T]) GroupForEach(groupID snowflake.ID, forEachFunc func(entity T)) {
+	c.partition(groupID).GroupForEach(groupID, forEachFunc)
+}
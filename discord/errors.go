@@ -10,8 +10,12 @@ var (
 	ErrNoShardManager          = errors.New("no shard manager configured")
 	ErrNoGateway               = errors.New("no gateway configured")
 	ErrGatewayAlreadyConnected = errors.New("gateway is already connected")
+	ErrGatewayReconnectFailed  = errors.New("gateway gave up reconnecting")
 	ErrShardNotConnected       = errors.New("shard is not connected")
 	ErrShardNotFound           = errors.New("shard not found in shard manager")
+	ErrIdentifyBudgetExhausted = errors.New("identify budget is exhausted until the session start limit resets")
+	ErrShardManagerResharding  = errors.New("shard manager is already resharding")
+	ErrReshardNotSupported     = errors.New("resharding is not supported by this shard manager")
 	ErrGatewayCompressedData   = errors.New("disgo does not currently support compressed gateway data")
 	ErrNoHTTPServer            = errors.New("no http server configured")
 
@@ -29,6 +33,8 @@ var (
 
 	ErrCheckFailed = errors.New("check failed")
 
+	ErrNoResponse = errors.New("middleware returned no response")
+
 	ErrMemberMustBeConnectedToChannel = errors.New("the member must be connected to the channel")
 
 	ErrStickerTypeGuild = errors.New("sticker type must be of type StickerTypeGuild")
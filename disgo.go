@@ -97,6 +97,8 @@ func getOS() string {
 // New creates a new bot.Client with the provided token & bot.ConfigOpt(s)
 func New(token string, opts ...bot.ConfigOpt) (bot.Client, error) {
 	config := bot.DefaultConfig(handlers.GetGatewayHandlers(), handlers.GetHTTPServerHandler())
+	config.GatewayEventFilterFunc = handlers.DefaultGatewayEventFilterFunc
+	config.RequiredIntentsFunc = handlers.RequiredIntents
 	config.Apply(opts)
 
 	return bot.BuildClient(token,
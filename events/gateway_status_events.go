@@ -12,3 +12,34 @@ type Ready struct {
 type Resumed struct {
 	*GenericEvent
 }
+
+// GatewayStatusChange indicates the gateway.Status of the gateway.Gateway changed
+type GatewayStatusChange struct {
+	*GenericEvent
+	gateway.StatusChange
+}
+
+// ShardStatusChange indicates the gateway.Status of a shard managed by the sharding.ShardManager changed
+type ShardStatusChange struct {
+	*GenericEvent
+	gateway.StatusChange
+}
+
+// IdentifyBudgetLow indicates the sharding.ShardManager is identifying a shard with only few identifies left until the session start limit resets.
+// Once the budget is exhausted, identifies are delayed or refused.
+type IdentifyBudgetLow struct {
+	*GenericEvent
+	gateway.EventIdentifyBudgetLow
+}
+
+// ShardReady indicates a shard received its Ready and its discord.Guild(s) are loaded or timed out.
+type ShardReady struct {
+	*GenericEvent
+	gateway.EventShardReady
+}
+
+// AllShardsReady is called once when all shards received their Ready and their discord.Guild(s) are loaded or timed out. See bot.Client.WaitReady.
+type AllShardsReady struct {
+	*GenericEvent
+	gateway.EventAllShardsReady
+}
@@ -44,7 +44,7 @@ type GuildReady struct {
 	*GenericGuild
 }
 
-// GuildsReady is called when all discord.Guild(s) are loaded after logging in
+// GuildsReady is called when all discord.Guild(s) are loaded or timed out after logging in, see bot.WithGuildLoadTimeout
 type GuildsReady struct {
 	*GenericEvent
 }
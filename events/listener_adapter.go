@@ -1,10 +1,12 @@
 package events
 
 import (
+	"reflect"
+
 	"github.com/disgoorg/disgo/bot"
 )
 
-var _ bot.EventListener = (*ListenerAdapter)(nil)
+var _ bot.TypedEventListener = (*ListenerAdapter)(nil)
 
 // ListenerAdapter lets you override the handles for receiving events
 type ListenerAdapter struct {
@@ -68,8 +70,13 @@ type ListenerAdapter struct {
 	OnStickerDelete  func(event *StickerDelete)
 
 	// gateway status Events
-	OnReady   func(event *Ready)
-	OnResumed func(event *Resumed)
+	OnReady               func(event *Ready)
+	OnResumed             func(event *Resumed)
+	OnGatewayStatusChange func(event *GatewayStatusChange)
+	OnShardStatusChange   func(event *ShardStatusChange)
+	OnIdentifyBudgetLow   func(event *IdentifyBudgetLow)
+	OnShardReady          func(event *ShardReady)
+	OnAllShardsReady      func(event *AllShardsReady)
 
 	// Guild Events
 	OnGuildJoin                func(event *GuildJoin)
@@ -170,6 +177,20 @@ type ListenerAdapter struct {
 	OnGuildWebhooksUpdate func(event *WebhooksUpdate)
 }
 
+// EventTypes returns the types of the Event(s) which have a handler set.
+func (l *ListenerAdapter) EventTypes() []reflect.Type {
+	var eventTypes []reflect.Type
+	v := reflect.ValueOf(l).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Func || field.IsNil() {
+			continue
+		}
+		eventTypes = append(eventTypes, field.Type().In(0))
+	}
+	return eventTypes
+}
+
 // OnEvent is getting called everytime we receive an event
 func (l *ListenerAdapter) OnEvent(event bot.Event) {
 	switch e := event.(type) {
@@ -343,6 +364,26 @@ func (l *ListenerAdapter) OnEvent(event bot.Event) {
 		if listener := l.OnResumed; listener != nil {
 			listener(e)
 		}
+	case *GatewayStatusChange:
+		if listener := l.OnGatewayStatusChange; listener != nil {
+			listener(e)
+		}
+	case *ShardStatusChange:
+		if listener := l.OnShardStatusChange; listener != nil {
+			listener(e)
+		}
+	case *IdentifyBudgetLow:
+		if listener := l.OnIdentifyBudgetLow; listener != nil {
+			listener(e)
+		}
+	case *ShardReady:
+		if listener := l.OnShardReady; listener != nil {
+			listener(e)
+		}
+	case *AllShardsReady:
+		if listener := l.OnAllShardsReady; listener != nil {
+			listener(e)
+		}
 
 	// Guild Events
 	case *GuildJoin:
@@ -0,0 +1,144 @@
+// Package bus bridges gateway.Gateway dispatches to worker processes, so a bot can be split into a gateway process and worker processes.
+//
+// The gateway process publishes all dispatches to a Publisher, which is a gateway.Sink, and sends the commands of the workers to its shards:
+//
+//	publisher, _ := bus.Listen(":4000")
+//	client, _ := disgo.New(token, bot.WithShardManagerConfigOpts(
+//		sharding.WithGatewayConfigOpts(gateway.WithSink(publisher)),
+//	))
+//	publisher.HandleCommands(bus.ShardManagerCommandHandler(client.ShardManager(), client.Logger()))
+//
+// Workers consume the dispatches with a Consumer and run the normal bot.Client pipeline without opening a websocket:
+//
+//	consumer, _ := bus.Dial(ctx, "gateway:4000")
+//	client, _ := disgo.New(token, bot.WithGatewayCreateFunc(bus.GatewayCreateFunc(consumer)))
+//	_ = client.OpenGateway(ctx)
+package bus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/disgoorg/log"
+
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/disgo/sharding"
+)
+
+type (
+	// DispatchHandlerFunc is called for every gateway.Dispatch a Consumer receives.
+	DispatchHandlerFunc func(dispatch gateway.Dispatch)
+
+	// CommandHandlerFunc is called for every command a Publisher receives from its consumers.
+	CommandHandlerFunc func(command gateway.Message)
+)
+
+// AllShards can be passed to Consumer.Consume to receive the dispatches of all shards.
+const AllShards = -1
+
+// Publisher is the gateway process side of a bus. It publishes the dispatches of the gateway.Gateway(s) to its consumers and receives their commands.
+type Publisher interface {
+	gateway.Sink
+
+	// HandleCommands sets the CommandHandlerFunc which is called for every command sent by a Consumer.
+	HandleCommands(handler CommandHandlerFunc)
+}
+
+// Consumer is the worker process side of a bus. It receives the published dispatches and sends commands back to the gateway process.
+type Consumer interface {
+	// Consume sets the DispatchHandlerFunc which is called for every published gateway.Dispatch of the given shard, or of all shards for AllShards.
+	// Each shard has its own DispatchHandlerFunc, so multiple gateway.Gateway(s) can consume from the same Consumer. A nil handler removes the one of the shard.
+	Consume(shardID int, handler DispatchHandlerFunc)
+
+	// SendCommand sends the command to the gateway process.
+	SendCommand(ctx context.Context, command gateway.Message) error
+}
+
+// dispatchHandlers routes dispatches to the DispatchHandlerFunc of their shard & the one of AllShards.
+type dispatchHandlers struct {
+	mu       sync.RWMutex
+	handlers map[int]DispatchHandlerFunc
+}
+
+func (h *dispatchHandlers) set(shardID int, handler DispatchHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if handler == nil {
+		delete(h.handlers, shardID)
+		return
+	}
+	if h.handlers == nil {
+		h.handlers = map[int]DispatchHandlerFunc{}
+	}
+	h.handlers[shardID] = handler
+}
+
+func (h *dispatchHandlers) dispatch(dispatch gateway.Dispatch) {
+	h.mu.RLock()
+	shardHandler := h.handlers[dispatch.ShardID]
+	allHandler := h.handlers[AllShards]
+	h.mu.RUnlock()
+	if shardHandler != nil {
+		shardHandler(dispatch)
+	}
+	if allHandler != nil {
+		allHandler(dispatch)
+	}
+}
+
+// allowedCommand returns whether consumers are allowed to send commands with the given gateway.Opcode.
+// Everything related to the connection itself is managed by the gateway process.
+func allowedCommand(op gateway.Opcode) bool {
+	switch op {
+	case gateway.OpcodePresenceUpdate, gateway.OpcodeVoiceStateUpdate, gateway.OpcodeRequestGuildMembers:
+		return true
+	default:
+		return false
+	}
+}
+
+// GatewayCommandHandler returns a CommandHandlerFunc which sends all commands to the given gateway.Gateway.
+func GatewayCommandHandler(g gateway.Gateway, logger log.Logger) CommandHandlerFunc {
+	return func(command gateway.Message) {
+		if !allowedCommand(command.Op) {
+			logger.Warnf("dropped command with opcode %d from bus consumer", command.Op)
+			return
+		}
+		if err := g.Send(context.TODO(), command.Op, command.D); err != nil {
+			logger.Errorf("failed to send command with opcode %d from bus consumer: %s", command.Op, err)
+		}
+	}
+}
+
+// ShardManagerCommandHandler returns a CommandHandlerFunc which sends commands to the shard of their guild.
+// Commands without a guild, like presence updates, are sent to all shards.
+func ShardManagerCommandHandler(shardManager sharding.ShardManager, logger log.Logger) CommandHandlerFunc {
+	return func(command gateway.Message) {
+		if !allowedCommand(command.Op) {
+			logger.Warnf("dropped command with opcode %d from bus consumer", command.Op)
+			return
+		}
+
+		var shards []gateway.Gateway
+		switch d := command.D.(type) {
+		case gateway.MessageDataVoiceStateUpdate:
+			shards = append(shards, shardManager.ShardByGuildID(d.GuildID))
+		case gateway.MessageDataRequestGuildMembers:
+			shards = append(shards, shardManager.ShardByGuildID(d.GuildID))
+		default:
+			for _, shard := range shardManager.Shards() {
+				shards = append(shards, shard)
+			}
+		}
+
+		for _, shard := range shards {
+			if shard == nil {
+				logger.Errorf("no shard found for command with opcode %d from bus consumer", command.Op)
+				continue
+			}
+			if err := shard.Send(context.TODO(), command.Op, command.D); err != nil {
+				logger.Errorf("failed to send command with opcode %d from bus consumer to shard %d: %s", command.Op, shard.ShardID(), err)
+			}
+		}
+	}
+}
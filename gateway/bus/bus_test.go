@@ -0,0 +1,148 @@
+package bus
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+func TestTCPBus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	publisher, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	commands := make(chan gateway.Message, 1)
+	publisher.HandleCommands(func(command gateway.Message) {
+		commands <- command
+	})
+
+	consumer, err := Dial(ctx, publisher.Addr().String())
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	events := make(chan gateway.EventType, 1)
+	g := GatewayCreateFunc(consumer)("", func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+		events <- eventType
+	}, nil)
+	require.NoError(t, g.Open(ctx))
+
+	// wait until the publisher accepted the consumer
+	for {
+		publisher.mu.Lock()
+		n := len(publisher.conns)
+		publisher.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.NoError(t, publisher.Publish(gateway.Dispatch{
+		Sequence:  1,
+		EventType: gateway.EventTypeMessageDelete,
+		Data:      []byte(`{"id":"1","channel_id":"2"}`),
+	}))
+	select {
+	case eventType := <-events:
+		assert.Equal(t, gateway.EventTypeMessageDelete, eventType)
+	case <-ctx.Done():
+		t.Fatal("dispatch was not consumed")
+	}
+	assert.Equal(t, 1, *g.LastSequenceReceived())
+
+	require.NoError(t, g.Send(ctx, gateway.OpcodePresenceUpdate, gateway.MessageDataPresenceUpdate{Status: "idle"}))
+	select {
+	case command := <-commands:
+		assert.Equal(t, gateway.OpcodePresenceUpdate, command.Op)
+		assert.Equal(t, gateway.MessageDataPresenceUpdate{Status: "idle"}, command.D)
+	case <-ctx.Done():
+		t.Fatal("command was not received")
+	}
+
+	assert.Error(t, g.Send(ctx, gateway.OpcodeIdentify, gateway.MessageDataIdentify{}))
+}
+
+func TestTCPBusReplayState(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	publisher, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	require.NoError(t, publisher.Publish(gateway.Dispatch{
+		Sequence:  1,
+		EventType: gateway.EventTypeReady,
+		Data:      []byte(`{"v":10,"user":{"id":"1"},"guilds":[{"id":"2","unavailable":true},{"id":"3","unavailable":true}],"session_id":"session"}`),
+	}))
+	require.NoError(t, publisher.Publish(gateway.Dispatch{
+		Sequence:  2,
+		EventType: gateway.EventTypeGuildCreate,
+		Data:      []byte(`{"id":"2","name":"guild"}`),
+	}))
+	require.NoError(t, publisher.Publish(gateway.Dispatch{
+		Sequence:  3,
+		EventType: gateway.EventTypeGuildCreate,
+		Data:      []byte(`{"id":"3","name":"guild"}`),
+	}))
+	require.NoError(t, publisher.Publish(gateway.Dispatch{
+		Sequence:  4,
+		EventType: gateway.EventTypeGuildDelete,
+		Data:      []byte(`{"id":"3"}`),
+	}))
+
+	// read the frames directly, so none are missed before a DispatchHandlerFunc is set
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", publisher.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	require.NoError(t, conn.SetReadDeadline(deadline))
+
+	var sequences []int
+	_ = (&tcpConn{conn: conn}).read(func(f frame) error {
+		sequences = append(sequences, f.Dispatch.Sequence)
+		if len(sequences) == 2 {
+			return io.EOF
+		}
+		return nil
+	})
+	assert.Equal(t, []int{1, 2}, sequences)
+}
+
+func TestLocalBusRoutesDispatchesByShard(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	local := NewLocal()
+
+	shardIDs := make([][]int, 2)
+	for i := range shardIDs {
+		i := i
+		g := GatewayCreateFunc(local)("", func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+			shardIDs[i] = append(shardIDs[i], shardID)
+		}, nil, gateway.WithShardID(i), gateway.WithShardCount(2))
+		require.NoError(t, g.Open(ctx))
+		defer g.Close(ctx)
+	}
+
+	for i, shardID := range []int{0, 1, 1} {
+		require.NoError(t, local.Publish(gateway.Dispatch{
+			ShardID:   shardID,
+			Sequence:  i + 1,
+			EventType: gateway.EventTypeMessageDelete,
+			Data:      []byte(`{"id":"1","channel_id":"2"}`),
+		}))
+	}
+	assert.Equal(t, [][]int{{0}, {1, 1}}, shardIDs)
+}
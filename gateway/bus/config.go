@@ -0,0 +1,88 @@
+package bus
+
+import (
+	"time"
+
+	"github.com/disgoorg/log"
+)
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() *Config {
+	return &Config{
+		Logger:             log.Default(),
+		WriteTimeout:       5 * time.Second,
+		QueueSize:          1024,
+		SlowConsumerPolicy: SlowConsumerPolicyDisconnect,
+		ReplayState:        true,
+	}
+}
+
+// SlowConsumerPolicy decides what a TCPPublisher does with a consumer whose queue is full.
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerPolicyDisconnect disconnects consumers whose queue is full, so they notice that they missed dispatches.
+	SlowConsumerPolicyDisconnect SlowConsumerPolicy = iota
+
+	// SlowConsumerPolicyDrop drops dispatches for consumers whose queue is full.
+	SlowConsumerPolicyDrop
+)
+
+// Config lets you configure the TCP Publisher & Consumer.
+type Config struct {
+	// Logger is the logger of the bus. Defaults to log.Default().
+	Logger log.Logger
+	// WriteTimeout is the maximum time a write to a connection may take. Consumers which are too slow are disconnected. Defaults to 5 seconds.
+	WriteTimeout time.Duration
+	// QueueSize is the number of dispatches the TCPPublisher queues per consumer. Defaults to 1024.
+	QueueSize int
+	// SlowConsumerPolicy decides what happens to consumers whose queue is full. Defaults to SlowConsumerPolicyDisconnect.
+	SlowConsumerPolicy SlowConsumerPolicy
+	// ReplayState makes the TCPPublisher replay the last READY & GUILD_CREATE dispatches to consumers which connect later. Defaults to true.
+	ReplayState bool
+}
+
+// ConfigOpt is a type alias for a function that takes a Config and is used to configure the TCP Publisher & Consumer.
+type ConfigOpt func(config *Config)
+
+// Apply applies the given ConfigOpt(s) to the Config
+func (c *Config) Apply(opts []ConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithLogger sets the Logger of the bus.
+func WithLogger(logger log.Logger) ConfigOpt {
+	return func(config *Config) {
+		config.Logger = logger
+	}
+}
+
+// WithWriteTimeout sets the maximum time a write to a connection may take.
+func WithWriteTimeout(writeTimeout time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.WriteTimeout = writeTimeout
+	}
+}
+
+// WithQueueSize sets the number of dispatches the TCPPublisher queues per consumer.
+func WithQueueSize(queueSize int) ConfigOpt {
+	return func(config *Config) {
+		config.QueueSize = queueSize
+	}
+}
+
+// WithSlowConsumerPolicy sets what happens to consumers whose queue is full.
+func WithSlowConsumerPolicy(policy SlowConsumerPolicy) ConfigOpt {
+	return func(config *Config) {
+		config.SlowConsumerPolicy = policy
+	}
+}
+
+// WithReplayState sets whether the TCPPublisher replays the last READY & GUILD_CREATE dispatches to consumers which connect later.
+func WithReplayState(replayState bool) ConfigOpt {
+	return func(config *Config) {
+		config.ReplayState = replayState
+	}
+}
@@ -0,0 +1,178 @@
+package bus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var _ gateway.Gateway = (*consumerGatewayImpl)(nil)
+
+// GatewayCreateFunc returns a gateway.CreateFunc which creates a gateway.Gateway consuming the dispatches of the given Consumer instead of connecting to Discord.
+// Commands sent via gateway.Gateway.Send are forwarded to the gateway process with Consumer.SendCommand.
+// Use it with bot.WithGatewayCreateFunc. The ShardID, ShardCount, Intents, EventFilter, EnableRawEvents & Logger of the gateway.Config are respected.
+func GatewayCreateFunc(consumer Consumer) gateway.CreateFunc {
+	return func(token string, eventHandlerFunc gateway.EventHandlerFunc, closeHandlerFunc gateway.CloseHandlerFunc, opts ...gateway.ConfigOpt) gateway.Gateway {
+		config := gateway.DefaultConfig()
+		config.Apply(opts)
+
+		return &consumerGatewayImpl{
+			config:           *config,
+			consumer:         consumer,
+			eventHandlerFunc: eventHandlerFunc,
+			status:           gateway.StatusUnconnected,
+		}
+	}
+}
+
+type consumerGatewayImpl struct {
+	config           gateway.Config
+	consumer         Consumer
+	eventHandlerFunc gateway.EventHandlerFunc
+
+	mu                   sync.Mutex
+	status               gateway.Status
+	sessionID            *string
+	lastSequenceReceived *int
+	presence             *gateway.MessageDataPresenceUpdate
+}
+
+func (g *consumerGatewayImpl) ShardID() int {
+	return g.config.ShardID
+}
+
+func (g *consumerGatewayImpl) ShardCount() int {
+	return g.config.ShardCount
+}
+
+func (g *consumerGatewayImpl) SessionID() *string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.sessionID
+}
+
+func (g *consumerGatewayImpl) LastSequenceReceived() *int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastSequenceReceived
+}
+
+func (g *consumerGatewayImpl) Intents() gateway.Intents {
+	return g.config.Intents
+}
+
+func (g *consumerGatewayImpl) Open(_ context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.status == gateway.StatusReady {
+		return discord.ErrGatewayAlreadyConnected
+	}
+	g.status = gateway.StatusReady
+	g.consumer.Consume(g.consumeShardID(), g.dispatch)
+	return nil
+}
+
+// consumeShardID returns the shard to consume the dispatches of. Without sharding, the dispatches of all shards are consumed.
+func (g *consumerGatewayImpl) consumeShardID() int {
+	if g.config.ShardCount > 1 {
+		return g.config.ShardID
+	}
+	return AllShards
+}
+
+func (g *consumerGatewayImpl) Close(_ context.Context) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.status != gateway.StatusReady {
+		return
+	}
+	g.consumer.Consume(g.consumeShardID(), nil)
+	g.status = gateway.StatusDisconnected
+}
+
+func (g *consumerGatewayImpl) CloseWithCode(ctx context.Context, _ int, _ string) {
+	g.Close(ctx)
+}
+
+func (g *consumerGatewayImpl) Status() gateway.Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status
+}
+
+func (g *consumerGatewayImpl) Send(ctx context.Context, op gateway.Opcode, data gateway.MessageData) error {
+	if !allowedCommand(op) {
+		return fmt.Errorf("opcode %d can not be sent through the bus", op)
+	}
+	if err := gateway.ValidateMessageData(op, data, g.config.Intents); err != nil {
+		return err
+	}
+	if err := g.consumer.SendCommand(ctx, gateway.Message{Op: op, D: data}); err != nil {
+		return err
+	}
+	if presence, ok := data.(gateway.MessageDataPresenceUpdate); ok {
+		g.mu.Lock()
+		g.presence = &presence
+		g.mu.Unlock()
+	}
+	return nil
+}
+
+func (g *consumerGatewayImpl) Latency() time.Duration {
+	return 0
+}
+
+func (g *consumerGatewayImpl) MissedHeartbeatAcks() int {
+	return 0
+}
+
+func (g *consumerGatewayImpl) QueueDepth(_ gateway.SendLane) int {
+	return 0
+}
+
+func (g *consumerGatewayImpl) Presence() *gateway.MessageDataPresenceUpdate {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.presence
+}
+
+func (g *consumerGatewayImpl) dispatch(dispatch gateway.Dispatch) {
+	g.mu.Lock()
+	g.lastSequenceReceived = &dispatch.Sequence
+	g.mu.Unlock()
+
+	decode := g.config.EventFilter == nil || dispatch.EventType == gateway.EventTypeReady || g.config.EventFilter(dispatch.EventType)
+
+	var eventData gateway.EventData
+	if decode {
+		var err error
+		if eventData, err = gateway.UnmarshalEventData(dispatch.Data, dispatch.EventType); err != nil {
+			g.config.Logger.Errorf("error while decoding consumed %s event. error: %s", dispatch.EventType, err)
+			return
+		}
+		if readyEvent, ok := eventData.(gateway.EventReady); ok {
+			g.mu.Lock()
+			g.sessionID = &readyEvent.SessionID
+			g.mu.Unlock()
+		}
+		if _, ok := eventData.(gateway.EventUnknown); ok {
+			g.config.Logger.Debugf("unknown consumed event: %s", dispatch.EventType)
+			return
+		}
+	}
+
+	if g.config.EnableRawEvents {
+		g.eventHandlerFunc(gateway.EventTypeRaw, dispatch.Sequence, dispatch.ShardID, gateway.EventRaw{
+			EventType: dispatch.EventType,
+			Payload:   bytes.NewReader(dispatch.Data),
+		})
+	}
+	if decode {
+		g.eventHandlerFunc(dispatch.EventType, dispatch.Sequence, dispatch.ShardID, eventData)
+	}
+}
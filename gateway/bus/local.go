@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var (
+	_ Publisher = (*Local)(nil)
+	_ Consumer  = (*Local)(nil)
+)
+
+// NewLocal returns a new in-memory bus, which is both the Publisher and the Consumer.
+// Dispatches and commands are passed to the handlers synchronously.
+// It is useful for tests and to run the gateway and the worker side in a single process.
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// Local is an in-memory bus. See NewLocal.
+type Local struct {
+	dispatchHandlers dispatchHandlers
+
+	mu             sync.RWMutex
+	commandHandler CommandHandlerFunc
+}
+
+func (l *Local) Publish(dispatch gateway.Dispatch) error {
+	l.dispatchHandlers.dispatch(dispatch)
+	return nil
+}
+
+func (l *Local) HandleCommands(handler CommandHandlerFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.commandHandler = handler
+}
+
+func (l *Local) Consume(shardID int, handler DispatchHandlerFunc) {
+	l.dispatchHandlers.set(shardID, handler)
+}
+
+func (l *Local) SendCommand(_ context.Context, command gateway.Message) error {
+	l.mu.RLock()
+	handler := l.commandHandler
+	l.mu.RUnlock()
+	if handler != nil {
+		handler(command)
+	}
+	return nil
+}
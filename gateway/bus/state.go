@@ -0,0 +1,66 @@
+package bus
+
+import (
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// replayState keeps the last READY dispatch of every shard and the last GUILD_CREATE dispatch of every guild,
+// so they can be replayed to consumers which connect after the shards were ready.
+// Dispatches which update a guild after its GUILD_CREATE are not merged into the replayed state.
+type replayState struct {
+	ready  map[int][]byte
+	guilds map[int]map[snowflake.ID][]byte
+}
+
+func newReplayState() *replayState {
+	return &replayState{
+		ready:  map[int][]byte{},
+		guilds: map[int]map[snowflake.ID][]byte{},
+	}
+}
+
+// update tracks the given gateway.Dispatch, data is its encoded frame.
+func (s *replayState) update(dispatch gateway.Dispatch, data []byte) error {
+	switch dispatch.EventType {
+	case gateway.EventTypeReady:
+		// a new session starts with a new set of guilds
+		s.ready[dispatch.ShardID] = data
+		s.guilds[dispatch.ShardID] = map[snowflake.ID][]byte{}
+
+	case gateway.EventTypeGuildCreate, gateway.EventTypeGuildDelete:
+		var guild struct {
+			ID snowflake.ID `json:"id"`
+		}
+		if err := json.Unmarshal(dispatch.Data, &guild); err != nil {
+			return err
+		}
+		guilds, ok := s.guilds[dispatch.ShardID]
+		if !ok {
+			guilds = map[snowflake.ID][]byte{}
+			s.guilds[dispatch.ShardID] = guilds
+		}
+		if dispatch.EventType == gateway.EventTypeGuildCreate {
+			guilds[guild.ID] = data
+		} else {
+			delete(guilds, guild.ID)
+		}
+	}
+	return nil
+}
+
+// frames returns the encoded frames to replay, the READY of each shard first.
+func (s *replayState) frames() [][]byte {
+	var frames [][]byte
+	for shardID, guilds := range s.guilds {
+		if ready, ok := s.ready[shardID]; ok {
+			frames = append(frames, ready)
+		}
+		for _, guild := range guilds {
+			frames = append(frames, guild)
+		}
+	}
+	return frames
+}
@@ -0,0 +1,330 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var (
+	_ Publisher = (*TCPPublisher)(nil)
+	_ Consumer  = (*TCPConsumer)(nil)
+)
+
+// frame is a single newline-delimited JSON message sent over a TCP connection.
+type frame struct {
+	Dispatch *gateway.Dispatch `json:"dispatch,omitempty"`
+	Command  *gateway.Message  `json:"command,omitempty"`
+}
+
+// tcpConn is a TCP connection which writes frames.
+type tcpConn struct {
+	conn         net.Conn
+	writeTimeout time.Duration
+
+	mu sync.Mutex
+}
+
+func (c *tcpConn) write(ctx context.Context, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deadline := time.Now().Add(c.writeTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(data)
+	return err
+}
+
+// read calls handleFunc for every frame received until the connection is closed.
+func (c *tcpConn) read(handleFunc func(f frame) error) error {
+	reader := bufio.NewReader(c.conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var f frame
+			if jErr := json.Unmarshal(line, &f); jErr != nil {
+				return jErr
+			}
+			if hErr := handleFunc(f); hErr != nil {
+				return hErr
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func marshalFrame(f frame) ([]byte, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// publisherConn is a connection of a TCPPublisher to a TCPConsumer with its queue of frames.
+type publisherConn struct {
+	*tcpConn
+	queue chan []byte
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *publisherConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		_ = c.conn.Close()
+	})
+}
+
+// Listen starts a TCPPublisher listening on the given address. TCPConsumer(s) connect to it with Dial.
+func Listen(addr string, opts ...ConfigOpt) (*TCPPublisher, error) {
+	config := DefaultConfig()
+	config.Apply(opts)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &TCPPublisher{
+		config:   *config,
+		listener: listener,
+		conns:    map[*publisherConn]struct{}{},
+	}
+	if config.ReplayState {
+		p.state = newReplayState()
+	}
+	go p.accept()
+	return p, nil
+}
+
+// TCPPublisher is a Publisher which publishes all dispatches to every connected TCPConsumer as newline-delimited JSON.
+// Every consumer has its own queue, so slow consumers do not block the Gateway. See Config.SlowConsumerPolicy.
+// Consumers which connect later receive the last READY & GUILD_CREATE dispatches first if Config.ReplayState is enabled.
+// It is a simple stand-in for a real message broker.
+type TCPPublisher struct {
+	config   Config
+	listener net.Listener
+
+	mu             sync.Mutex
+	conns          map[*publisherConn]struct{}
+	state          *replayState
+	commandHandler CommandHandlerFunc
+}
+
+// Addr returns the address the TCPPublisher listens on.
+func (p *TCPPublisher) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+func (p *TCPPublisher) accept() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				p.config.Logger.Error("failed to accept bus consumer: ", err)
+			}
+			return
+		}
+		c := &publisherConn{
+			tcpConn: &tcpConn{conn: conn, writeTimeout: p.config.WriteTimeout},
+			queue:   make(chan []byte, p.config.QueueSize),
+			closed:  make(chan struct{}),
+		}
+
+		// take the replayed state & register the consumer at once, so it does not miss or duplicate dispatches
+		var replay [][]byte
+		p.mu.Lock()
+		if p.state != nil {
+			replay = p.state.frames()
+		}
+		p.conns[c] = struct{}{}
+		p.mu.Unlock()
+		go p.handleConn(c)
+		go p.writeConn(c, replay)
+	}
+}
+
+func (p *TCPPublisher) handleConn(c *publisherConn) {
+	defer p.removeConn(c)
+	if err := c.read(func(f frame) error {
+		if f.Command == nil {
+			return nil
+		}
+		p.mu.Lock()
+		handler := p.commandHandler
+		p.mu.Unlock()
+		if handler != nil {
+			handler(*f.Command)
+		}
+		return nil
+	}); err != nil {
+		p.config.Logger.Error("failed to read from bus consumer: ", err)
+	}
+}
+
+// writeConn writes the replayed frames and then the queued frames to the consumer until it is disconnected.
+func (p *TCPPublisher) writeConn(c *publisherConn, replay [][]byte) {
+	for _, data := range replay {
+		if err := c.write(context.Background(), data); err != nil {
+			p.config.Logger.Error("failed to replay state to bus consumer, disconnecting it: ", err)
+			p.removeConn(c)
+			return
+		}
+	}
+	for {
+		select {
+		case <-c.closed:
+			return
+		case data := <-c.queue:
+			if err := c.write(context.Background(), data); err != nil {
+				p.config.Logger.Error("failed to publish dispatch to bus consumer, disconnecting it: ", err)
+				p.removeConn(c)
+				return
+			}
+		}
+	}
+}
+
+func (p *TCPPublisher) removeConn(c *publisherConn) {
+	p.mu.Lock()
+	delete(p.conns, c)
+	p.mu.Unlock()
+	c.close()
+}
+
+// Publish queues the dispatch for every connected consumer without blocking.
+func (p *TCPPublisher) Publish(dispatch gateway.Dispatch) error {
+	data, err := marshalFrame(frame{Dispatch: &dispatch})
+	if err != nil {
+		return err
+	}
+
+	var slowConns []*publisherConn
+	p.mu.Lock()
+	if p.state != nil {
+		if err = p.state.update(dispatch, data); err != nil {
+			p.config.Logger.Errorf("failed to track %s dispatch for bus consumers: %s", dispatch.EventType, err)
+		}
+	}
+	for c := range p.conns {
+		select {
+		case c.queue <- data:
+		default:
+			slowConns = append(slowConns, c)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, c := range slowConns {
+		if p.config.SlowConsumerPolicy == SlowConsumerPolicyDrop {
+			p.config.Logger.Warnf("queue of bus consumer is full, dropping %s dispatch", dispatch.EventType)
+			continue
+		}
+		p.config.Logger.Error("queue of bus consumer is full, disconnecting it")
+		p.removeConn(c)
+	}
+	return nil
+}
+
+func (p *TCPPublisher) HandleCommands(handler CommandHandlerFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.commandHandler = handler
+}
+
+// Close stops listening and disconnects all consumers.
+func (p *TCPPublisher) Close() error {
+	err := p.listener.Close()
+	p.mu.Lock()
+	for c := range p.conns {
+		c.close()
+		delete(p.conns, c)
+	}
+	p.mu.Unlock()
+	return err
+}
+
+// Dial connects a TCPConsumer to the TCPPublisher listening on the given address.
+func Dial(ctx context.Context, addr string, opts ...ConfigOpt) (*TCPConsumer, error) {
+	config := DefaultConfig()
+	config.Apply(opts)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &TCPConsumer{
+		config: *config,
+		conn:   &tcpConn{conn: conn, writeTimeout: config.WriteTimeout},
+		closed: make(chan struct{}),
+	}
+	go c.listen()
+	return c, nil
+}
+
+// TCPConsumer is a Consumer connected to a TCPPublisher.
+// It does not reconnect, use Closed to get notified when the connection is lost.
+type TCPConsumer struct {
+	config Config
+	conn   *tcpConn
+	closed chan struct{}
+
+	dispatchHandlers dispatchHandlers
+}
+
+func (c *TCPConsumer) listen() {
+	defer close(c.closed)
+	if err := c.conn.read(func(f frame) error {
+		if f.Dispatch == nil {
+			return nil
+		}
+		c.dispatchHandlers.dispatch(*f.Dispatch)
+		return nil
+	}); err != nil {
+		c.config.Logger.Error("failed to read from bus publisher: ", err)
+	}
+	_ = c.conn.conn.Close()
+}
+
+func (c *TCPConsumer) Consume(shardID int, handler DispatchHandlerFunc) {
+	c.dispatchHandlers.set(shardID, handler)
+}
+
+func (c *TCPConsumer) SendCommand(ctx context.Context, command gateway.Message) error {
+	data, err := marshalFrame(frame{Command: &command})
+	if err != nil {
+		return err
+	}
+	return c.conn.write(ctx, data)
+}
+
+// Closed returns a channel which is closed when the connection to the TCPPublisher is closed.
+func (c *TCPConsumer) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// Close closes the connection to the TCPPublisher.
+func (c *TCPConsumer) Close() error {
+	return c.conn.conn.Close()
+}
@@ -0,0 +1,448 @@
+package etf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ToJSON transcodes the given External Term Format payload to JSON.
+func ToJSON(data []byte) ([]byte, error) {
+	return AppendJSON(make([]byte, 0, len(data)*2), data)
+}
+
+// AppendJSON transcodes the given External Term Format payload to JSON and appends it to dst.
+func AppendJSON(dst []byte, data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != Version {
+		return nil, ErrInvalidVersion
+	}
+	d := decoder{data: data, pos: 1}
+	dst, err := d.term(dst)
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+	// snowflakes is true while transcoding the value of a map key which holds snowflakes, so its integers are quoted like in Discord's JSON
+	snowflakes bool
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, ErrUnexpectedEnd
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) readUint8() (int, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return int(b[0]), nil
+}
+
+func (d *decoder) readUint16() (int, error) {
+	b, err := d.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(b)), nil
+}
+
+func (d *decoder) readUint32() (int, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint32(b)), nil
+}
+
+func (d *decoder) term(dst []byte) ([]byte, error) {
+	tag, err := d.readUint8()
+	if err != nil {
+		return nil, err
+	}
+
+	switch byte(tag) {
+	case tagSmallInteger:
+		var i int
+		if i, err = d.readUint8(); err != nil {
+			return nil, err
+		}
+		return d.appendInt(dst, int64(i)), nil
+
+	case tagInteger:
+		var b []byte
+		if b, err = d.read(4); err != nil {
+			return nil, err
+		}
+		return d.appendInt(dst, int64(int32(binary.BigEndian.Uint32(b)))), nil
+
+	case tagSmallBig:
+		var n int
+		if n, err = d.readUint8(); err != nil {
+			return nil, err
+		}
+		return d.big(dst, n)
+
+	case tagLargeBig:
+		var n int
+		if n, err = d.readUint32(); err != nil {
+			return nil, err
+		}
+		return d.big(dst, n)
+
+	case tagNewFloat:
+		var b []byte
+		if b, err = d.read(8); err != nil {
+			return nil, err
+		}
+		return appendFloat(dst, math.Float64frombits(binary.BigEndian.Uint64(b))), nil
+
+	case tagFloat:
+		var b []byte
+		if b, err = d.read(31); err != nil {
+			return nil, err
+		}
+		var f float64
+		if f, err = strconv.ParseFloat(string(bytes.TrimRight(b, "\x00")), 64); err != nil {
+			return nil, err
+		}
+		return appendFloat(dst, f), nil
+
+	case tagAtom, tagAtomUTF8:
+		var n int
+		if n, err = d.readUint16(); err != nil {
+			return nil, err
+		}
+		return d.atom(dst, n)
+
+	case tagSmallAtom, tagSmallAtomUTF8:
+		var n int
+		if n, err = d.readUint8(); err != nil {
+			return nil, err
+		}
+		return d.atom(dst, n)
+
+	case tagBinary:
+		var n int
+		if n, err = d.readUint32(); err != nil {
+			return nil, err
+		}
+		var b []byte
+		if b, err = d.read(n); err != nil {
+			return nil, err
+		}
+		return appendString(dst, b), nil
+
+	case tagNil:
+		return append(dst, '[', ']'), nil
+
+	case tagString:
+		// lists of small integers are encoded as strings
+		var n int
+		if n, err = d.readUint16(); err != nil {
+			return nil, err
+		}
+		var b []byte
+		if b, err = d.read(n); err != nil {
+			return nil, err
+		}
+		dst = append(dst, '[')
+		for i, c := range b {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = d.appendInt(dst, int64(c))
+		}
+		return append(dst, ']'), nil
+
+	case tagList:
+		var n int
+		if n, err = d.readUint32(); err != nil {
+			return nil, err
+		}
+		if dst, err = d.array(dst, n); err != nil {
+			return nil, err
+		}
+		// proper lists end with an empty list as tail
+		var tail int
+		if tail, err = d.readUint8(); err != nil {
+			return nil, err
+		}
+		if byte(tail) != tagNil {
+			return nil, UnsupportedTagError{Tag: byte(tail)}
+		}
+		return dst, nil
+
+	case tagSmallTuple:
+		var n int
+		if n, err = d.readUint8(); err != nil {
+			return nil, err
+		}
+		return d.array(dst, n)
+
+	case tagLargeTuple:
+		var n int
+		if n, err = d.readUint32(); err != nil {
+			return nil, err
+		}
+		return d.array(dst, n)
+
+	case tagMap:
+		var n int
+		if n, err = d.readUint32(); err != nil {
+			return nil, err
+		}
+		snowflakes := d.snowflakes
+		dst = append(dst, '{')
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			keyStart := len(dst)
+			if dst, err = d.key(dst); err != nil {
+				return nil, err
+			}
+			d.snowflakes = isSnowflakeKey(dst[keyStart+1 : len(dst)-1])
+			dst = append(dst, ':')
+			if dst, err = d.term(dst); err != nil {
+				return nil, err
+			}
+		}
+		d.snowflakes = snowflakes
+		return append(dst, '}'), nil
+
+	case tagCompressed:
+		var size int
+		if size, err = d.readUint32(); err != nil {
+			return nil, err
+		}
+		var reader io.ReadCloser
+		if reader, err = zlib.NewReader(bytes.NewReader(d.data[d.pos:])); err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		inner := make([]byte, size)
+		if _, err = io.ReadFull(reader, inner); err != nil {
+			return nil, err
+		}
+		// the compressed term is always the last term of the payload
+		d.pos = len(d.data)
+		return (&decoder{data: inner}).term(dst)
+
+	default:
+		return nil, UnsupportedTagError{Tag: byte(tag)}
+	}
+}
+
+func (d *decoder) array(dst []byte, n int) ([]byte, error) {
+	var err error
+	dst = append(dst, '[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		if dst, err = d.term(dst); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// key transcodes a map key which has to be a JSON string.
+// Discord sends keys as atoms, but binaries & integers are accepted as well.
+func (d *decoder) key(dst []byte) ([]byte, error) {
+	if d.pos >= len(d.data) {
+		return nil, ErrUnexpectedEnd
+	}
+	var (
+		n   int
+		err error
+	)
+	switch d.data[d.pos] {
+	case tagAtom, tagAtomUTF8:
+		d.pos++
+		n, err = d.readUint16()
+	case tagSmallAtom, tagSmallAtomUTF8:
+		d.pos++
+		n, err = d.readUint8()
+	case tagBinary:
+		d.pos++
+		n, err = d.readUint32()
+	case tagSmallInteger, tagInteger, tagSmallBig, tagLargeBig:
+		var num []byte
+		if num, err = d.term(nil); err != nil {
+			return nil, err
+		}
+		// big integers are already quoted
+		if num[0] == '"' {
+			return append(dst, num...), nil
+		}
+		dst = append(dst, '"')
+		dst = append(dst, num...)
+		return append(dst, '"'), nil
+	default:
+		return nil, UnsupportedTagError{Tag: d.data[d.pos]}
+	}
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return appendString(dst, b), nil
+}
+
+func (d *decoder) atom(dst []byte, n int) ([]byte, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	switch string(b) {
+	case "nil":
+		return append(dst, "null"...), nil
+	case "true":
+		return append(dst, "true"...), nil
+	case "false":
+		return append(dst, "false"...), nil
+	}
+	return appendString(dst, b), nil
+}
+
+// big transcodes a little endian big integer with n digits.
+// Integers which don't fit into a JSON number without losing precision are quoted.
+func (d *decoder) big(dst []byte, n int) ([]byte, error) {
+	sign, err := d.readUint8()
+	if err != nil {
+		return nil, err
+	}
+	digits, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if n <= 8 {
+		var u uint64
+		for i := n - 1; i >= 0; i-- {
+			u = u<<8 | uint64(digits[i])
+		}
+		if u <= maxSafeJSONInteger && !d.snowflakes {
+			if sign != 0 {
+				dst = append(dst, '-')
+			}
+			return strconv.AppendUint(dst, u, 10), nil
+		}
+		dst = append(dst, '"')
+		if sign != 0 {
+			dst = append(dst, '-')
+		}
+		dst = strconv.AppendUint(dst, u, 10)
+		return append(dst, '"'), nil
+	}
+
+	bigEndian := make([]byte, n)
+	for i, b := range digits {
+		bigEndian[n-1-i] = b
+	}
+	i := new(big.Int).SetBytes(bigEndian)
+	if sign != 0 {
+		i.Neg(i)
+	}
+	dst = append(dst, '"')
+	dst = i.Append(dst, 10)
+	return append(dst, '"'), nil
+}
+
+// appendInt appends i as JSON number, or as JSON string if it is a snowflake.
+func (d *decoder) appendInt(dst []byte, i int64) []byte {
+	if !d.snowflakes {
+		return strconv.AppendInt(dst, i, 10)
+	}
+	dst = append(dst, '"')
+	dst = strconv.AppendInt(dst, i, 10)
+	return append(dst, '"')
+}
+
+// snowflakeKeys are the keys of snowflakes, and lists of them, which don't follow the id, *_id & *_ids naming of Discord.
+var snowflakeKeys = map[string]struct{}{
+	"roles":           {},
+	"mention_roles":   {},
+	"applied_tags":    {},
+	"exempt_roles":    {},
+	"exempt_channels": {},
+}
+
+// isSnowflakeKey reports whether the value of the given map key is a snowflake, or a list of them.
+// Discord sends snowflakes as integers in the External Term Format, but always as strings in JSON.
+func isSnowflakeKey(key []byte) bool {
+	if string(key) == "id" || bytes.HasSuffix(key, []byte("_id")) || bytes.HasSuffix(key, []byte("_ids")) {
+		return true
+	}
+	_, ok := snowflakeKeys[string(key)]
+	return ok
+}
+
+func appendFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, "null"...)
+	}
+	return strconv.AppendFloat(dst, f, 'g', -1, 64)
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends b as quoted JSON string to dst.
+func appendString(dst []byte, b []byte) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(b); {
+		c := b[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, b[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, b[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, b[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,297 @@
+package etf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidJSON is returned when FromJSON is called with malformed JSON.
+var ErrInvalidJSON = errors.New("etf: invalid json")
+
+// FromJSON transcodes the given JSON payload to the External Term Format.
+// JSON objects are encoded as maps with binary keys, strings as binaries and null as the atom nil.
+func FromJSON(data []byte) ([]byte, error) {
+	e := encoder{data: data}
+	dst, err := e.value(append(make([]byte, 0, len(data)), Version))
+	if err != nil {
+		return nil, err
+	}
+	e.skipWhitespace()
+	if e.pos != len(e.data) {
+		return nil, ErrInvalidJSON
+	}
+	return dst, nil
+}
+
+type encoder struct {
+	data []byte
+	pos  int
+}
+
+func (e *encoder) skipWhitespace() {
+	for e.pos < len(e.data) {
+		switch e.data[e.pos] {
+		case ' ', '\t', '\n', '\r':
+			e.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (e *encoder) consume(literal string) bool {
+	if len(e.data)-e.pos < len(literal) || string(e.data[e.pos:e.pos+len(literal)]) != literal {
+		return false
+	}
+	e.pos += len(literal)
+	return true
+}
+
+func (e *encoder) value(dst []byte) ([]byte, error) {
+	e.skipWhitespace()
+	if e.pos >= len(e.data) {
+		return nil, ErrInvalidJSON
+	}
+
+	var err error
+	switch c := e.data[e.pos]; {
+	case c == '{':
+		e.pos++
+		dst = append(dst, tagMap, 0, 0, 0, 0)
+		countPos := len(dst) - 4
+		count := 0
+		for {
+			e.skipWhitespace()
+			if e.consume("}") {
+				break
+			}
+			if count > 0 && !e.consume(",") {
+				return nil, ErrInvalidJSON
+			}
+			e.skipWhitespace()
+			var key []byte
+			if key, err = e.string(); err != nil {
+				return nil, err
+			}
+			dst = appendBinary(dst, key)
+			e.skipWhitespace()
+			if !e.consume(":") {
+				return nil, ErrInvalidJSON
+			}
+			if dst, err = e.value(dst); err != nil {
+				return nil, err
+			}
+			count++
+		}
+		binary.BigEndian.PutUint32(dst[countPos:], uint32(count))
+		return dst, nil
+
+	case c == '[':
+		e.pos++
+		start := len(dst)
+		dst = append(dst, tagList, 0, 0, 0, 0)
+		count := 0
+		for {
+			e.skipWhitespace()
+			if e.consume("]") {
+				break
+			}
+			if count > 0 && !e.consume(",") {
+				return nil, ErrInvalidJSON
+			}
+			if dst, err = e.value(dst); err != nil {
+				return nil, err
+			}
+			count++
+		}
+		// empty lists are encoded as nil
+		if count == 0 {
+			return append(dst[:start], tagNil), nil
+		}
+		binary.BigEndian.PutUint32(dst[start+1:], uint32(count))
+		return append(dst, tagNil), nil
+
+	case c == '"':
+		var s []byte
+		if s, err = e.string(); err != nil {
+			return nil, err
+		}
+		return appendBinary(dst, s), nil
+
+	case c == '-' || (c >= '0' && c <= '9'):
+		return e.number(dst)
+
+	case e.consume("true"):
+		return appendAtom(dst, "true"), nil
+
+	case e.consume("false"):
+		return appendAtom(dst, "false"), nil
+
+	case e.consume("null"):
+		return appendAtom(dst, "nil"), nil
+
+	default:
+		return nil, fmt.Errorf("%w: unexpected character %q", ErrInvalidJSON, c)
+	}
+}
+
+// string parses a JSON string and returns its unescaped content.
+func (e *encoder) string() ([]byte, error) {
+	if !e.consume(`"`) {
+		return nil, ErrInvalidJSON
+	}
+	start := e.pos
+	// fast path for strings without escape sequences
+	for e.pos < len(e.data) {
+		switch e.data[e.pos] {
+		case '"':
+			e.pos++
+			return e.data[start : e.pos-1], nil
+		case '\\':
+			return e.escapedString(append([]byte(nil), e.data[start:e.pos]...))
+		}
+		e.pos++
+	}
+	return nil, ErrInvalidJSON
+}
+
+func (e *encoder) escapedString(s []byte) ([]byte, error) {
+	for e.pos < len(e.data) {
+		c := e.data[e.pos]
+		e.pos++
+		switch c {
+		case '"':
+			return s, nil
+		case '\\':
+			if e.pos >= len(e.data) {
+				return nil, ErrInvalidJSON
+			}
+			c = e.data[e.pos]
+			e.pos++
+			switch c {
+			case '"', '\\', '/':
+				s = append(s, c)
+			case 'b':
+				s = append(s, '\b')
+			case 'f':
+				s = append(s, '\f')
+			case 'n':
+				s = append(s, '\n')
+			case 'r':
+				s = append(s, '\r')
+			case 't':
+				s = append(s, '\t')
+			case 'u':
+				r, ok := e.hexRune()
+				if !ok {
+					return nil, ErrInvalidJSON
+				}
+				if utf16.IsSurrogate(r) {
+					r2 := utf8.RuneError
+					if e.consume(`\u`) {
+						if r2, ok = e.hexRune(); !ok {
+							return nil, ErrInvalidJSON
+						}
+					}
+					r = utf16.DecodeRune(r, r2)
+				}
+				s = utf8.AppendRune(s, r)
+			default:
+				return nil, ErrInvalidJSON
+			}
+		default:
+			s = append(s, c)
+		}
+	}
+	return nil, ErrInvalidJSON
+}
+
+func (e *encoder) hexRune() (rune, bool) {
+	if len(e.data)-e.pos < 4 {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(string(e.data[e.pos:e.pos+4]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	e.pos += 4
+	return rune(r), true
+}
+
+func (e *encoder) number(dst []byte) ([]byte, error) {
+	start := e.pos
+	isFloat := false
+	for e.pos < len(e.data) {
+		c := e.data[e.pos]
+		if c == '.' || c == 'e' || c == 'E' {
+			isFloat = true
+		} else if (c < '0' || c > '9') && c != '-' && c != '+' {
+			break
+		}
+		e.pos++
+	}
+	number := string(e.data[start:e.pos])
+
+	if !isFloat {
+		if i, err := strconv.ParseInt(number, 10, 64); err == nil {
+			switch {
+			case i >= 0 && i <= math.MaxUint8:
+				return append(dst, tagSmallInteger, byte(i)), nil
+			case i >= math.MinInt32 && i <= math.MaxInt32:
+				dst = append(dst, tagInteger, 0, 0, 0, 0)
+				binary.BigEndian.PutUint32(dst[len(dst)-4:], uint32(int32(i)))
+				return dst, nil
+			}
+		}
+		if i, ok := new(big.Int).SetString(number, 10); ok {
+			return appendBig(dst, i), nil
+		}
+		return nil, fmt.Errorf("%w: invalid number %q", ErrInvalidJSON, number)
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid number %q", ErrInvalidJSON, number)
+	}
+	dst = append(dst, tagNewFloat, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(dst[len(dst)-8:], math.Float64bits(f))
+	return dst, nil
+}
+
+func appendAtom(dst []byte, atom string) []byte {
+	dst = append(dst, tagSmallAtomUTF8, byte(len(atom)))
+	return append(dst, atom...)
+}
+
+func appendBinary(dst []byte, b []byte) []byte {
+	dst = append(dst, tagBinary, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(dst[len(dst)-4:], uint32(len(b)))
+	return append(dst, b...)
+}
+
+func appendBig(dst []byte, i *big.Int) []byte {
+	var sign byte
+	if i.Sign() < 0 {
+		sign = 1
+	}
+	digits := new(big.Int).Abs(i).Bytes()
+
+	if len(digits) <= math.MaxUint8 {
+		dst = append(dst, tagSmallBig, byte(len(digits)))
+	} else {
+		dst = append(dst, tagLargeBig, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(dst[len(dst)-4:], uint32(len(digits)))
+	}
+	dst = append(dst, sign)
+	// digits are stored little endian
+	for j := len(digits) - 1; j >= 0; j-- {
+		dst = append(dst, digits[j])
+	}
+	return dst
+}
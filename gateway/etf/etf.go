@@ -0,0 +1,67 @@
+// Package etf implements the Erlang External Term Format as used by the Discord gateway.
+//
+// Terms are decoded directly into the same structs as regular JSON gateway payloads, see Unmarshal. Types which implement json.Unmarshaler
+// receive the term transcoded to JSON instead. Integers which can't be represented exactly by a JSON number (like snowflakes, which Discord sends
+// as 64-bit integers) are transcoded to JSON strings. The atom nil is transcoded to JSON null, while missing map keys stay missing.
+//
+// See here for more information: https://www.erlang.org/doc/apps/erts/erl_ext_dist.html
+package etf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/disgoorg/json"
+)
+
+// Version is the version byte every External Term Format payload starts with.
+const Version byte = 131
+
+// External Term Format tags used by the Discord gateway.
+const (
+	tagNewFloat        byte = 70
+	tagCompressed      byte = 80
+	tagSmallInteger    byte = 97
+	tagInteger         byte = 98
+	tagFloat           byte = 99
+	tagAtom            byte = 100
+	tagSmallTuple      byte = 104
+	tagLargeTuple      byte = 105
+	tagNil             byte = 106
+	tagString          byte = 107
+	tagList            byte = 108
+	tagBinary          byte = 109
+	tagSmallBig        byte = 110
+	tagLargeBig        byte = 111
+	tagMap             byte = 116
+	tagSmallAtom       byte = 115
+	tagAtomUTF8        byte = 118
+	tagSmallAtomUTF8   byte = 119
+	maxSafeJSONInteger      = 1<<53 - 1
+)
+
+var (
+	// ErrInvalidVersion is returned when a payload does not start with the Version byte.
+	ErrInvalidVersion = errors.New("etf: invalid version byte")
+
+	// ErrUnexpectedEnd is returned when a payload ends before the term is complete.
+	ErrUnexpectedEnd = errors.New("etf: unexpected end of payload")
+)
+
+// UnsupportedTagError is returned when a payload contains a term which can't be transcoded to JSON.
+type UnsupportedTagError struct {
+	Tag byte
+}
+
+func (e UnsupportedTagError) Error() string {
+	return fmt.Sprintf("etf: unsupported tag %d", e.Tag)
+}
+
+// Marshal encodes v to JSON using json.Marshal and transcodes the result to the External Term Format.
+func Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(data)
+}
@@ -0,0 +1,105 @@
+package etf
+
+import (
+	"testing"
+
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToJSON(t *testing.T) {
+	data := []byte{
+		Version,
+		tagMap, 0, 0, 0, 5,
+		// "id" => 1039203432584773632
+		tagSmallAtomUTF8, 2, 'i', 'd',
+		tagSmallBig, 8, 0, 0x00, 0x00, 0x95, 0x18, 0x04, 0xfe, 0x6b, 0x0e,
+		// "name" => <<"disgo">>
+		tagSmallAtom, 4, 'n', 'a', 'm', 'e',
+		tagBinary, 0, 0, 0, 5, 'd', 'i', 's', 'g', 'o',
+		// "avatar" => nil
+		tagAtom, 0, 6, 'a', 'v', 'a', 't', 'a', 'r',
+		tagSmallAtomUTF8, 3, 'n', 'i', 'l',
+		// "shard" => [0, 1]
+		tagSmallAtomUTF8, 5, 's', 'h', 'a', 'r', 'd',
+		tagString, 0, 2, 0, 1,
+		// "flags" => [-1, true]
+		tagSmallAtomUTF8, 5, 'f', 'l', 'a', 'g', 's',
+		tagList, 0, 0, 0, 2,
+		tagInteger, 0xff, 0xff, 0xff, 0xff,
+		tagSmallAtomUTF8, 4, 't', 'r', 'u', 'e',
+		tagNil,
+	}
+
+	jsonData, err := ToJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"1039203432584773632","name":"disgo","avatar":null,"shard":[0,1],"flags":[-1,true]}`, string(jsonData))
+
+	var v struct {
+		ID     snowflake.ID `json:"id"`
+		Name   string       `json:"name"`
+		Avatar *string      `json:"avatar"`
+		Shard  [2]int       `json:"shard"`
+	}
+	assert.NoError(t, Unmarshal(data, &v))
+	assert.Equal(t, snowflake.ID(1039203432584773632), v.ID)
+	assert.Equal(t, "disgo", v.Name)
+	assert.Nil(t, v.Avatar)
+	assert.Equal(t, [2]int{0, 1}, v.Shard)
+}
+
+func TestFromJSON(t *testing.T) {
+	payloads := []string{
+		`{"op":1,"d":null}`,
+		`{"op":2,"d":{"token":"t\"o\\ken \u00e9\ud83d\ude00","intents":3276799,"shard":[0,1],"compress":false,"large_threshold":-50}}`,
+		`{"op":8,"d":{"guild_id":"1039203432584773632","user_ids":[],"since":1.5}}`,
+	}
+	for _, payload := range payloads {
+		data, err := FromJSON([]byte(payload))
+		assert.NoError(t, err)
+
+		var expected, actual any
+		assert.NoError(t, json.Unmarshal([]byte(payload), &expected))
+		assert.NoError(t, Unmarshal(data, &actual))
+		assert.Equal(t, expected, actual)
+	}
+
+	data, err := FromJSON([]byte(`[123456789012345678901234567890,-9007199254740993]`))
+	assert.NoError(t, err)
+	jsonData, err := ToJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, `["123456789012345678901234567890","-9007199254740993"]`, string(jsonData))
+
+	_, err = FromJSON([]byte(`{"op":1`))
+	assert.ErrorIs(t, err, ErrInvalidJSON)
+}
+
+func TestUnmarshal(t *testing.T) {
+	data, err := FromJSON([]byte(`{"id":"1039203432584773632","name":"disgo","extra":[1,{"a":null}],"count":null,"roles":{"123":true},"trigger":"3","embedded":7}`))
+	assert.NoError(t, err)
+
+	type Embedded struct {
+		Embedded int `json:"embedded"`
+	}
+	var v struct {
+		Embedded
+		ID      snowflake.ID          `json:"id"`
+		Name    string                `json:"name"`
+		Count   *int                  `json:"count"`
+		Roles   map[snowflake.ID]bool `json:"roles"`
+		Trigger int                   `json:"trigger,string"`
+	}
+	assert.NoError(t, Unmarshal(data, &v))
+	assert.Equal(t, snowflake.ID(1039203432584773632), v.ID)
+	assert.Equal(t, "disgo", v.Name)
+	assert.Nil(t, v.Count)
+	assert.Equal(t, map[snowflake.ID]bool{123: true}, v.Roles)
+	assert.Equal(t, 3, v.Trigger)
+	assert.Equal(t, 7, v.Embedded.Embedded)
+
+	// snowflakes which fit into a JSON number are quoted as well
+	jsonData, err := ToJSON([]byte{Version, tagMap, 0, 0, 0, 1, tagSmallAtom, 8, 'g', 'u', 'i', 'l', 'd', '_', 'i', 'd', tagSmallInteger, 42})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"guild_id":"42"}`, string(jsonData))
+}
@@ -0,0 +1,771 @@
+package etf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/disgoorg/json"
+)
+
+// RawTerm is a raw encoded External Term Format term including the Version byte.
+// Use it to delay decoding a term, it can be passed to Unmarshal or ToJSON as is.
+type RawTerm []byte
+
+// UnmarshalTypeError is returned when a term can't be decoded into a Go value of the given type.
+type UnmarshalTypeError struct {
+	Tag  byte
+	Type reflect.Type
+}
+
+func (e UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("etf: cannot decode tag %d into Go value of type %s", e.Tag, e.Type)
+}
+
+var (
+	rawTermType         = reflect.TypeOf(RawTerm(nil))
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Unmarshal decodes the External Term Format payload directly into v, which has to be a non-nil pointer.
+//
+// Values are decoded like json.Unmarshal would decode the equivalent JSON: struct fields are matched by their json tags and maps, slices, arrays,
+// strings, numbers, booleans and pointers are decoded natively. Integers are decoded into integer kinds as is, so snowflakes are decoded without
+// a detour over strings regardless of their size, even if the type implements json.Unmarshaler.
+// Other types which implement json.Unmarshaler, as well as interface values, receive the term transcoded to JSON via ToJSON.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("etf: Unmarshal(non-pointer %T)", v)
+	}
+	if len(data) == 0 || data[0] != Version {
+		return ErrInvalidVersion
+	}
+	d := decoder{data: data, pos: 1}
+	if len(data) > 1 && data[1] == tagCompressed {
+		inner, err := d.decompress()
+		if err != nil {
+			return err
+		}
+		d = decoder{data: inner}
+	}
+	return d.value(rv)
+}
+
+// decompress returns the compressed term which the decoder is positioned at.
+func (d *decoder) decompress() ([]byte, error) {
+	d.pos++
+	size, err := d.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := zlib.NewReader(bytes.NewReader(d.data[d.pos:]))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	inner := make([]byte, size)
+	if _, err = io.ReadFull(reader, inner); err != nil {
+		return nil, err
+	}
+	// the compressed term is always the last term of the payload
+	d.pos = len(d.data)
+	return inner, nil
+}
+
+func (d *decoder) peek() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, ErrUnexpectedEnd
+	}
+	return d.data[d.pos], nil
+}
+
+// peekAtom returns the name of the atom the decoder is positioned at without consuming it.
+func (d *decoder) peekAtom() (string, bool) {
+	if d.pos >= len(d.data) {
+		return "", false
+	}
+	var start, n int
+	switch d.data[d.pos] {
+	case tagAtom, tagAtomUTF8:
+		if d.pos+3 > len(d.data) {
+			return "", false
+		}
+		start, n = d.pos+3, int(binary.BigEndian.Uint16(d.data[d.pos+1:]))
+	case tagSmallAtom, tagSmallAtomUTF8:
+		if d.pos+2 > len(d.data) {
+			return "", false
+		}
+		start, n = d.pos+2, int(d.data[d.pos+1])
+	default:
+		return "", false
+	}
+	if start+n > len(d.data) {
+		return "", false
+	}
+	return string(d.data[start : start+n]), true
+}
+
+func (d *decoder) value(v reflect.Value) error {
+	tag, err := d.peek()
+	if err != nil {
+		return err
+	}
+
+	if v.Type() == rawTermType {
+		start := d.pos
+		if err = d.skip(); err != nil {
+			return err
+		}
+		raw := make(RawTerm, 0, d.pos-start+1)
+		raw = append(raw, Version)
+		v.SetBytes(append(raw, d.data[start:d.pos]...))
+		return nil
+	}
+
+	if atom, ok := d.peekAtom(); ok && atom == "nil" {
+		d.skipAtom()
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.value(v.Elem())
+	}
+
+	if isInteger(tag) && isIntegerKind(v.Kind()) {
+		return d.integer(v)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(jsonUnmarshalerType) {
+		var jsonData []byte
+		if jsonData, err = d.term(nil); err != nil {
+			return err
+		}
+		return v.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(jsonData)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		var jsonData []byte
+		if jsonData, err = d.term(nil); err != nil {
+			return err
+		}
+		return json.Unmarshal(jsonData, v.Addr().Interface())
+
+	case reflect.Bool:
+		atom, ok := d.peekAtom()
+		if !ok || (atom != "true" && atom != "false") {
+			return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+		}
+		d.skipAtom()
+		v.SetBool(atom == "true")
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		return d.float(v)
+
+	case reflect.String:
+		var b []byte
+		if b, err = d.text(); err != nil {
+			return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+		}
+		v.SetString(string(b))
+		return nil
+
+	case reflect.Slice:
+		return d.sliceValue(v)
+
+	case reflect.Array:
+		return d.arrayValue(v)
+
+	case reflect.Map:
+		return d.mapValue(v)
+
+	case reflect.Struct:
+		return d.structValue(v)
+	}
+	return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+}
+
+func isInteger(tag byte) bool {
+	return tag == tagSmallInteger || tag == tagInteger || tag == tagSmallBig || tag == tagLargeBig
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func (d *decoder) skipAtom() {
+	switch d.data[d.pos] {
+	case tagAtom, tagAtomUTF8:
+		d.pos += 3 + int(binary.BigEndian.Uint16(d.data[d.pos+1:]))
+	default:
+		d.pos += 2 + int(d.data[d.pos+1])
+	}
+}
+
+// readInteger reads an integer term which fits into 64 bits.
+func (d *decoder) readInteger() (u uint64, negative bool, err error) {
+	tag, err := d.readUint8()
+	if err != nil {
+		return 0, false, err
+	}
+	switch byte(tag) {
+	case tagSmallInteger:
+		var i int
+		i, err = d.readUint8()
+		return uint64(i), false, err
+
+	case tagInteger:
+		var b []byte
+		if b, err = d.read(4); err != nil {
+			return 0, false, err
+		}
+		i := int64(int32(binary.BigEndian.Uint32(b)))
+		if i < 0 {
+			return uint64(-i), true, nil
+		}
+		return uint64(i), false, nil
+
+	case tagSmallBig, tagLargeBig:
+		var n int
+		if byte(tag) == tagSmallBig {
+			n, err = d.readUint8()
+		} else {
+			n, err = d.readUint32()
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		var sign int
+		if sign, err = d.readUint8(); err != nil {
+			return 0, false, err
+		}
+		var digits []byte
+		if digits, err = d.read(n); err != nil {
+			return 0, false, err
+		}
+		for i := n - 1; i >= 0; i-- {
+			if digits[i] == 0 && u == 0 {
+				continue
+			}
+			if i >= 8 {
+				return 0, false, fmt.Errorf("etf: integer with %d bytes overflows 64 bits", n)
+			}
+			u = u<<8 | uint64(digits[i])
+		}
+		return u, sign != 0, nil
+	}
+	return 0, false, UnsupportedTagError{Tag: byte(tag)}
+}
+
+func (d *decoder) integer(v reflect.Value) error {
+	tag := d.data[d.pos]
+	u, negative, err := d.readInteger()
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if u > math.MaxInt64 && !(negative && u == 1<<63) {
+			return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+		}
+		i := int64(u)
+		if negative {
+			i = -i
+		}
+		if v.OverflowInt(i) {
+			return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+		}
+		v.SetInt(i)
+	default:
+		if negative && u != 0 || v.OverflowUint(u) {
+			return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+		}
+		v.SetUint(u)
+	}
+	return nil
+}
+
+func (d *decoder) float(v reflect.Value) error {
+	tag, err := d.peek()
+	if err != nil {
+		return err
+	}
+	var f float64
+	switch tag {
+	case tagNewFloat:
+		d.pos++
+		var b []byte
+		if b, err = d.read(8); err != nil {
+			return err
+		}
+		f = math.Float64frombits(binary.BigEndian.Uint64(b))
+	case tagFloat:
+		d.pos++
+		var b []byte
+		if b, err = d.read(31); err != nil {
+			return err
+		}
+		if f, err = strconv.ParseFloat(string(bytes.TrimRight(b, "\x00")), 64); err != nil {
+			return err
+		}
+	case tagSmallInteger, tagInteger, tagSmallBig, tagLargeBig:
+		var (
+			u        uint64
+			negative bool
+		)
+		if u, negative, err = d.readInteger(); err != nil {
+			return err
+		}
+		f = float64(u)
+		if negative {
+			f = -f
+		}
+	default:
+		return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+	}
+	v.SetFloat(f)
+	return nil
+}
+
+// text reads a binary or atom term as bytes.
+func (d *decoder) text() ([]byte, error) {
+	tag, err := d.readUint8()
+	if err != nil {
+		return nil, err
+	}
+	var n int
+	switch byte(tag) {
+	case tagBinary:
+		n, err = d.readUint32()
+	case tagAtom, tagAtomUTF8:
+		n, err = d.readUint16()
+	case tagSmallAtom, tagSmallAtomUTF8:
+		n, err = d.readUint8()
+	default:
+		d.pos--
+		return nil, UnsupportedTagError{Tag: byte(tag)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d.read(n)
+}
+
+// listLength reads the header of a list-like term and returns the number of elements.
+// Lists of small integers which are encoded as strings are returned as bytes instead.
+func (d *decoder) listLength() (n int, tail bool, bytes []byte, err error) {
+	tag, err := d.readUint8()
+	if err != nil {
+		return 0, false, nil, err
+	}
+	switch byte(tag) {
+	case tagNil:
+		return 0, false, nil, nil
+	case tagString:
+		if n, err = d.readUint16(); err != nil {
+			return 0, false, nil, err
+		}
+		bytes, err = d.read(n)
+		return n, false, bytes, err
+	case tagList:
+		n, err = d.readUint32()
+		return n, true, nil, err
+	case tagSmallTuple:
+		n, err = d.readUint8()
+		return n, false, nil, err
+	case tagLargeTuple:
+		n, err = d.readUint32()
+		return n, false, nil, err
+	}
+	d.pos--
+	return 0, false, nil, UnsupportedTagError{Tag: byte(tag)}
+}
+
+// listTail consumes the tail of a proper list.
+func (d *decoder) listTail() error {
+	tail, err := d.readUint8()
+	if err != nil {
+		return err
+	}
+	if byte(tail) != tagNil {
+		return UnsupportedTagError{Tag: byte(tail)}
+	}
+	return nil
+}
+
+// listElement decodes the i-th element of a list-like term into v.
+func (d *decoder) listElement(v reflect.Value, i int, bytes []byte) error {
+	if bytes != nil {
+		return d.integerFromByte(v, bytes[i])
+	}
+	return d.value(v)
+}
+
+func (d *decoder) integerFromByte(v reflect.Value, b byte) error {
+	// wrap the byte as small integer term, so it is decoded like every other integer
+	return (&decoder{data: []byte{tagSmallInteger, b}}).value(v)
+}
+
+func (d *decoder) sliceValue(v reflect.Value) error {
+	tag, _ := d.peek()
+	n, tail, bytes, err := d.listLength()
+	if err != nil {
+		return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+	}
+	s := reflect.MakeSlice(v.Type(), n, n)
+	for i := 0; i < n; i++ {
+		if err = d.listElement(s.Index(i), i, bytes); err != nil {
+			return err
+		}
+	}
+	if tail {
+		if err = d.listTail(); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+func (d *decoder) arrayValue(v reflect.Value) error {
+	tag, _ := d.peek()
+	n, tail, bytes, err := d.listLength()
+	if err != nil {
+		return UnmarshalTypeError{Tag: tag, Type: v.Type()}
+	}
+	for i := 0; i < n; i++ {
+		if i >= v.Len() {
+			if bytes == nil {
+				if err = d.skip(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err = d.listElement(v.Index(i), i, bytes); err != nil {
+			return err
+		}
+	}
+	for i := n; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+	}
+	if tail {
+		return d.listTail()
+	}
+	return nil
+}
+
+func (d *decoder) mapLength(t reflect.Type) (int, error) {
+	tag, err := d.readUint8()
+	if err != nil {
+		return 0, err
+	}
+	if byte(tag) != tagMap {
+		return 0, UnmarshalTypeError{Tag: byte(tag), Type: t}
+	}
+	return d.readUint32()
+}
+
+// mapKey decodes a map key the same way encoding/json does: into strings, encoding.TextUnmarshaler(s) or integers.
+func (d *decoder) mapKey(keyType reflect.Type) (reflect.Value, error) {
+	tag, err := d.peek()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	key := reflect.New(keyType).Elem()
+	if isInteger(tag) && isIntegerKind(keyType.Kind()) {
+		return key, d.integer(key)
+	}
+
+	var text []byte
+	if isInteger(tag) {
+		// integer keys of other key types are decoded from their string representation
+		if text, err = d.term(nil); err != nil {
+			return reflect.Value{}, err
+		}
+		text = bytes.Trim(text, `"`)
+	} else if text, err = d.text(); err != nil {
+		return reflect.Value{}, err
+	}
+
+	switch {
+	case reflect.PointerTo(keyType).Implements(textUnmarshalerType):
+		err = key.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(text)
+	case keyType.Kind() == reflect.String:
+		key.SetString(string(text))
+	case isIntegerKind(keyType.Kind()):
+		err = d.integerFromText(key, text)
+	default:
+		err = UnmarshalTypeError{Tag: tag, Type: keyType}
+	}
+	return key, err
+}
+
+func (d *decoder) integerFromText(v reflect.Value, text []byte) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(text), 10, 64)
+		if err != nil || v.OverflowInt(i) {
+			return fmt.Errorf("etf: invalid integer key %q for %s", text, v.Type())
+		}
+		v.SetInt(i)
+	default:
+		u, err := strconv.ParseUint(string(text), 10, 64)
+		if err != nil || v.OverflowUint(u) {
+			return fmt.Errorf("etf: invalid integer key %q for %s", text, v.Type())
+		}
+		v.SetUint(u)
+	}
+	return nil
+}
+
+func (d *decoder) mapValue(v reflect.Value) error {
+	n, err := d.mapLength(v.Type())
+	if err != nil {
+		return err
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+	}
+	elemType := v.Type().Elem()
+	for i := 0; i < n; i++ {
+		var key reflect.Value
+		if key, err = d.mapKey(v.Type().Key()); err != nil {
+			return err
+		}
+		elem := reflect.New(elemType).Elem()
+		if err = d.value(elem); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+func (d *decoder) structValue(v reflect.Value) error {
+	n, err := d.mapLength(v.Type())
+	if err != nil {
+		return err
+	}
+	fields := cachedFields(v.Type())
+	for i := 0; i < n; i++ {
+		var name []byte
+		if name, err = d.text(); err != nil {
+			return err
+		}
+		f, ok := fields.byName[string(name)]
+		if !ok {
+			f, ok = fields.byFoldedName[strings.ToLower(string(name))]
+		}
+		if !ok {
+			if err = d.skip(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var fv reflect.Value
+		if fv, ok = fieldByIndex(v, f.index); !ok {
+			// the field is in an unexported embedded struct pointer
+			if err = d.skip(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag, _ := d.peek(); f.quoted && tag == tagBinary {
+			// fields with the json ",string" option contain their value encoded as JSON string
+			var text []byte
+			if text, err = d.text(); err != nil {
+				return err
+			}
+			if err = json.Unmarshal(text, fv.Addr().Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = d.value(fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the field of v with the given index and allocates nil embedded struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+type field struct {
+	name   string
+	index  []int
+	quoted bool
+}
+
+type structFields struct {
+	byName       map[string]field
+	byFoldedName map[string]field
+}
+
+var fieldCache sync.Map // map[reflect.Type]structFields
+
+func cachedFields(t reflect.Type) structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(structFields)
+	}
+	fields := structFields{
+		byName:       map[string]field{},
+		byFoldedName: map[string]field{},
+	}
+	depths := map[string]int{}
+	collectFields(t, nil, 0, fields, depths)
+	f, _ := fieldCache.LoadOrStore(t, fields)
+	return f.(structFields)
+}
+
+// collectFields collects the fields of t like encoding/json does. Fields of embedded structs are promoted unless a shallower field has the same name.
+func collectFields(t reflect.Type, index []int, depth int, fields structFields, depths map[string]int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			collectFields(ft, fieldIndex, depth+1, fields, depths)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if d, ok := depths[name]; ok && d <= depth {
+			continue
+		}
+		depths[name] = depth
+		f := field{
+			name:   name,
+			index:  fieldIndex,
+			quoted: strings.Contains(","+opts+",", ",string,"),
+		}
+		fields.byName[name] = f
+		fields.byFoldedName[strings.ToLower(name)] = f
+	}
+}
+
+// skip skips the term the decoder is positioned at.
+func (d *decoder) skip() error {
+	tag, err := d.readUint8()
+	if err != nil {
+		return err
+	}
+
+	var n int
+	switch byte(tag) {
+	case tagSmallInteger:
+		_, err = d.read(1)
+	case tagInteger:
+		_, err = d.read(4)
+	case tagNewFloat:
+		_, err = d.read(8)
+	case tagFloat:
+		_, err = d.read(31)
+	case tagSmallBig:
+		if n, err = d.readUint8(); err == nil {
+			_, err = d.read(n + 1)
+		}
+	case tagLargeBig:
+		if n, err = d.readUint32(); err == nil {
+			_, err = d.read(n + 1)
+		}
+	case tagAtom, tagAtomUTF8, tagString:
+		if n, err = d.readUint16(); err == nil {
+			_, err = d.read(n)
+		}
+	case tagSmallAtom, tagSmallAtomUTF8:
+		if n, err = d.readUint8(); err == nil {
+			_, err = d.read(n)
+		}
+	case tagBinary:
+		if n, err = d.readUint32(); err == nil {
+			_, err = d.read(n)
+		}
+	case tagNil:
+	case tagList:
+		if n, err = d.readUint32(); err == nil {
+			// the tail is one more term
+			err = d.skipN(n + 1)
+		}
+	case tagSmallTuple:
+		if n, err = d.readUint8(); err == nil {
+			err = d.skipN(n)
+		}
+	case tagLargeTuple:
+		if n, err = d.readUint32(); err == nil {
+			err = d.skipN(n)
+		}
+	case tagMap:
+		if n, err = d.readUint32(); err == nil {
+			err = d.skipN(2 * n)
+		}
+	default:
+		return UnsupportedTagError{Tag: byte(tag)}
+	}
+	return err
+}
+
+func (d *decoder) skipN(n int) error {
+	for i := 0; i < n; i++ {
+		if err := d.skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,12 +2,26 @@ package gateway
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
 // Version defines which discord API version disgo should use to connect to discord.
 const Version = 10
 
+// Encoding is the payload encoding used by the Gateway.
+// See here for more information: https://discord.com/developers/docs/topics/gateway#encoding-and-compression
+type Encoding string
+
+const (
+	// EncodingJSON encodes payloads as JSON.
+	EncodingJSON Encoding = "json"
+
+	// EncodingETF encodes payloads in the Erlang External Term Format.
+	// Payloads are transcoded to JSON internally, see the etf package for details.
+	EncodingETF Encoding = "etf"
+)
+
 // Status is the state that the client is currently in.
 type Status int
 
@@ -49,6 +63,110 @@ const (
 	StatusDisconnected
 )
 
+// String returns the name of the Status.
+func (s Status) String() string {
+	switch s {
+	case StatusUnconnected:
+		return "Unconnected"
+	case StatusConnecting:
+		return "Connecting"
+	case StatusWaitingForHello:
+		return "WaitingForHello"
+	case StatusIdentifying:
+		return "Identifying"
+	case StatusResuming:
+		return "Resuming"
+	case StatusWaitingForReady:
+		return "WaitingForReady"
+	case StatusReady:
+		return "Ready"
+	case StatusDisconnected:
+		return "Disconnected"
+	default:
+		return "Unknown"
+	}
+}
+
+// StatusChangeCause describes why the Status of a Gateway changed.
+type StatusChangeCause int
+
+const (
+	// StatusChangeCauseHandshake is the cause of all status changes while connecting, identifying or resuming.
+	StatusChangeCauseHandshake StatusChangeCause = iota
+
+	// StatusChangeCauseClose is the cause when the Gateway was closed via Gateway.Close or Gateway.CloseWithCode.
+	StatusChangeCauseClose
+
+	// StatusChangeCauseCloseCode is the cause when Discord closed the connection. See StatusChange.CloseCode for the received code.
+	StatusChangeCauseCloseCode
+
+	// StatusChangeCauseConnectionError is the cause when connecting to, reading from or decompressing the connection failed.
+	StatusChangeCauseConnectionError
+
+	// StatusChangeCauseInvalidSession is the cause when Discord sent an OpcodeInvalidSession.
+	StatusChangeCauseInvalidSession
+
+	// StatusChangeCauseReconnectOpcode is the cause when Discord sent an OpcodeReconnect.
+	StatusChangeCauseReconnectOpcode
+
+	// StatusChangeCauseHeartbeatTimeout is the cause when a heartbeat could not be sent or was not acknowledged by Discord.
+	StatusChangeCauseHeartbeatTimeout
+
+	// StatusChangeCauseIdentifyRefused is the cause when the BeforeIdentifyFunc refused or delayed the identify of a new session.
+	StatusChangeCauseIdentifyRefused
+)
+
+// String returns the name of the StatusChangeCause.
+func (c StatusChangeCause) String() string {
+	switch c {
+	case StatusChangeCauseHandshake:
+		return "Handshake"
+	case StatusChangeCauseClose:
+		return "Close"
+	case StatusChangeCauseCloseCode:
+		return "CloseCode"
+	case StatusChangeCauseConnectionError:
+		return "ConnectionError"
+	case StatusChangeCauseInvalidSession:
+		return "InvalidSession"
+	case StatusChangeCauseReconnectOpcode:
+		return "ReconnectOpcode"
+	case StatusChangeCauseHeartbeatTimeout:
+		return "HeartbeatTimeout"
+	case StatusChangeCauseIdentifyRefused:
+		return "IdentifyRefused"
+	default:
+		return "Unknown"
+	}
+}
+
+// IdentifyDelayError can be returned by a BeforeIdentifyFunc to delay the identify of the Gateway.
+// The Gateway closes the connection and reconnects after RetryAfter unless it is closed in the meantime.
+type IdentifyDelayError struct {
+	RetryAfter time.Duration
+	// Err is the reason the identify was delayed, like discord.ErrIdentifyBudgetExhausted.
+	Err error
+}
+
+// Error returns the error formatted as string
+func (e IdentifyDelayError) Error() string {
+	return fmt.Sprintf("identify delayed by %s: %s", e.RetryAfter, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e IdentifyDelayError) Unwrap() error {
+	return e.Err
+}
+
+// StatusChange describes a change of the Status of a Gateway.
+type StatusChange struct {
+	OldStatus Status
+	NewStatus Status
+	Cause     StatusChangeCause
+	// CloseCode is the close code the Gateway was closed with for StatusChangeCauseClose or received from Discord for StatusChangeCauseCloseCode.
+	CloseCode int
+}
+
 type (
 	// EventHandlerFunc is a function that is called when an event is received.
 	EventHandlerFunc func(gatewayEventType EventType, sequenceNumber int, shardID int, event EventData)
@@ -58,6 +176,20 @@ type (
 
 	// CloseHandlerFunc is a function that is called when the Gateway is closed.
 	CloseHandlerFunc func(gateway Gateway, err error)
+
+	// StatusChangeFunc is a function that is called when the Status of the Gateway changes.
+	StatusChangeFunc func(gateway Gateway, change StatusChange)
+
+	// ReconnectAttemptFunc is a function that is called before each reconnect attempt with the delay the Gateway is going to wait.
+	ReconnectAttemptFunc func(gateway Gateway, try int, delay time.Duration)
+
+	// BeforeIdentifyFunc is a function that is called right before the Gateway sends an identify for a new session.
+	// Return an IdentifyDelayError to close the connection and identify again after its delay, or any other error to refuse the identify,
+	// in which case the Gateway closes the connection and calls its CloseHandlerFunc with the error.
+	BeforeIdentifyFunc func(ctx context.Context, gateway Gateway) error
+
+	// EventFilterFunc is a function that returns whether the dispatch of the given EventType should be decoded and passed to the EventHandlerFunc.
+	EventFilterFunc func(eventType EventType) bool
 )
 
 // Gateway is what is used to connect to discord.
@@ -95,12 +227,20 @@ type Gateway interface {
 
 	// Send sends a message to the Discord gateway with the opCode and data.
 	// If context is deadline exceeds, the message sending will be aborted.
+	// Payloads violating Discord's limits are not sent and return a PayloadTooLargeError or InvalidPayloadError.
 	Send(ctx context.Context, op Opcode, data MessageData) error
 
 	// Latency returns the latency of the Gateway.
 	// This is calculated by the time it takes to send a heartbeat and receive a heartbeat ack by discord.
 	Latency() time.Duration
 
+	// MissedHeartbeatAcks returns how many heartbeats in a row were not acknowledged by Discord. It is reset when Discord acknowledges a heartbeat.
+	// Every missed heartbeat ACK means the connection was zombied and caused the Gateway to reconnect, so reconnecting does not reset it.
+	MissedHeartbeatAcks() int
+
+	// QueueDepth returns how many messages are waiting to be sent in the given SendLane.
+	QueueDepth(lane SendLane) int
+
 	// Presence returns the current presence of the Gateway.
 	Presence() *MessageDataPresenceUpdate
 }
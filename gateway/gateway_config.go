@@ -13,10 +13,12 @@ func DefaultConfig() *Config {
 		LargeThreshold:  50,
 		Intents:         IntentsDefault,
 		Compress:        true,
+		Encoding:        EncodingJSON,
 		URL:             "wss://gateway.discord.gg",
 		ShardID:         0,
 		ShardCount:      1,
 		AutoReconnect:   true,
+		ReconnectPolicy: DefaultReconnectPolicy(),
 		EnableResumeURL: true,
 	}
 }
@@ -34,6 +36,11 @@ type Config struct {
 	Intents Intents
 	// Compress is whether the Gateway should compress payloads. Defaults to true.
 	Compress bool
+	// TransportCompression is whether the Gateway should use zlib-stream transport compression for the whole connection.
+	// If enabled, payload compression is not requested. Defaults to false.
+	TransportCompression bool
+	// Encoding is the payload Encoding of the Gateway. Defaults to EncodingJSON.
+	Encoding Encoding
 	// URL is the URL of the Gateway. Defaults to fetch from Discord.
 	URL string
 	// ShardID is the shardID of the Gateway. Defaults to 0.
@@ -46,10 +53,27 @@ type Config struct {
 	ResumeURL *string
 	// LastSequenceReceived is the last sequence received by the Gateway. Defaults to nil (no resume).
 	LastSequenceReceived *int
+	// SessionStore is the SessionStore used to persist the session across process restarts. Defaults to nil (no persistence).
+	SessionStore SessionStore
 	// AutoReconnect is whether the Gateway should automatically reconnect or call the CloseHandlerFunc. Defaults to true.
 	AutoReconnect bool
+	// StatusChangeFunc is called asynchronously, but in order, when the Status of the Gateway changes. Defaults to nil.
+	StatusChangeFunc StatusChangeFunc
+	// ReconnectPolicy is the ReconnectPolicy used to delay reconnect attempts. Defaults to DefaultReconnectPolicy().
+	ReconnectPolicy ReconnectPolicy
+	// ReconnectAttemptFunc is called before each reconnect attempt. Defaults to nil.
+	ReconnectAttemptFunc ReconnectAttemptFunc
+	// BeforeIdentifyFunc is called right before identifying a new session. Defaults to nil.
+	BeforeIdentifyFunc BeforeIdentifyFunc
+	// EventFilter decides which dispatches are decoded and passed to the EventHandlerFunc. All other dispatches are skipped without being decoded.
+	// EventTypeReady is always decoded. Defaults to nil (decode all dispatches).
+	EventFilter EventFilterFunc
 	// EnableRawEvents is whether the Gateway should emit EventRaw. Defaults to false.
 	EnableRawEvents bool
+	// Recorder records all dispatches received by the Gateway. Defaults to nil (no recording).
+	Recorder *Recorder
+	// Sink receives all dispatches received by the Gateway. Defaults to nil.
+	Sink Sink
 	// EnableResumeURL is whether the Gateway should enable the resumeURL. Defaults to true.
 	EnableResumeURL bool
 	// RateLimiter is the RateLimiter of the Gateway. Defaults to NewRateLimiter().
@@ -77,6 +101,9 @@ func (c *Config) Apply(opts []ConfigOpt) {
 	if c.RateLimiter == nil {
 		c.RateLimiter = NewRateLimiter(c.RateRateLimiterConfigOpts...)
 	}
+	if c.ReconnectPolicy == nil {
+		c.ReconnectPolicy = DefaultReconnectPolicy()
+	}
 }
 
 // WithLogger sets the Logger for the Gateway.
@@ -117,6 +144,22 @@ func WithCompress(compress bool) ConfigOpt {
 	}
 }
 
+// WithTransportCompression sets whether this Gateway uses zlib-stream transport compression.
+// See here for more information: https://discord.com/developers/docs/topics/gateway#transport-compression
+func WithTransportCompression(transportCompression bool) ConfigOpt {
+	return func(config *Config) {
+		config.TransportCompression = transportCompression
+	}
+}
+
+// WithEncoding sets the payload Encoding for the Gateway.
+// See here for more information: https://discord.com/developers/docs/topics/gateway#encoding-and-compression
+func WithEncoding(encoding Encoding) ConfigOpt {
+	return func(config *Config) {
+		config.Encoding = encoding
+	}
+}
+
 // WithURL sets the Gateway URL for the Gateway.
 func WithURL(url string) ConfigOpt {
 	return func(config *Config) {
@@ -156,6 +199,14 @@ func WithSequence(sequence int) ConfigOpt {
 	}
 }
 
+// WithSessionStore sets the SessionStore used to persist the session of the Gateway across process restarts.
+// The stored session is loaded in Gateway.Open if no session was set via WithSessionID & WithSequence.
+func WithSessionStore(sessionStore SessionStore) ConfigOpt {
+	return func(config *Config) {
+		config.SessionStore = sessionStore
+	}
+}
+
 // WithAutoReconnect sets whether the Gateway should automatically reconnect to Discord.
 func WithAutoReconnect(autoReconnect bool) ConfigOpt {
 	return func(config *Config) {
@@ -163,6 +214,35 @@ func WithAutoReconnect(autoReconnect bool) ConfigOpt {
 	}
 }
 
+// WithStatusChangeFunc sets the StatusChangeFunc which is called when the Status of the Gateway changes.
+func WithStatusChangeFunc(statusChangeFunc StatusChangeFunc) ConfigOpt {
+	return func(config *Config) {
+		config.StatusChangeFunc = statusChangeFunc
+	}
+}
+
+// WithReconnectPolicy sets the ReconnectPolicy used to delay reconnect attempts.
+func WithReconnectPolicy(reconnectPolicy ReconnectPolicy) ConfigOpt {
+	return func(config *Config) {
+		config.ReconnectPolicy = reconnectPolicy
+	}
+}
+
+// WithReconnectAttemptFunc sets the ReconnectAttemptFunc which is called before each reconnect attempt.
+func WithReconnectAttemptFunc(reconnectAttemptFunc ReconnectAttemptFunc) ConfigOpt {
+	return func(config *Config) {
+		config.ReconnectAttemptFunc = reconnectAttemptFunc
+	}
+}
+
+// WithBeforeIdentifyFunc sets the BeforeIdentifyFunc which is called right before identifying a new session.
+// It can be used to delay or refuse identifies, for example to not exhaust the daily session start limit.
+func WithBeforeIdentifyFunc(beforeIdentifyFunc BeforeIdentifyFunc) ConfigOpt {
+	return func(config *Config) {
+		config.BeforeIdentifyFunc = beforeIdentifyFunc
+	}
+}
+
 // WithEnableRawEvents enables/disables the EventTypeRaw.
 func WithEnableRawEvents(enableRawEventEvents bool) ConfigOpt {
 	return func(config *Config) {
@@ -170,6 +250,28 @@ func WithEnableRawEvents(enableRawEventEvents bool) ConfigOpt {
 	}
 }
 
+// WithEventFilter sets the EventFilterFunc which decides which dispatches are decoded.
+// Skipped dispatches are still emitted as EventRaw if raw events are enabled.
+func WithEventFilter(eventFilter EventFilterFunc) ConfigOpt {
+	return func(config *Config) {
+		config.EventFilter = eventFilter
+	}
+}
+
+// WithRecorder sets the Recorder which records all dispatches received by the Gateway.
+func WithRecorder(recorder *Recorder) ConfigOpt {
+	return func(config *Config) {
+		config.Recorder = recorder
+	}
+}
+
+// WithSink sets the Sink which receives all dispatches received by the Gateway.
+func WithSink(sink Sink) ConfigOpt {
+	return func(config *Config) {
+		config.Sink = sink
+	}
+}
+
 // WithEnableResumeURL enables/disables usage of resume URLs sent by Discord.
 func WithEnableResumeURL(enableResumeURL bool) ConfigOpt {
 	return func(config *Config) {
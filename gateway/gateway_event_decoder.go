@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"sync"
+
+	"github.com/disgoorg/json"
+)
+
+// EventDecoderFunc decodes the data of a dispatch with an EventType unknown to disgo.
+type EventDecoderFunc func(data []byte) (any, error)
+
+var (
+	eventDecodersMu sync.RWMutex
+	eventDecoders   = map[EventType]EventDecoderFunc{}
+)
+
+// RegisterEventDecoder registers an EventDecoderFunc for an EventType unknown to disgo.
+// Dispatches of this EventType are decoded with it and passed to the EventHandlerFunc as EventCustom instead of being dropped as EventUnknown.
+// EventDecoderFunc(s) for EventType(s) known to disgo are never used.
+func RegisterEventDecoder(eventType EventType, decoder EventDecoderFunc) {
+	eventDecodersMu.Lock()
+	defer eventDecodersMu.Unlock()
+	eventDecoders[eventType] = decoder
+}
+
+// RegisterEventType registers an EventDecoderFunc which unmarshals dispatches of the given EventType into T.
+func RegisterEventType[T any](eventType EventType) {
+	RegisterEventDecoder(eventType, func(data []byte) (any, error) {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+// UnregisterEventDecoder removes the EventDecoderFunc for the given EventType.
+func UnregisterEventDecoder(eventType EventType) {
+	eventDecodersMu.Lock()
+	defer eventDecodersMu.Unlock()
+	delete(eventDecoders, eventType)
+}
+
+func eventDecoder(eventType EventType) (EventDecoderFunc, bool) {
+	eventDecodersMu.RLock()
+	defer eventDecodersMu.RUnlock()
+	decoder, ok := eventDecoders[eventType]
+	return decoder, ok
+}
@@ -0,0 +1,25 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterEventType(t *testing.T) {
+	type eventFoo struct {
+		Foo string `json:"foo"`
+	}
+	eventType := EventType("FOO_CREATE")
+
+	eventData, err := UnmarshalEventData([]byte(`{"foo":"bar"}`), eventType)
+	assert.NoError(t, err)
+	assert.IsType(t, EventUnknown{}, eventData)
+
+	RegisterEventType[eventFoo](eventType)
+	defer UnregisterEventDecoder(eventType)
+
+	eventData, err = UnmarshalEventData([]byte(`{"foo":"bar"}`), eventType)
+	assert.NoError(t, err)
+	assert.Equal(t, EventCustom{Data: eventFoo{Foo: "bar"}}, eventData)
+}
@@ -8,6 +8,10 @@ const (
 	// EventTypeRaw is not a real event type, but is used to pass raw payloads to the bot.EventManager
 	EventTypeRaw                                 EventType = "__RAW__"
 	EventTypeHeartbeatAck                        EventType = "__HEARTBEAT_ACK__"
+	EventTypeStatusChange                        EventType = "__STATUS_CHANGE__"
+	EventTypeIdentifyBudgetLow                   EventType = "__IDENTIFY_BUDGET_LOW__"
+	EventTypeShardReady                          EventType = "__SHARD_READY__"
+	EventTypeAllShardsReady                      EventType = "__ALL_SHARDS_READY__"
 	EventTypeReady                               EventType = "READY"
 	EventTypeResumed                             EventType = "RESUMED"
 	EventTypeApplicationCommandPermissionsUpdate EventType = "APPLICATION_COMMAND_PERMISSIONS_UPDATE"
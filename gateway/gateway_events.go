@@ -29,6 +29,14 @@ func (e *EventUnknown) UnmarshalJSON(data []byte) error {
 func (EventUnknown) messageData() {}
 func (EventUnknown) eventData()   {}
 
+// EventCustom is an event that is not known to disgo, but was decoded by an EventDecoderFunc registered with RegisterEventDecoder
+type EventCustom struct {
+	Data any
+}
+
+func (EventCustom) messageData() {}
+func (EventCustom) eventData()   {}
+
 // EventReady is the event sent by discord when you successfully Identify
 type EventReady struct {
 	Version          int                        `json:"v"`
@@ -700,3 +708,37 @@ type EventHeartbeatAck struct {
 
 func (EventHeartbeatAck) messageData() {}
 func (EventHeartbeatAck) eventData()   {}
+
+// EventStatusChange is not a real event, but is used to pass StatusChange(s) to the bot.EventManager
+type EventStatusChange struct {
+	StatusChange
+}
+
+func (EventStatusChange) messageData() {}
+func (EventStatusChange) eventData()   {}
+
+// EventIdentifyBudgetLow is not a real event, but is used by the sharding.ShardManager to warn the bot.EventManager that only few identifies are left
+type EventIdentifyBudgetLow struct {
+	discord.SessionStartLimit
+}
+
+func (EventIdentifyBudgetLow) messageData() {}
+func (EventIdentifyBudgetLow) eventData()   {}
+
+// EventShardReady is not a real event, but is used by the bot.ReadyTracker to pass that a shard is READY and its guilds are loaded to the bot.EventManager
+type EventShardReady struct {
+	// UnavailableGuildIDs are the guilds which did not load within the guild load timeout.
+	UnavailableGuildIDs []snowflake.ID
+}
+
+func (EventShardReady) messageData() {}
+func (EventShardReady) eventData()   {}
+
+// EventAllShardsReady is not a real event, but is used by the bot.ReadyTracker to pass that all shards are READY and their guilds are loaded to the bot.EventManager
+type EventAllShardsReady struct {
+	// UnavailableGuildIDs are the guilds of all shards which did not load within the guild load timeout.
+	UnavailableGuildIDs []snowflake.ID
+}
+
+func (EventAllShardsReady) messageData() {}
+func (EventAllShardsReady) eventData()   {}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"sync"
 	"syscall"
@@ -16,6 +17,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway/etf"
 )
 
 var _ Gateway = (*gatewayImpl)(nil)
@@ -43,11 +45,21 @@ type gatewayImpl struct {
 	conn          *websocket.Conn
 	connMu        sync.Mutex
 	heartbeatChan chan struct{}
-	status        Status
+	// identifyDelayCancel cancels the reconnect after an identify was delayed
+	identifyDelayCancel context.CancelFunc
+	status              Status
+	statusMu            sync.Mutex
+
+	statusChanges   []StatusChange
+	notifyingStatus bool
 
 	heartbeatInterval     time.Duration
 	lastHeartbeatSent     time.Time
 	lastHeartbeatReceived time.Time
+
+	heartbeatMu         sync.Mutex
+	heartbeatAcked      bool
+	missedHeartbeatAcks int
 }
 
 func (g *gatewayImpl) ShardID() int {
@@ -85,28 +97,103 @@ func (g *gatewayImpl) formatLogs(a ...any) string {
 }
 
 func (g *gatewayImpl) Open(ctx context.Context) error {
-	return g.reconnectTry(ctx, 0)
+	g.loadSession()
+	return g.reconnectTry(ctx)
+}
+
+// loadSession loads the resume data from the SessionStore if none was configured.
+func (g *gatewayImpl) loadSession() {
+	if g.config.SessionStore == nil || g.config.SessionID != nil {
+		return
+	}
+	session, err := g.config.SessionStore.Session(g.config.ShardID)
+	if err != nil {
+		g.config.Logger.Error(g.formatLogs("failed to load session from session store. error: ", err))
+		return
+	}
+	if session == nil || session.ShardCount != g.config.ShardCount {
+		return
+	}
+	g.config.Logger.Debug(g.formatLogsf("loaded session %s with sequence %d from session store", session.ID, session.Sequence))
+	g.config.SessionID = &session.ID
+	g.config.ResumeURL = session.ResumeURL
+	g.config.LastSequenceReceived = &session.Sequence
+}
+
+// saveSession saves the current resume data to the SessionStore.
+func (g *gatewayImpl) saveSession() {
+	if g.config.SessionStore == nil || g.config.SessionID == nil || g.config.LastSequenceReceived == nil {
+		return
+	}
+	if err := g.config.SessionStore.SetSession(g.config.ShardID, Session{
+		ID:         *g.config.SessionID,
+		ResumeURL:  g.config.ResumeURL,
+		Sequence:   *g.config.LastSequenceReceived,
+		ShardCount: g.config.ShardCount,
+	}); err != nil {
+		g.config.Logger.Error(g.formatLogs("failed to save session to session store. error: ", err))
+	}
+}
+
+// clearSession clears the resume data and deletes it from the SessionStore.
+func (g *gatewayImpl) clearSession() {
+	g.config.SessionID = nil
+	g.config.ResumeURL = nil
+	g.config.LastSequenceReceived = nil
+	if g.config.SessionStore == nil {
+		return
+	}
+	if err := g.config.SessionStore.DeleteSession(g.config.ShardID); err != nil {
+		g.config.Logger.Error(g.formatLogs("failed to delete session from session store. error: ", err))
+	}
 }
 
 func (g *gatewayImpl) open(ctx context.Context) error {
 	g.config.Logger.Debug(g.formatLogs("opening gateway connection"))
 
+	g.connMu.Lock()
+	connected := g.conn != nil
+	g.connMu.Unlock()
+	if connected {
+		return discord.ErrGatewayAlreadyConnected
+	}
+
+	g.setStatus(StatusConnecting, StatusChangeCauseHandshake, 0)
+
+	conn, err := g.dial(ctx)
+	if err != nil {
+		if err != discord.ErrGatewayAlreadyConnected {
+			g.setStatus(StatusDisconnected, StatusChangeCauseConnectionError, 0)
+		}
+		return err
+	}
+	g.setStatus(StatusWaitingForHello, StatusChangeCauseHandshake, 0)
+
+	go g.listen(conn)
+
+	return nil
+}
+
+func (g *gatewayImpl) dial(ctx context.Context) (*websocket.Conn, error) {
 	g.connMu.Lock()
 	defer g.connMu.Unlock()
 	if g.conn != nil {
-		return discord.ErrGatewayAlreadyConnected
+		return nil, discord.ErrGatewayAlreadyConnected
 	}
-	g.status = StatusConnecting
 
 	wsURL := g.config.URL
 	if g.config.ResumeURL != nil && g.config.EnableResumeURL {
 		wsURL = *g.config.ResumeURL
 	}
-	gatewayURL := fmt.Sprintf("%s?v=%d&encoding=json", wsURL, Version)
+	gatewayURL := fmt.Sprintf("%s?v=%d&encoding=%s", wsURL, Version, g.config.Encoding)
+	if g.config.TransportCompression {
+		gatewayURL += "&compress=zlib-stream"
+	}
+	g.heartbeatMu.Lock()
 	g.lastHeartbeatSent = time.Now().UTC()
+	g.heartbeatMu.Unlock()
 	conn, rs, err := g.config.Dialer.DialContext(ctx, gatewayURL, nil)
 	if err != nil {
-		g.Close(ctx)
 		body := "empty"
 		if rs != nil && rs.Body != nil {
 			defer func() {
@@ -120,7 +207,7 @@ func (g *gatewayImpl) open(ctx context.Context) error {
 		}
 
 		g.config.Logger.Error(g.formatLogsf("error connecting to the gateway. url: %s, error: %s, body: %s", gatewayURL, err, body))
-		return err
+		return nil, err
 	}
 
 	conn.SetCloseHandler(func(code int, text string) error {
@@ -132,11 +219,7 @@ func (g *gatewayImpl) open(ctx context.Context) error {
 	// reset rate limiter when connecting
 	g.config.RateLimiter.Reset()
 
-	g.status = StatusWaitingForHello
-
-	go g.listen(conn)
-
-	return nil
+	return conn, nil
 }
 
 func (g *gatewayImpl) Close(ctx context.Context) {
@@ -144,38 +227,110 @@ func (g *gatewayImpl) Close(ctx context.Context) {
 }
 
 func (g *gatewayImpl) CloseWithCode(ctx context.Context, code int, message string) {
-	if g.heartbeatChan != nil {
-		g.config.Logger.Debug(g.formatLogs("closing heartbeat goroutines..."))
-		g.heartbeatChan <- struct{}{}
+	g.close(ctx, code, message, StatusChangeCauseClose, code)
+}
+
+// close closes the connection with the given code & message and reports the StatusChangeCause with its closeCode.
+func (g *gatewayImpl) close(ctx context.Context, code int, message string, cause StatusChangeCause, closeCode int) {
+	if g.closeConn(ctx, code, message) {
+		g.setStatus(StatusDisconnected, cause, closeCode)
 	}
+}
+
+func (g *gatewayImpl) closeConn(ctx context.Context, code int, message string) bool {
+	// close the RateLimiter first to reject queued messages and wait for the message which is currently sent
+	g.config.RateLimiter.Close(ctx)
 
 	g.connMu.Lock()
 	defer g.connMu.Unlock()
-	if g.conn != nil {
-		g.config.RateLimiter.Close(ctx)
-		g.config.Logger.Debug(g.formatLogsf("closing gateway connection with code: %d, message: %s", code, message))
-		if err := g.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, message)); err != nil && err != websocket.ErrCloseSent {
-			g.config.Logger.Debug(g.formatLogs("error writing close code. error: ", err))
-		}
-		_ = g.conn.Close()
-		g.conn = nil
-
-		// clear resume data as we closed gracefully
-		if code == websocket.CloseNormalClosure || code == websocket.CloseGoingAway {
-			g.config.SessionID = nil
-			g.config.ResumeURL = nil
-			g.config.LastSequenceReceived = nil
+	if g.identifyDelayCancel != nil {
+		g.identifyDelayCancel()
+		g.identifyDelayCancel = nil
+	}
+	if g.heartbeatChan != nil {
+		g.config.Logger.Debug(g.formatLogs("closing heartbeat goroutines..."))
+		close(g.heartbeatChan)
+		g.heartbeatChan = nil
+	}
+
+	if g.conn == nil {
+		return false
+	}
+	g.config.Logger.Debug(g.formatLogsf("closing gateway connection with code: %d, message: %s", code, message))
+	if err := g.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, message)); err != nil && err != websocket.ErrCloseSent {
+		g.config.Logger.Debug(g.formatLogs("error writing close code. error: ", err))
+	}
+	_ = g.conn.Close()
+	g.conn = nil
+
+	// clear resume data as we closed gracefully
+	if code == websocket.CloseNormalClosure || code == websocket.CloseGoingAway {
+		g.clearSession()
+	} else if g.config.SessionStore != nil {
+		if err := g.config.SessionStore.Flush(); err != nil {
+			g.config.Logger.Error(g.formatLogs("failed to flush session store. error: ", err))
 		}
 	}
+	return true
 }
 
 func (g *gatewayImpl) Status() Status {
-	g.connMu.Lock()
-	defer g.connMu.Unlock()
+	g.statusMu.Lock()
+	defer g.statusMu.Unlock()
 	return g.status
 }
 
+// setStatus updates the Status and queues a StatusChange if it changed.
+// StatusChange(s) are passed to the StatusChangeFunc & EventHandlerFunc in order from a separate goroutine,
+// so they can be emitted while holding locks and handlers are free to use the Gateway.
+func (g *gatewayImpl) setStatus(status Status, cause StatusChangeCause, closeCode int) {
+	g.statusMu.Lock()
+	oldStatus := g.status
+	if oldStatus == status {
+		g.statusMu.Unlock()
+		return
+	}
+	g.status = status
+	g.statusChanges = append(g.statusChanges, StatusChange{
+		OldStatus: oldStatus,
+		NewStatus: status,
+		Cause:     cause,
+		CloseCode: closeCode,
+	})
+	notifying := g.notifyingStatus
+	g.notifyingStatus = true
+	g.statusMu.Unlock()
+
+	g.config.Logger.Debug(g.formatLogsf("status changed from %s to %s, cause: %s", oldStatus, status, cause))
+	if !notifying {
+		go g.notifyStatusChanges()
+	}
+}
+
+func (g *gatewayImpl) notifyStatusChanges() {
+	for {
+		g.statusMu.Lock()
+		if len(g.statusChanges) == 0 {
+			g.notifyingStatus = false
+			g.statusMu.Unlock()
+			return
+		}
+		change := g.statusChanges[0]
+		g.statusChanges = g.statusChanges[1:]
+		g.statusMu.Unlock()
+
+		if g.config.StatusChangeFunc != nil {
+			g.config.StatusChangeFunc(g, change)
+		}
+		g.eventHandlerFunc(EventTypeStatusChange, 0, g.config.ShardID, EventStatusChange{StatusChange: change})
+	}
+}
+
 func (g *gatewayImpl) Send(ctx context.Context, op Opcode, d MessageData) error {
+	// invalid payloads would make Discord close the connection, so reject them before sending
+	if err := ValidateMessageData(op, d, g.config.Intents); err != nil {
+		return err
+	}
 	data, err := json.Marshal(Message{
 		Op: op,
 		D:  d,
@@ -183,104 +338,224 @@ func (g *gatewayImpl) Send(ctx context.Context, op Opcode, d MessageData) error
 	if err != nil {
 		return err
 	}
-	return g.send(ctx, websocket.TextMessage, data)
+	messageType := websocket.TextMessage
+	if g.config.Encoding == EncodingETF {
+		if data, err = etf.FromJSON(data); err != nil {
+			return err
+		}
+		messageType = websocket.BinaryMessage
+	}
+	if len(data) > MaxPayloadSize {
+		return PayloadTooLargeError{Op: op, Size: len(data)}
+	}
+	return g.send(ctx, op, messageType, data)
 }
 
-func (g *gatewayImpl) send(ctx context.Context, messageType int, data []byte) error {
+func (g *gatewayImpl) send(ctx context.Context, op Opcode, messageType int, data []byte) error {
+	// wait for our turn before locking the connection, so other messages can still be queued by priority
+	if err := g.config.RateLimiter.Wait(ctx, op); err != nil {
+		return err
+	}
+	defer g.config.RateLimiter.Unlock()
+
 	g.connMu.Lock()
 	defer g.connMu.Unlock()
 	if g.conn == nil {
 		return discord.ErrShardNotConnected
 	}
 
-	if err := g.config.RateLimiter.Wait(ctx); err != nil {
-		return err
-	}
-
-	defer g.config.RateLimiter.Unlock()
 	g.config.Logger.Trace(g.formatLogs("sending gateway command: ", string(data)))
 	return g.conn.WriteMessage(messageType, data)
 }
 
 func (g *gatewayImpl) Latency() time.Duration {
+	g.heartbeatMu.Lock()
+	defer g.heartbeatMu.Unlock()
 	return g.lastHeartbeatReceived.Sub(g.lastHeartbeatSent)
 }
 
+func (g *gatewayImpl) QueueDepth(lane SendLane) int {
+	return g.config.RateLimiter.QueueDepth(lane)
+}
+
+func (g *gatewayImpl) MissedHeartbeatAcks() int {
+	g.heartbeatMu.Lock()
+	defer g.heartbeatMu.Unlock()
+	return g.missedHeartbeatAcks
+}
+
 func (g *gatewayImpl) Presence() *MessageDataPresenceUpdate {
 	return g.config.Presence
 }
 
-func (g *gatewayImpl) reconnectTry(ctx context.Context, try int) error {
-	delay := time.Duration(try) * 2 * time.Second
-	if delay > 30*time.Second {
-		delay = 30 * time.Second
-	}
+func (g *gatewayImpl) reconnectTry(ctx context.Context) error {
+	var lastErr error
+	for try := 0; ; try++ {
+		delay, ok := g.config.ReconnectPolicy.Delay(try)
+		if !ok {
+			return fmt.Errorf("%w after %d attempts, last error: %s", discord.ErrGatewayReconnectFailed, try, lastErr)
+		}
+		if g.config.ReconnectAttemptFunc != nil {
+			g.config.ReconnectAttemptFunc(g, try, delay)
+		}
 
-	timer := time.NewTimer(delay)
-	defer timer.Stop()
-	select {
-	case <-ctx.Done():
-		timer.Stop()
-		return ctx.Err()
-	case <-timer.C:
-	}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
 
-	if err := g.open(ctx); err != nil {
-		if err == discord.ErrGatewayAlreadyConnected {
-			return err
+		if err := g.open(ctx); err != nil {
+			if err == discord.ErrGatewayAlreadyConnected {
+				return err
+			}
+			g.config.Logger.Error(g.formatLogs("failed to reconnect gateway. error: ", err))
+			lastErr = err
+			continue
 		}
-		g.config.Logger.Error(g.formatLogs("failed to reconnect gateway. error: ", err))
-		g.status = StatusDisconnected
-		return g.reconnectTry(ctx, try+1)
+		return nil
 	}
-	return nil
 }
 
 func (g *gatewayImpl) reconnect() {
-	err := g.reconnectTry(context.Background(), 0)
+	err := g.reconnectTry(context.Background())
 	if err != nil {
 		g.config.Logger.Error(g.formatLogs("failed to reopen gateway. error: ", err))
+		if err != discord.ErrGatewayAlreadyConnected && g.closeHandlerFunc != nil {
+			g.closeHandlerFunc(g, err)
+		}
 	}
 }
 
-func (g *gatewayImpl) heartbeat() {
-	if g.heartbeatChan == nil {
-		g.heartbeatChan = make(chan struct{})
+// identifyRefused closes the connection after the BeforeIdentifyFunc refused or delayed the identify.
+// Delayed identifies are retried after the delay unless the Gateway is closed in the meantime, refused ones are passed to the CloseHandlerFunc.
+func (g *gatewayImpl) identifyRefused(err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	g.close(ctx, websocket.CloseNormalClosure, "identify refused", StatusChangeCauseIdentifyRefused, 0)
+	cancel()
+
+	var delayErr IdentifyDelayError
+	if !errors.As(err, &delayErr) {
+		g.config.Logger.Warn(g.formatLogs("identify refused. error: ", err))
+		if g.closeHandlerFunc != nil {
+			go g.closeHandlerFunc(g, err)
+		}
+		return
 	}
-	heartbeatTicker := time.NewTicker(g.heartbeatInterval)
-	defer heartbeatTicker.Stop()
+
+	g.config.Logger.Warn(g.formatLogsf("identify delayed by %s. error: %s", delayErr.RetryAfter, delayErr.Err))
+	delayCtx, delayCancel := context.WithCancel(context.Background())
+	g.connMu.Lock()
+	g.identifyDelayCancel = delayCancel
+	g.connMu.Unlock()
+	go func() {
+		defer delayCancel()
+		timer := time.NewTimer(delayErr.RetryAfter)
+		select {
+		case <-delayCtx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := g.reconnectTry(delayCtx); err != nil && delayCtx.Err() == nil {
+			g.config.Logger.Error(g.formatLogs("failed to reopen gateway. error: ", err))
+			if err != discord.ErrGatewayAlreadyConnected && g.closeHandlerFunc != nil {
+				g.closeHandlerFunc(g, err)
+			}
+		}
+	}()
+}
+
+func (g *gatewayImpl) heartbeat(heartbeatChan <-chan struct{}, heartbeatInterval time.Duration) {
 	defer g.config.Logger.Debug(g.formatLogs("exiting heartbeat goroutine..."))
 
+	// the first heartbeat should be sent after heartbeat_interval * jitter
+	// see here for more information: https://discord.com/developers/docs/topics/gateway#sending-heartbeats
+	jitterTimer := time.NewTimer(time.Duration(rand.Float64() * float64(heartbeatInterval)))
+	select {
+	case <-heartbeatChan:
+		jitterTimer.Stop()
+		return
+
+	case <-jitterTimer.C:
+		g.sendHeartbeat(heartbeatInterval)
+	}
+
+	heartbeatTicker := time.NewTicker(heartbeatInterval)
+	defer heartbeatTicker.Stop()
+
 	for {
 		select {
-		case <-g.heartbeatChan:
+		case <-heartbeatChan:
 			return
 
 		case <-heartbeatTicker.C:
-			g.sendHeartbeat()
+			if !g.checkHeartbeatAck() {
+				g.config.Logger.Warn(g.formatLogs("no heartbeat ACK received since the last heartbeat, reconnecting zombied connection..."))
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				g.close(ctx, websocket.CloseServiceRestart, "heartbeat ACK timeout", StatusChangeCauseHeartbeatTimeout, 0)
+				cancel()
+				go g.reconnect()
+				return
+			}
+			g.sendHeartbeat(heartbeatInterval)
 		}
 	}
 }
 
-func (g *gatewayImpl) sendHeartbeat() {
+// checkHeartbeatAck returns whether the last heartbeat was acknowledged by Discord and counts it as missed if not.
+func (g *gatewayImpl) checkHeartbeatAck() bool {
+	g.heartbeatMu.Lock()
+	defer g.heartbeatMu.Unlock()
+	if !g.heartbeatAcked {
+		g.missedHeartbeatAcks++
+		return false
+	}
+	return true
+}
+
+func (g *gatewayImpl) sendHeartbeat(heartbeatInterval time.Duration) {
 	g.config.Logger.Debug(g.formatLogs("sending heartbeat..."))
 
-	ctx, cancel := context.WithTimeout(context.Background(), g.heartbeatInterval)
+	// the sequence is null until the first dispatch was received
+	var data MessageData
+	if g.config.LastSequenceReceived != nil {
+		data = MessageDataHeartbeat(*g.config.LastSequenceReceived)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), heartbeatInterval)
 	defer cancel()
-	if err := g.Send(ctx, OpcodeHeartbeat, MessageDataHeartbeat(*g.config.LastSequenceReceived)); err != nil {
+	if err := g.Send(ctx, OpcodeHeartbeat, data); err != nil {
 		if err == discord.ErrShardNotConnected || errors.Is(err, syscall.EPIPE) {
 			return
 		}
 		g.config.Logger.Error(g.formatLogs("failed to send heartbeat. error: ", err))
-		g.CloseWithCode(context.TODO(), websocket.CloseServiceRestart, "heartbeat timeout")
+		closeCtx, closeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer closeCancel()
+		g.close(closeCtx, websocket.CloseServiceRestart, "heartbeat timeout", StatusChangeCauseHeartbeatTimeout, 0)
 		go g.reconnect()
 		return
 	}
+	g.heartbeatMu.Lock()
 	g.lastHeartbeatSent = time.Now().UTC()
+	g.heartbeatAcked = false
+	g.heartbeatMu.Unlock()
+}
+
+// beforeIdentify calls the BeforeIdentifyFunc right before identifying, so only identifies which are actually sent use up the session start limit.
+func (g *gatewayImpl) beforeIdentify() error {
+	if g.config.BeforeIdentifyFunc == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.heartbeatInterval)
+	defer cancel()
+	return g.config.BeforeIdentifyFunc(ctx, g)
 }
 
 func (g *gatewayImpl) identify() {
-	g.status = StatusIdentifying
+	g.setStatus(StatusIdentifying, StatusChangeCauseHandshake, 0)
 	g.config.Logger.Debug(g.formatLogs("sending Identify command..."))
 
 	identify := MessageDataIdentify{
@@ -290,7 +565,7 @@ func (g *gatewayImpl) identify() {
 			Browser: g.config.Browser,
 			Device:  g.config.Device,
 		},
-		Compress:       g.config.Compress,
+		Compress:       g.config.Compress && !g.config.TransportCompression && g.config.Encoding == EncodingJSON,
 		LargeThreshold: g.config.LargeThreshold,
 		Intents:        g.config.Intents,
 		Presence:       g.config.Presence,
@@ -300,11 +575,11 @@ func (g *gatewayImpl) identify() {
 	if err := g.Send(context.TODO(), OpcodeIdentify, identify); err != nil {
 		g.config.Logger.Error(g.formatLogs("error sending Identify command err: ", err))
 	}
-	g.status = StatusWaitingForReady
+	g.setStatus(StatusWaitingForReady, StatusChangeCauseHandshake, 0)
 }
 
 func (g *gatewayImpl) resume() {
-	g.status = StatusResuming
+	g.setStatus(StatusResuming, StatusChangeCauseHandshake, 0)
 	resume := MessageDataResume{
 		Token:     g.token,
 		SessionID: *g.config.SessionID,
@@ -319,6 +594,12 @@ func (g *gatewayImpl) resume() {
 
 func (g *gatewayImpl) listen(conn *websocket.Conn) {
 	defer g.config.Logger.Debug(g.formatLogs("exiting listen goroutine..."))
+
+	// the zlib-stream context is bound to the connection
+	var zlibStream *zlibStreamDecompressor
+	if g.config.TransportCompression {
+		zlibStream = newZlibStreamDecompressor()
+	}
 loop:
 	for {
 		mt, data, err := conn.ReadMessage()
@@ -333,14 +614,16 @@ loop:
 			}
 
 			reconnect := true
+			cause := StatusChangeCauseConnectionError
+			var causeCloseCode int
 			if closeError, ok := err.(*websocket.CloseError); ok {
+				cause = StatusChangeCauseCloseCode
+				causeCloseCode = closeError.Code
 				closeCode := CloseEventCodeByCode(closeError.Code)
 				reconnect = closeCode.Reconnect
 
 				if closeCode == CloseEventCodeInvalidSeq {
-					g.config.LastSequenceReceived = nil
-					g.config.SessionID = nil
-					g.config.ResumeURL = nil
+					g.clearSession()
 				}
 				message := g.formatLogsf("gateway close received, reconnect: %t, code: %d, error: %s", g.config.AutoReconnect && reconnect, closeError.Code, closeError.Text)
 				if reconnect {
@@ -357,7 +640,7 @@ loop:
 
 			// make sure the connection is properly closed
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			g.CloseWithCode(ctx, websocket.CloseServiceRestart, "reconnecting")
+			g.close(ctx, websocket.CloseServiceRestart, "reconnecting", cause, causeCloseCode)
 			cancel()
 			if g.config.AutoReconnect && reconnect {
 				go g.reconnect()
@@ -368,6 +651,23 @@ loop:
 			break loop
 		}
 
+		if zlibStream != nil && mt == websocket.BinaryMessage {
+			var complete bool
+			data, complete, err = zlibStream.decompress(data)
+			if err != nil {
+				g.config.Logger.Error(g.formatLogs("error while decompressing zlib-stream. error: ", err))
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				g.close(ctx, websocket.CloseServiceRestart, "zlib-stream error", StatusChangeCauseConnectionError, 0)
+				cancel()
+				go g.reconnect()
+				break loop
+			}
+			if !complete {
+				continue
+			}
+			mt = websocket.TextMessage
+		}
+
 		message, err := g.parseMessage(mt, data)
 		if err != nil {
 			g.config.Logger.Error(g.formatLogs("error while parsing gateway message. error: ", err))
@@ -377,10 +677,24 @@ loop:
 		switch message.Op {
 		case OpcodeHello:
 			g.heartbeatInterval = time.Duration(message.D.(MessageDataHello).HeartbeatInterval) * time.Millisecond
+			g.heartbeatMu.Lock()
 			g.lastHeartbeatReceived = time.Now().UTC()
-			go g.heartbeat()
+			g.heartbeatAcked = true
+			g.heartbeatMu.Unlock()
+
+			g.connMu.Lock()
+			if g.heartbeatChan != nil {
+				close(g.heartbeatChan)
+			}
+			g.heartbeatChan = make(chan struct{})
+			go g.heartbeat(g.heartbeatChan, g.heartbeatInterval)
+			g.connMu.Unlock()
 
 			if g.config.LastSequenceReceived == nil || g.config.SessionID == nil {
+				if err = g.beforeIdentify(); err != nil {
+					g.identifyRefused(err)
+					break loop
+				}
 				g.identify()
 			} else {
 				g.resume()
@@ -390,6 +704,31 @@ loop:
 			// set last sequence received
 			g.config.LastSequenceReceived = &message.S
 
+			if g.config.Recorder != nil {
+				if err = g.config.Recorder.Record(g.config.ShardID, message.S, message.T, message.RawD); err != nil {
+					g.config.Logger.Error(g.formatLogs("failed to record dispatch. error: ", err))
+				}
+			}
+			if g.config.Sink != nil {
+				if err = g.config.Sink.Publish(Dispatch{
+					ShardID:   g.config.ShardID,
+					Sequence:  message.S,
+					EventType: message.T,
+					Data:      message.RawD,
+				}); err != nil {
+					g.config.Logger.Error(g.formatLogs("failed to publish dispatch to sink. error: ", err))
+				}
+			}
+
+			// with an EventFilter dispatches are only decoded if needed
+			decode := g.config.EventFilter == nil || message.T == EventTypeReady || g.config.EventFilter(message.T)
+			if g.config.EventFilter != nil && decode {
+				if message.D, err = message.unmarshalEventData(); err != nil {
+					g.config.Logger.Error(g.formatLogsf("error while decoding %s event. error: %s", message.T, err))
+					continue
+				}
+			}
+
 			eventData, ok := message.D.(EventData)
 			if !ok && message.D != nil {
 				g.config.Logger.Error(g.formatLogsf("invalid message data of type %T received", message.D))
@@ -400,9 +739,12 @@ loop:
 			if readyEvent, ok := eventData.(EventReady); ok {
 				g.config.SessionID = &readyEvent.SessionID
 				g.config.ResumeURL = &readyEvent.ResumeGatewayURL
-				g.status = StatusReady
 				g.config.Logger.Debug(g.formatLogs("ready message received"))
 			}
+			g.saveSession()
+			if message.T == EventTypeReady || message.T == EventTypeResumed {
+				g.setStatus(StatusReady, StatusChangeCauseHandshake, 0)
+			}
 
 			if unknownEvent, ok := eventData.(EventUnknown); ok {
 				g.config.Logger.Debug(g.formatLogsf("unknown event received: %s, data: %s", message.T, unknownEvent))
@@ -416,14 +758,17 @@ loop:
 					Payload:   bytes.NewReader(message.RawD),
 				})
 			}
+			if !decode {
+				continue
+			}
 			g.eventHandlerFunc(message.T, message.S, g.config.ShardID, eventData)
 
 		case OpcodeHeartbeat:
-			g.sendHeartbeat()
+			g.sendHeartbeat(g.heartbeatInterval)
 
 		case OpcodeReconnect:
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			g.CloseWithCode(ctx, websocket.CloseServiceRestart, "received reconnect")
+			g.close(ctx, websocket.CloseServiceRestart, "received reconnect", StatusChangeCauseReconnectOpcode, 0)
 			cancel()
 			go g.reconnect()
 			break loop
@@ -436,24 +781,28 @@ loop:
 				code = websocket.CloseServiceRestart
 			} else {
 				// clear resume info
-				g.config.SessionID = nil
-				g.config.LastSequenceReceived = nil
-				g.config.ResumeURL = nil
+				g.clearSession()
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			g.CloseWithCode(ctx, code, "invalid session")
+			g.close(ctx, code, "invalid session", StatusChangeCauseInvalidSession, 0)
 			cancel()
 			go g.reconnect()
 			break loop
 
 		case OpcodeHeartbeatACK:
 			newHeartbeat := time.Now().UTC()
+			g.heartbeatMu.Lock()
+			lastHeartbeat := g.lastHeartbeatReceived
+			g.lastHeartbeatReceived = newHeartbeat
+			g.heartbeatAcked = true
+			g.missedHeartbeatAcks = 0
+			g.heartbeatMu.Unlock()
+
 			g.eventHandlerFunc(EventTypeHeartbeatAck, message.S, g.config.ShardID, EventHeartbeatAck{
-				LastHeartbeat: g.lastHeartbeatReceived,
+				LastHeartbeat: lastHeartbeat,
 				NewHeartbeat:  newHeartbeat,
 			})
-			g.lastHeartbeatReceived = newHeartbeat
 
 		default:
 			g.config.Logger.Debug(g.formatLogsf("unknown opcode received: %d, data: %s", message.Op, message.D))
@@ -463,7 +812,7 @@ loop:
 
 func (g *gatewayImpl) parseMessage(mt int, data []byte) (Message, error) {
 	var finalData []byte
-	if mt == websocket.BinaryMessage {
+	if mt == websocket.BinaryMessage && g.config.Encoding == EncodingJSON {
 		g.config.Logger.Trace(g.formatLogs("binary message received. decompressing..."))
 
 		reader, err := zlib.NewReader(bytes.NewReader(data))
@@ -475,6 +824,15 @@ func (g *gatewayImpl) parseMessage(mt int, data []byte) (Message, error) {
 		if err != nil {
 			return Message{}, fmt.Errorf("failed to read decompressed data: %w", err)
 		}
+	} else if g.config.Encoding == EncodingETF {
+		// the data is only transcoded to JSON for the consumers of the raw dispatch data
+		needsRaw := g.config.Recorder != nil || g.config.Sink != nil || g.config.EnableRawEvents
+		var message Message
+		if err := message.unmarshalETF(data, g.config.EventFilter != nil, needsRaw); err != nil {
+			return Message{}, fmt.Errorf("failed to decode etf: %w", err)
+		}
+		g.config.Logger.Trace(g.formatLogsf("received gateway message: op: %d, t: %s", message.Op, message.T))
+		return message, nil
 	} else {
 		finalData = data
 	}
@@ -482,5 +840,5 @@ func (g *gatewayImpl) parseMessage(mt int, data []byte) (Message, error) {
 	g.config.Logger.Trace(g.formatLogs("received gateway message: ", string(finalData)))
 
 	var message Message
-	return message, json.Unmarshal(finalData, &message)
+	return message, message.unmarshal(finalData, g.config.EventFilter != nil)
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/disgoorg/snowflake/v2"
 
 	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway/etf"
 )
 
 // Message raw Message type
@@ -14,9 +15,20 @@ type Message struct {
 	T    EventType       `json:"t,omitempty"`
 	D    MessageData     `json:"d,omitempty"`
 	RawD json.RawMessage `json:"-"`
+
+	// rawETF is the undecoded data of a dispatch received in the External Term Format
+	rawETF etf.RawTerm
 }
 
 func (e *Message) UnmarshalJSON(data []byte) error {
+	return e.unmarshal(data, false)
+}
+
+// unmarshalFunc decodes data into v. It is json.Unmarshal or etf.Unmarshal depending on the Encoding the payload was received in.
+type unmarshalFunc func(data []byte, v any) error
+
+// unmarshal unmarshals the Message. If lazyDispatch is true, the MessageData of dispatches is not decoded and only available in RawD.
+func (e *Message) unmarshal(data []byte, lazyDispatch bool) error {
 	var v struct {
 		Op Opcode          `json:"op"`
 		S  int             `json:"s,omitempty"`
@@ -27,80 +39,135 @@ func (e *Message) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	messageData, err := unmarshalMessageData(v.D, v.Op, v.T, lazyDispatch, json.Unmarshal)
+	if err != nil {
+		return err
+	}
+	e.Op = v.Op
+	e.S = v.S
+	e.T = v.T
+	e.D = messageData
+	e.RawD = v.D
+	return nil
+}
+
+// unmarshalETF unmarshals the Message from the External Term Format. The MessageData is decoded directly from the term.
+// RawD is only transcoded to JSON if needsRaw is true. If lazyDispatch is true, the MessageData of dispatches is not decoded and can be decoded
+// later with unmarshalEventData.
+func (e *Message) unmarshalETF(data []byte, lazyDispatch bool, needsRaw bool) error {
+	var v struct {
+		Op Opcode      `json:"op"`
+		S  int         `json:"s,omitempty"`
+		T  EventType   `json:"t,omitempty"`
+		D  etf.RawTerm `json:"d,omitempty"`
+	}
+	if err := etf.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	messageData, err := unmarshalMessageData(v.D, v.Op, v.T, lazyDispatch, etf.Unmarshal)
+	if err != nil {
+		return err
+	}
+	var rawD json.RawMessage
+	if needsRaw && v.D != nil {
+		if rawD, err = etf.ToJSON(v.D); err != nil {
+			return err
+		}
+	}
+	e.Op = v.Op
+	e.S = v.S
+	e.T = v.T
+	e.D = messageData
+	e.RawD = rawD
+	if lazyDispatch {
+		e.rawETF = v.D
+	}
+	return nil
+}
+
+// unmarshalEventData decodes the EventData of a dispatch which was unmarshalled lazily.
+func (e *Message) unmarshalEventData() (EventData, error) {
+	if e.rawETF != nil {
+		return unmarshalEventData(e.rawETF, e.T, etf.Unmarshal)
+	}
+	return UnmarshalEventData(e.RawD, e.T)
+}
+
+func unmarshalMessageData(data []byte, op Opcode, eventType EventType, lazyDispatch bool, unmarshal unmarshalFunc) (MessageData, error) {
 	var (
 		messageData MessageData
 		err         error
 	)
 
-	switch v.Op {
+	switch op {
 	case OpcodeDispatch:
-		messageData, err = UnmarshalEventData(v.D, v.T)
+		if !lazyDispatch {
+			messageData, err = unmarshalEventData(data, eventType, unmarshal)
+		}
 
 	case OpcodeHeartbeat:
 		var d MessageDataHeartbeat
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodeIdentify:
 		var d MessageDataIdentify
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodePresenceUpdate:
 		var d MessageDataPresenceUpdate
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodeVoiceStateUpdate:
 		var d MessageDataVoiceStateUpdate
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodeResume:
 		var d MessageDataResume
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodeReconnect:
 
 	case OpcodeRequestGuildMembers:
 		var d MessageDataRequestGuildMembers
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodeInvalidSession:
 		var d MessageDataInvalidSession
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodeHello:
 		var d MessageDataHello
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 
 	case OpcodeHeartbeatACK:
 
 	default:
 		var d MessageDataUnknown
-		err = json.Unmarshal(v.D, &d)
+		err = unmarshal(data, &d)
 		messageData = d
 	}
-	if err != nil {
-		return err
-	}
-	e.Op = v.Op
-	e.S = v.S
-	e.T = v.T
-	e.D = messageData
-	e.RawD = v.D
-	return nil
+	return messageData, err
 }
 
 type MessageData interface {
 	messageData()
 }
 
+// UnmarshalEventData decodes the JSON data of a dispatch of the given EventType.
 func UnmarshalEventData(data []byte, eventType EventType) (EventData, error) {
+	return unmarshalEventData(data, eventType, json.Unmarshal)
+}
+
+func unmarshalEventData(data []byte, eventType EventType, unmarshal unmarshalFunc) (EventData, error) {
 	var (
 		eventData EventData
 		err       error
@@ -108,7 +175,7 @@ func UnmarshalEventData(data []byte, eventType EventType) (EventData, error) {
 	switch eventType {
 	case EventTypeReady:
 		var d EventReady
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeResumed:
@@ -116,302 +183,315 @@ func UnmarshalEventData(data []byte, eventType EventType) (EventData, error) {
 
 	case EventTypeApplicationCommandPermissionsUpdate:
 		var d EventApplicationCommandPermissionsUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeAutoModerationRuleCreate:
 		var d EventAutoModerationRuleCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeAutoModerationRuleUpdate:
 		var d EventAutoModerationRuleUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeAutoModerationRuleDelete:
 		var d EventAutoModerationRuleDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeAutoModerationActionExecution:
 		var d EventAutoModerationActionExecution
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeChannelCreate:
 		var d EventChannelCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeChannelUpdate:
 		var d EventChannelUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeChannelDelete:
 		var d EventChannelDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeChannelPinsUpdate:
 		var d EventChannelPinsUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeThreadCreate:
 		var d EventThreadCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeThreadUpdate:
 		var d EventThreadUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeThreadDelete:
 		var d EventThreadDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeThreadListSync:
 		var d EventThreadListSync
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeThreadMemberUpdate:
 		var d EventThreadMemberUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeThreadMembersUpdate:
 		var d EventThreadMembersUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildCreate:
 		var d EventGuildCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildUpdate:
 		var d EventGuildUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildDelete:
 		var d EventGuildDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildAuditLogEntryCreate:
 		var d EventGuildAuditLogEntryCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildBanAdd:
 		var d EventGuildBanAdd
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildBanRemove:
 		var d EventGuildBanRemove
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildEmojisUpdate:
 		var d EventGuildEmojisUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildStickersUpdate:
 		var d EventGuildStickersUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildIntegrationsUpdate:
 		var d EventGuildIntegrationsUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildMemberAdd:
 		var d EventGuildMemberAdd
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildMemberRemove:
 		var d EventGuildMemberRemove
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildMemberUpdate:
 		var d EventGuildMemberUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildMembersChunk:
 		var d EventGuildMembersChunk
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildRoleCreate:
 		var d EventGuildRoleCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildRoleUpdate:
 		var d EventGuildRoleUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildRoleDelete:
 		var d EventGuildRoleDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildScheduledEventCreate:
 		var d EventGuildScheduledEventCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildScheduledEventUpdate:
 		var d EventGuildScheduledEventUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildScheduledEventDelete:
 		var d EventGuildScheduledEventDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildScheduledEventUserAdd:
 		var d EventGuildScheduledEventUserAdd
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeGuildScheduledEventUserRemove:
 		var d EventGuildScheduledEventUserRemove
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeIntegrationCreate:
 		var d EventIntegrationCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeIntegrationUpdate:
 		var d EventIntegrationUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeIntegrationDelete:
 		var d EventIntegrationDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeInteractionCreate:
 		var d EventInteractionCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeInviteCreate:
 		var d EventInviteCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeInviteDelete:
 		var d EventInviteDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageCreate:
 		var d EventMessageCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageUpdate:
 		var d EventMessageUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageDelete:
 		var d EventMessageDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageDeleteBulk:
 		var d EventMessageDeleteBulk
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageReactionAdd:
 		var d EventMessageReactionAdd
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageReactionRemove:
 		var d EventMessageReactionRemove
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageReactionRemoveAll:
 		var d EventMessageReactionRemoveAll
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeMessageReactionRemoveEmoji:
 		var d EventMessageReactionRemoveEmoji
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypePresenceUpdate:
 		var d EventPresenceUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeStageInstanceCreate:
 		var d EventStageInstanceCreate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeStageInstanceUpdate:
 		var d EventStageInstanceUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeStageInstanceDelete:
 		var d EventStageInstanceDelete
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeTypingStart:
 		var d EventTypingStart
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeUserUpdate:
 		var d EventUserUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeVoiceStateUpdate:
 		var d EventVoiceStateUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeVoiceServerUpdate:
 		var d EventVoiceServerUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	case EventTypeWebhooksUpdate:
 		var d EventWebhooksUpdate
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 
 	default:
+		if decoder, ok := eventDecoder(eventType); ok {
+			// custom decoders always receive JSON
+			var (
+				raw json.RawMessage
+				d   any
+			)
+			if err = unmarshal(data, &raw); err != nil {
+				break
+			}
+			d, err = decoder(raw)
+			eventData = EventCustom{Data: d}
+			break
+		}
 		var d EventUnknown
-		err = json.Unmarshal(data, &d)
+		err = unmarshal(data, &d)
 		eventData = d
 	}
 
@@ -420,6 +500,14 @@ func UnmarshalEventData(data []byte, eventType EventType) (EventData, error) {
 
 type MessageDataUnknown json.RawMessage
 
+func (e MessageDataUnknown) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(e).MarshalJSON()
+}
+
+func (e *MessageDataUnknown) UnmarshalJSON(data []byte) error {
+	return (*json.RawMessage)(e).UnmarshalJSON(data)
+}
+
 func (MessageDataUnknown) messageData() {}
 
 // MessageDataHeartbeat is used to ensure the websocket connection remains open, and disconnect if not.
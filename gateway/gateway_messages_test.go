@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/gateway/etf"
+)
+
+// etfTerm builds External Term Format terms the way Discord encodes them.
+type etfTerm []byte
+
+func etfAtom(name string) etfTerm {
+	return append(etfTerm{119, byte(len(name))}, name...)
+}
+
+func etfBinary(s string) etfTerm {
+	t := etfTerm{109, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(t[1:], uint32(len(s)))
+	return append(t, s...)
+}
+
+func etfInt(i uint64) etfTerm {
+	if i <= 255 {
+		return etfTerm{97, byte(i)}
+	}
+	// Discord encodes all integers which don't fit into 32 bits as small big
+	t := etfTerm{110, 8, 0}
+	for n := 0; n < 8; n++ {
+		t = append(t, byte(i>>(8*n)))
+	}
+	return t
+}
+
+func etfMap(kv ...any) etfTerm {
+	t := etfTerm{116, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(t[1:], uint32(len(kv)/2))
+	for i := 0; i < len(kv); i += 2 {
+		t = append(t, etfAtom(kv[i].(string))...)
+		t = append(t, kv[i+1].(etfTerm)...)
+	}
+	return t
+}
+
+func etfList(elems ...etfTerm) etfTerm {
+	t := etfTerm{108, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(t[1:], uint32(len(elems)))
+	for _, elem := range elems {
+		t = append(t, elem...)
+	}
+	return append(t, 106)
+}
+
+func TestMessageUnmarshalETF(t *testing.T) {
+	// application id is a snowflake which fits into a JSON number without losing precision
+	const applicationID = snowflake.ID(1 << 40)
+	ready := etfMap(
+		"v", etfInt(10),
+		"user", etfMap(
+			"id", etfInt(1039203432584773632),
+			"username", etfBinary("disgo"),
+			"avatar", etfAtom("nil"),
+			"bot", etfAtom("true"),
+		),
+		"guilds", etfList(
+			etfMap("id", etfInt(817327181659111454), "unavailable", etfAtom("true")),
+			etfMap("id", etfInt(1), "unavailable", etfAtom("true")),
+		),
+		"session_id", etfBinary("session"),
+		"resume_gateway_url", etfBinary("wss://gateway.discord.gg"),
+		"shard", etfTerm{107, 0, 2, 0, 1},
+		"application", etfMap("id", etfInt(uint64(applicationID)), "flags", etfInt(1<<23)),
+		"_trace", etfList(etfBinary(`["gateway-prd",{"micros":0.0}]`)),
+	)
+	data := append([]byte{etf.Version}, etfMap(
+		"op", etfInt(0),
+		"s", etfInt(1),
+		"t", etfAtom("READY"),
+		"d", ready,
+	)...)
+
+	var message Message
+	assert.NoError(t, message.unmarshalETF(data, false, true))
+	assert.Equal(t, OpcodeDispatch, message.Op)
+	assert.Equal(t, 1, message.S)
+	assert.Equal(t, EventTypeReady, message.T)
+
+	assert.IsType(t, EventReady{}, message.D)
+	readyEvent := message.D.(EventReady)
+	assert.Equal(t, 10, readyEvent.Version)
+	assert.Equal(t, snowflake.ID(1039203432584773632), readyEvent.User.ID)
+	assert.Equal(t, "disgo", readyEvent.User.Username)
+	assert.Nil(t, readyEvent.User.Avatar)
+	assert.True(t, readyEvent.User.Bot)
+	if assert.Len(t, readyEvent.Guilds, 2) {
+		assert.Equal(t, snowflake.ID(817327181659111454), readyEvent.Guilds[0].ID)
+		assert.Equal(t, snowflake.ID(1), readyEvent.Guilds[1].ID)
+		assert.True(t, readyEvent.Guilds[1].Unavailable)
+	}
+	assert.Equal(t, "session", readyEvent.SessionID)
+	assert.Equal(t, "wss://gateway.discord.gg", readyEvent.ResumeGatewayURL)
+	assert.Equal(t, [2]int{0, 1}, readyEvent.Shard)
+	assert.Equal(t, applicationID, readyEvent.Application.ID)
+
+	// the raw data is still JSON for recorders, sinks & raw events
+	eventData, err := UnmarshalEventData(message.RawD, EventTypeReady)
+	assert.NoError(t, err)
+	assert.Equal(t, readyEvent.User.ID, eventData.(EventReady).User.ID)
+
+	var lazyMessage Message
+	assert.NoError(t, lazyMessage.unmarshalETF(data, true, false))
+	assert.Nil(t, lazyMessage.D)
+	assert.Nil(t, lazyMessage.RawD)
+	eventData, err = lazyMessage.unmarshalEventData()
+	assert.NoError(t, err)
+	assert.Equal(t, readyEvent, eventData)
+}
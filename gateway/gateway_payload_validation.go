@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+const (
+	// MaxPayloadSize is the maximum size in bytes of an encoded payload sent to the Gateway.
+	MaxPayloadSize = 4096
+
+	// MaxPresenceActivities is the maximum number of activities a bot can set in its presence.
+	MaxPresenceActivities = 1
+
+	// MaxRequestGuildMembersUserIDs is the maximum number of user IDs in a single MessageDataRequestGuildMembers.
+	MaxRequestGuildMembersUserIDs = 100
+
+	// MaxRequestGuildMembersNonceLength is the maximum length in bytes of the nonce of a MessageDataRequestGuildMembers.
+	MaxRequestGuildMembersNonceLength = 32
+)
+
+var (
+	_ error = (*PayloadTooLargeError)(nil)
+	_ error = (*InvalidPayloadError)(nil)
+)
+
+// PayloadTooLargeError is returned by Gateway.Send when the encoded payload exceeds MaxPayloadSize.
+// Discord would close the connection with CloseEventCodeDecodeError.
+type PayloadTooLargeError struct {
+	Op   Opcode
+	Size int
+}
+
+// Error returns the error formatted as string
+func (e PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("payload with opcode %d is %d bytes, which exceeds the limit of %d bytes", e.Op, e.Size, MaxPayloadSize)
+}
+
+// InvalidPayloadError is returned by Gateway.Send when the MessageData violates Discord's rules for the Opcode.
+// Discord would close the connection with CloseEventCodeDecodeError or ignore the payload.
+type InvalidPayloadError struct {
+	Op     Opcode
+	Reason string
+	// Err is the underlying error if there is one, like discord.ErrNoGuildMembersIntent.
+	Err error
+}
+
+// Error returns the error formatted as string
+func (e InvalidPayloadError) Error() string {
+	return fmt.Sprintf("invalid payload with opcode %d: %s", e.Op, e.Reason)
+}
+
+// Unwrap returns the underlying error
+func (e InvalidPayloadError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateMessageData checks the MessageData sent with the Opcode against Discord's rules.
+// Intents are the Intents of the Gateway sending the MessageData.
+// It returns an InvalidPayloadError if a rule is violated.
+func ValidateMessageData(op Opcode, d MessageData, intents Intents) error {
+	switch data := d.(type) {
+	case MessageDataIdentify:
+		if data.Presence != nil {
+			return validatePresence(op, *data.Presence)
+		}
+
+	case MessageDataPresenceUpdate:
+		return validatePresence(op, data)
+
+	case MessageDataRequestGuildMembers:
+		return validateRequestGuildMembers(op, data, intents)
+	}
+	return nil
+}
+
+func validatePresence(op Opcode, presence MessageDataPresenceUpdate) error {
+	if len(presence.Activities) > MaxPresenceActivities {
+		return InvalidPayloadError{Op: op, Reason: fmt.Sprintf("presence has %d activities, but at most %d are allowed", len(presence.Activities), MaxPresenceActivities)}
+	}
+	return nil
+}
+
+func validateRequestGuildMembers(op Opcode, data MessageDataRequestGuildMembers, intents Intents) error {
+	if data.Query != nil && len(data.UserIDs) > 0 {
+		return InvalidPayloadError{Op: op, Reason: "query and user_ids are mutually exclusive"}
+	}
+	if data.Query == nil && len(data.UserIDs) == 0 {
+		return InvalidPayloadError{Op: op, Reason: "either query or user_ids is required"}
+	}
+	if len(data.UserIDs) > MaxRequestGuildMembersUserIDs {
+		return InvalidPayloadError{Op: op, Reason: fmt.Sprintf("%d user_ids requested, but at most %d are allowed", len(data.UserIDs), MaxRequestGuildMembersUserIDs)}
+	}
+	if data.Query != nil {
+		if data.Limit == nil {
+			return InvalidPayloadError{Op: op, Reason: "limit is required with query"}
+		}
+		if *data.Limit < 0 {
+			return InvalidPayloadError{Op: op, Reason: "limit must not be negative"}
+		}
+		// requesting all members with an empty query and no limit requires the GUILD_MEMBERS intent
+		if *data.Query == "" && *data.Limit == 0 && intents.Missing(IntentGuildMembers) {
+			return InvalidPayloadError{Op: op, Reason: "requesting all members requires the GUILD_MEMBERS intent", Err: discord.ErrNoGuildMembersIntent}
+		}
+	}
+	if len(data.Nonce) > MaxRequestGuildMembersNonceLength {
+		return InvalidPayloadError{Op: op, Reason: fmt.Sprintf("nonce is %d bytes, but at most %d are allowed", len(data.Nonce), MaxRequestGuildMembersNonceLength)}
+	}
+	return nil
+}
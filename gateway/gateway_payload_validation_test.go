@@ -0,0 +1,47 @@
+package gateway
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+func TestValidateRequestGuildMembers(t *testing.T) {
+	query := ""
+	limit := 0
+
+	var invalidPayloadErr InvalidPayloadError
+	err := ValidateMessageData(OpcodeRequestGuildMembers, MessageDataRequestGuildMembers{
+		GuildID: 1,
+		Query:   &query,
+		UserIDs: []snowflake.ID{2},
+	}, IntentsNone)
+	assert.True(t, errors.As(err, &invalidPayloadErr))
+
+	err = ValidateMessageData(OpcodeRequestGuildMembers, MessageDataRequestGuildMembers{
+		GuildID: 1,
+		Query:   &query,
+		Limit:   &limit,
+	}, IntentsNone)
+	assert.ErrorIs(t, err, discord.ErrNoGuildMembersIntent)
+
+	assert.NoError(t, ValidateMessageData(OpcodeRequestGuildMembers, MessageDataRequestGuildMembers{
+		GuildID: 1,
+		Query:   &query,
+		Limit:   &limit,
+	}, IntentGuildMembers))
+}
+
+func TestValidatePresenceActivities(t *testing.T) {
+	presence := MessageDataPresenceUpdate{
+		Activities: []discord.Activity{{Name: "a"}, {Name: "b"}},
+		Status:     discord.OnlineStatusOnline,
+	}
+	var invalidPayloadErr InvalidPayloadError
+	assert.True(t, errors.As(ValidateMessageData(OpcodePresenceUpdate, presence, IntentsNone), &invalidPayloadErr))
+	assert.True(t, errors.As(ValidateMessageData(OpcodeIdentify, MessageDataIdentify{Presence: &presence}, IntentsNone), &invalidPayloadErr))
+}
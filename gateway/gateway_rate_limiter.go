@@ -4,19 +4,74 @@ import (
 	"context"
 )
 
+// SendLane is a queue of the RateLimiter. Each Opcode is sent through one SendLane.
+type SendLane int
+
+const (
+	// SendLanePriority is used for OpcodeHeartbeat, OpcodeIdentify & OpcodeResume.
+	// It always goes first and has commands reserved, so it can't be delayed by other commands.
+	SendLanePriority SendLane = iota
+
+	// SendLanePresence is used for OpcodePresenceUpdate.
+	SendLanePresence
+
+	// SendLaneVoiceState is used for OpcodeVoiceStateUpdate.
+	SendLaneVoiceState
+
+	// SendLaneDefault is used for all other opcodes like OpcodeRequestGuildMembers.
+	SendLaneDefault
+)
+
+// SendLanes contains all SendLane(s) ordered by priority.
+var SendLanes = []SendLane{SendLanePriority, SendLanePresence, SendLaneVoiceState, SendLaneDefault}
+
+// SendLaneByOpcode returns the SendLane the given Opcode is sent through.
+func SendLaneByOpcode(op Opcode) SendLane {
+	switch op {
+	case OpcodeHeartbeat, OpcodeIdentify, OpcodeResume:
+		return SendLanePriority
+	case OpcodePresenceUpdate:
+		return SendLanePresence
+	case OpcodeVoiceStateUpdate:
+		return SendLaneVoiceState
+	default:
+		return SendLaneDefault
+	}
+}
+
+// String returns the name of the SendLane.
+func (l SendLane) String() string {
+	switch l {
+	case SendLanePriority:
+		return "Priority"
+	case SendLanePresence:
+		return "Presence"
+	case SendLaneVoiceState:
+		return "VoiceState"
+	case SendLaneDefault:
+		return "Default"
+	default:
+		return "Unknown"
+	}
+}
+
 // RateLimiter provides handles the rate limiting logic for connecting to Discord's Gateway.
+// Messages are queued per SendLane. SendLanePriority is always served first, all other SendLane(s) take turns.
 type RateLimiter interface {
-	// Close gracefully closes the RateLimiter.
+	// Close gracefully closes the RateLimiter. Queued messages are rejected with discord.ErrShardNotConnected.
 	// If the context deadline is exceeded, the RateLimiter will be closed immediately.
 	Close(ctx context.Context)
 
 	// Reset resets the RateLimiter to its initial state.
 	Reset()
 
-	// Wait waits for the RateLimiter to be ready to send a new message.
+	// Wait waits for the RateLimiter to be ready to send a new message with the given Opcode.
 	// If the context deadline is exceeded, Wait will return immediately and no message will be sent.
-	Wait(ctx context.Context) error
+	Wait(ctx context.Context, op Opcode) error
 
 	// Unlock unlocks the RateLimiter and allows the next message to be sent.
 	Unlock()
+
+	// QueueDepth returns how many messages are waiting in the given SendLane.
+	QueueDepth(lane SendLane) int
 }
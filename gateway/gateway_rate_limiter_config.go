@@ -9,6 +9,7 @@ func DefaultRateLimiterConfig() *RateLimiterConfig {
 	return &RateLimiterConfig{
 		Logger:            log.Default(),
 		CommandsPerMinute: 120,
+		PriorityReserve:   5,
 	}
 }
 
@@ -16,6 +17,7 @@ func DefaultRateLimiterConfig() *RateLimiterConfig {
 type RateLimiterConfig struct {
 	Logger            log.Logger
 	CommandsPerMinute int
+	PriorityReserve   int
 }
 
 // RateLimiterConfigOpt is a type alias for a function that takes a RateLimiterConfig and is used to configure your Server.
@@ -41,3 +43,10 @@ func WithCommandsPerMinute(commandsPerMinute int) RateLimiterConfigOpt {
 		config.CommandsPerMinute = commandsPerMinute
 	}
 }
+
+// WithPriorityReserve sets the number of commands per minute which are reserved for SendLanePriority.
+func WithPriorityReserve(priorityReserve int) RateLimiterConfigOpt {
+	return func(config *RateLimiterConfig) {
+		config.PriorityReserve = priorityReserve
+	}
+}
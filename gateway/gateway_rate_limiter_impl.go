@@ -2,71 +2,221 @@ package gateway
 
 import (
 	"context"
+	"sync"
 	"time"
 
-	"github.com/sasha-s/go-csync"
+	"github.com/disgoorg/disgo/discord"
 )
 
+var _ RateLimiter = (*rateLimiterImpl)(nil)
+
 // NewRateLimiter creates a new default RateLimiter with the given RateLimiterConfigOpt(s).
 func NewRateLimiter(opts ...RateLimiterConfigOpt) RateLimiter {
 	config := DefaultRateLimiterConfig()
 	config.Apply(opts)
 
 	return &rateLimiterImpl{
+		queues: make([][]*waiter, len(SendLanes)),
 		config: *config,
 	}
 }
 
+type waiter struct {
+	lane    SendLane
+	ready   chan struct{}
+	granted bool
+	err     error
+}
+
 type rateLimiterImpl struct {
-	mu csync.Mutex
+	mu sync.Mutex
 
 	reset     time.Time
 	remaining int
 
+	queues   [][]*waiter
+	nextLane SendLane
+	busy     bool
+	idle     chan struct{}
+	closed   bool
+	timer    *time.Timer
+
 	config RateLimiterConfig
 }
 
 func (l *rateLimiterImpl) Close(ctx context.Context) {
-	_ = l.mu.CLock(ctx)
+	l.mu.Lock()
+	l.closed = true
+	for lane, queue := range l.queues {
+		for _, w := range queue {
+			w.err = discord.ErrShardNotConnected
+			close(w.ready)
+		}
+		l.queues[lane] = nil
+	}
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	idle := l.idle
+	busy := l.busy
+	l.mu.Unlock()
+
+	// wait for the message which is currently sent
+	if busy {
+		select {
+		case <-ctx.Done():
+		case <-idle:
+		}
+	}
 }
 
 func (l *rateLimiterImpl) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.reset = time.Time{}
 	l.remaining = 0
-	l.mu = csync.Mutex{}
-}
-
-func (l *rateLimiterImpl) Wait(ctx context.Context) error {
-	l.config.Logger.Trace("locking gateway rate limiter")
-	if err := l.mu.CLock(ctx); err != nil {
-		return err
+	l.busy = false
+	if l.idle != nil {
+		close(l.idle)
+		l.idle = nil
 	}
+	l.closed = false
+	l.nextLane = SendLanePriority
+}
 
-	now := time.Now()
-
-	var until time.Time
+func (l *rateLimiterImpl) Wait(ctx context.Context, op Opcode) error {
+	lane := SendLaneByOpcode(op)
+	l.config.Logger.Tracef("waiting for gateway rate limiter in lane %s", lane)
 
-	if l.remaining == 0 && l.reset.After(now) {
-		until = l.reset
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return discord.ErrShardNotConnected
 	}
+	w := &waiter{
+		lane:  lane,
+		ready: make(chan struct{}),
+	}
+	l.queues[lane] = append(l.queues[lane], w)
+	l.dispatch()
+	l.mu.Unlock()
 
-	if until.After(now) {
-		select {
-		case <-ctx.Done():
-			l.Unlock()
-			return ctx.Err()
-		case <-time.After(until.Sub(now)):
+	select {
+	case <-w.ready:
+		return w.err
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.granted {
+			// we got our turn while the context was cancelled, give it to the next message
+			l.release()
+		} else if w.err == nil {
+			l.remove(w)
 		}
+		return ctx.Err()
 	}
-	return nil
 }
 
 func (l *rateLimiterImpl) Unlock() {
 	l.config.Logger.Trace("unlocking gateway rate limiter")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.busy {
+		return
+	}
 	now := time.Now()
 	if l.reset.Before(now) {
 		l.reset = now.Add(time.Minute)
 		l.remaining = l.config.CommandsPerMinute
 	}
-	l.mu.Unlock()
+	l.remaining--
+	l.release()
+}
+
+func (l *rateLimiterImpl) QueueDepth(lane SendLane) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if int(lane) < 0 || int(lane) >= len(l.queues) {
+		return 0
+	}
+	return len(l.queues[lane])
+}
+
+// release frees the send slot and hands it to the next waiting message.
+func (l *rateLimiterImpl) release() {
+	l.busy = false
+	if l.idle != nil {
+		close(l.idle)
+		l.idle = nil
+	}
+	l.dispatch()
+}
+
+func (l *rateLimiterImpl) remove(w *waiter) {
+	queue := l.queues[w.lane]
+	for i := range queue {
+		if queue[i] == w {
+			l.queues[w.lane] = append(queue[:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// dispatch hands the send slot to the next message if there is budget left.
+// SendLanePriority always goes first and may use the reserved budget, all other lanes take turns.
+func (l *rateLimiterImpl) dispatch() {
+	if l.busy || l.closed {
+		return
+	}
+
+	now := time.Now()
+	remaining := l.remaining
+	if l.reset.Before(now) {
+		remaining = l.config.CommandsPerMinute
+	}
+
+	var next *waiter
+	if len(l.queues[SendLanePriority]) > 0 {
+		if remaining > 0 {
+			next = l.queues[SendLanePriority][0]
+		}
+	} else if remaining > l.config.PriorityReserve {
+		for i := 0; i < len(SendLanes)-1; i++ {
+			lane := (l.nextLane+SendLane(i))%(SendLane(len(SendLanes))-1) + 1
+			if len(l.queues[lane]) > 0 {
+				next = l.queues[lane][0]
+				l.nextLane = lane % (SendLane(len(SendLanes)) - 1)
+				break
+			}
+		}
+	}
+
+	if next == nil {
+		if l.hasWaiters() && l.timer == nil {
+			l.config.Logger.Debugf("gateway rate limit exceeded, waiting until %s", l.reset)
+			l.timer = time.AfterFunc(l.reset.Sub(now), func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				l.timer = nil
+				l.dispatch()
+			})
+		}
+		return
+	}
+
+	l.queues[next.lane] = l.queues[next.lane][1:]
+	l.busy = true
+	l.idle = make(chan struct{})
+	next.granted = true
+	close(next.ready)
+}
+
+func (l *rateLimiterImpl) hasWaiters() bool {
+	for _, queue := range l.queues {
+		if len(queue) > 0 {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,27 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterPriorityReserve(t *testing.T) {
+	limiter := NewRateLimiter(WithCommandsPerMinute(3), WithPriorityReserve(1))
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, limiter.Wait(context.Background(), OpcodeRequestGuildMembers))
+		limiter.Unlock()
+	}
+
+	// the last command is reserved for heartbeats, identify & resume
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx, OpcodePresenceUpdate), context.DeadlineExceeded)
+	assert.Equal(t, 0, limiter.QueueDepth(SendLanePresence))
+
+	assert.NoError(t, limiter.Wait(context.Background(), OpcodeHeartbeat))
+	limiter.Unlock()
+}
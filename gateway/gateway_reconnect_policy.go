@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy decides how long the Gateway waits before each reconnect attempt and when it gives up.
+type ReconnectPolicy interface {
+	// Delay returns how long the Gateway should wait before the given attempt, starting at 0.
+	// If false is returned, the Gateway gives up reconnecting and calls its CloseHandlerFunc.
+	Delay(try int) (time.Duration, bool)
+}
+
+var _ ReconnectPolicy = (*BackoffReconnectPolicy)(nil)
+
+// DefaultReconnectPolicy returns the BackoffReconnectPolicy used by default.
+// It retries forever with delays doubling from 2 seconds up to 30 seconds and 50% jitter.
+func DefaultReconnectPolicy() *BackoffReconnectPolicy {
+	return &BackoffReconnectPolicy{
+		BaseDelay: 2 * time.Second,
+		MaxDelay:  30 * time.Second,
+		Jitter:    0.5,
+	}
+}
+
+// BackoffReconnectPolicy is a ReconnectPolicy with exponential backoff and jitter.
+// The first attempt is only delayed by the jitter, every following attempt waits twice as long as the previous one.
+// A nil or zero value BackoffReconnectPolicy behaves like the DefaultReconnectPolicy without jitter.
+type BackoffReconnectPolicy struct {
+	// BaseDelay is the delay before the second attempt. It also is the delay the jitter of the first attempt is based on. Defaults to 2 seconds.
+	BaseDelay time.Duration
+	// MaxDelay is the maximum delay between two attempts. Defaults to 30 seconds or BaseDelay if it is greater.
+	MaxDelay time.Duration
+	// Jitter is the fraction of the delay which is randomized, between 0 and 1.
+	// This prevents multiple shards from reconnecting in lockstep.
+	Jitter float64
+	// MaxAttempts is the maximum number of attempts before giving up. 0 means unlimited attempts.
+	MaxAttempts int
+}
+
+func (p *BackoffReconnectPolicy) Delay(try int) (time.Duration, bool) {
+	if p == nil {
+		p = &BackoffReconnectPolicy{}
+	}
+	if p.MaxAttempts > 0 && try >= p.MaxAttempts {
+		return 0, false
+	}
+
+	baseDelay := p.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = 2 * time.Second
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	if maxDelay < baseDelay {
+		maxDelay = baseDelay
+	}
+
+	if try == 0 {
+		// spread the first attempt of all shards over the jitter of the base delay
+		if p.Jitter > 0 {
+			return time.Duration(rand.Float64() * p.Jitter * float64(baseDelay)), true
+		}
+		return 0, true
+	}
+
+	delay := baseDelay
+	for i := 1; i < try && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay, true
+}
@@ -0,0 +1,38 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffReconnectPolicy(t *testing.T) {
+	policy := DefaultReconnectPolicy()
+	first := map[time.Duration]struct{}{}
+	for i := 0; i < 10; i++ {
+		delay, ok := policy.Delay(0)
+		assert.True(t, ok)
+		assert.Less(t, delay, policy.BaseDelay)
+		first[delay] = struct{}{}
+	}
+	assert.Greater(t, len(first), 1, "the first attempt should be jittered")
+
+	// zero values and nil don't retry in a tight loop
+	var zero BackoffReconnectPolicy
+	delay, ok := zero.Delay(1)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, delay)
+	delay, ok = zero.Delay(10)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	var nilPolicy *BackoffReconnectPolicy
+	delay, ok = nilPolicy.Delay(1)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, delay)
+
+	limited := &BackoffReconnectPolicy{BaseDelay: time.Second, MaxAttempts: 2}
+	_, ok = limited.Delay(2)
+	assert.False(t, ok)
+}
@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+)
+
+// RecordedDispatch is a single dispatch written by a Recorder as one line of newline-delimited JSON.
+type RecordedDispatch struct {
+	ShardID   int             `json:"shard_id"`
+	Sequence  int             `json:"s"`
+	Timestamp time.Time       `json:"timestamp"`
+	EventType EventType       `json:"t"`
+	Data      json.RawMessage `json:"d"`
+}
+
+// NewRecorder returns a new Recorder which writes to the given io.Writer.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Recorder writes every dispatch received by a Gateway as newline-delimited JSON.
+// Recordings can be replayed with NewReplay.
+// A Recorder is safe for concurrent use, so all shards can share one Recorder.
+type Recorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Record writes a dispatch with the given shard ID, sequence, EventType and raw data, timestamped with the current time.
+func (r *Recorder) Record(shardID int, sequence int, eventType EventType, data json.RawMessage) error {
+	line, err := json.Marshal(RecordedDispatch{
+		ShardID:   shardID,
+		Sequence:  sequence,
+		Timestamp: time.Now().UTC(),
+		EventType: eventType,
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.w.Write(append(line, '\n'))
+	return err
+}
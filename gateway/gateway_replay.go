@@ -0,0 +1,263 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+var _ ReplayGateway = (*replayGatewayImpl)(nil)
+
+// ReplayGateway is a Gateway which replays dispatches recorded by a Recorder instead of connecting to Discord.
+// Commands sent via Send are discarded.
+type ReplayGateway interface {
+	Gateway
+
+	// Done returns a channel which is closed when all dispatches were replayed or the ReplayGateway was closed.
+	Done() <-chan struct{}
+
+	// Err returns the error which stopped the replay early, or nil.
+	Err() error
+}
+
+// NewReplay creates a new ReplayGateway which replays the dispatches recorded in the given io.Reader through the EventHandlerFunc once opened.
+// Use handlers.DefaultGatewayEventHandlerFunc to replay dispatches into a bot.Client.
+func NewReplay(r io.Reader, eventHandlerFunc EventHandlerFunc, opts ...ReplayConfigOpt) ReplayGateway {
+	config := DefaultReplayConfig()
+	config.Apply(opts)
+
+	return &replayGatewayImpl{
+		config:           *config,
+		reader:           bufio.NewReader(r),
+		eventHandlerFunc: eventHandlerFunc,
+		closeChan:        make(chan struct{}),
+		done:             make(chan struct{}),
+		status:           StatusUnconnected,
+	}
+}
+
+type replayGatewayImpl struct {
+	config           ReplayConfig
+	reader           *bufio.Reader
+	eventHandlerFunc EventHandlerFunc
+
+	closeOnce sync.Once
+	closeChan chan struct{}
+	done      chan struct{}
+
+	mu                   sync.Mutex
+	status               Status
+	sessionID            *string
+	lastSequenceReceived *int
+	presence             *MessageDataPresenceUpdate
+	err                  error
+}
+
+func (g *replayGatewayImpl) ShardID() int {
+	return g.config.ShardID
+}
+
+func (g *replayGatewayImpl) ShardCount() int {
+	return g.config.ShardCount
+}
+
+func (g *replayGatewayImpl) SessionID() *string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.sessionID
+}
+
+func (g *replayGatewayImpl) LastSequenceReceived() *int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastSequenceReceived
+}
+
+func (g *replayGatewayImpl) Intents() Intents {
+	return IntentsNone
+}
+
+func (g *replayGatewayImpl) Open(_ context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.status != StatusUnconnected {
+		return discord.ErrGatewayAlreadyConnected
+	}
+	g.status = StatusReady
+	go g.replay()
+	return nil
+}
+
+func (g *replayGatewayImpl) Close(ctx context.Context) {
+	g.closeOnce.Do(func() {
+		close(g.closeChan)
+	})
+
+	g.mu.Lock()
+	opened := g.status != StatusUnconnected
+	g.mu.Unlock()
+	if !opened {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-g.done:
+	}
+}
+
+func (g *replayGatewayImpl) CloseWithCode(ctx context.Context, _ int, _ string) {
+	g.Close(ctx)
+}
+
+func (g *replayGatewayImpl) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status
+}
+
+func (g *replayGatewayImpl) Send(_ context.Context, op Opcode, data MessageData) error {
+	if presence, ok := data.(MessageDataPresenceUpdate); ok && op == OpcodePresenceUpdate {
+		g.mu.Lock()
+		g.presence = &presence
+		g.mu.Unlock()
+	}
+	g.config.Logger.Debugf("replay gateway discarded command with opcode %d", op)
+	return nil
+}
+
+func (g *replayGatewayImpl) Latency() time.Duration {
+	return 0
+}
+
+func (g *replayGatewayImpl) MissedHeartbeatAcks() int {
+	return 0
+}
+
+func (g *replayGatewayImpl) QueueDepth(_ SendLane) int {
+	return 0
+}
+
+func (g *replayGatewayImpl) Presence() *MessageDataPresenceUpdate {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.presence
+}
+
+func (g *replayGatewayImpl) Done() <-chan struct{} {
+	return g.done
+}
+
+func (g *replayGatewayImpl) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+func (g *replayGatewayImpl) replay() {
+	defer func() {
+		g.mu.Lock()
+		g.status = StatusDisconnected
+		g.mu.Unlock()
+		close(g.done)
+	}()
+
+	var (
+		lastTimestamp time.Time
+		timer         *time.Timer
+	)
+	for {
+		line, readErr := g.reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var dispatch RecordedDispatch
+			if err := json.Unmarshal(line, &dispatch); err != nil {
+				g.setErr(fmt.Errorf("failed to decode recorded dispatch: %w", err))
+				return
+			}
+
+			if g.config.ShardCount <= 1 || dispatch.ShardID == g.config.ShardID {
+				// wait the recorded delay since the previous dispatch divided by the speed
+				if g.config.Speed > 0 && !lastTimestamp.IsZero() {
+					if delay := time.Duration(float64(dispatch.Timestamp.Sub(lastTimestamp)) / g.config.Speed); delay > 0 {
+						if timer == nil {
+							timer = time.NewTimer(delay)
+						} else {
+							timer.Reset(delay)
+						}
+						select {
+						case <-g.closeChan:
+							timer.Stop()
+							return
+						case <-timer.C:
+						}
+					}
+				}
+				lastTimestamp = dispatch.Timestamp
+
+				select {
+				case <-g.closeChan:
+					return
+				default:
+				}
+				g.dispatch(dispatch)
+			}
+		}
+
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				g.setErr(fmt.Errorf("failed to read recorded dispatch: %w", readErr))
+			}
+			return
+		}
+	}
+}
+
+func (g *replayGatewayImpl) dispatch(dispatch RecordedDispatch) {
+	g.mu.Lock()
+	g.lastSequenceReceived = &dispatch.Sequence
+	g.mu.Unlock()
+
+	decode := g.config.EventFilter == nil || dispatch.EventType == EventTypeReady || g.config.EventFilter(dispatch.EventType)
+
+	var eventData EventData
+	if decode {
+		var err error
+		if eventData, err = UnmarshalEventData(dispatch.Data, dispatch.EventType); err != nil {
+			g.config.Logger.Errorf("error while decoding recorded %s event. error: %s", dispatch.EventType, err)
+			return
+		}
+		if readyEvent, ok := eventData.(EventReady); ok {
+			g.mu.Lock()
+			g.sessionID = &readyEvent.SessionID
+			g.mu.Unlock()
+		}
+		if _, ok := eventData.(EventUnknown); ok {
+			g.config.Logger.Debugf("unknown recorded event: %s", dispatch.EventType)
+			return
+		}
+	}
+
+	if g.config.EnableRawEvents {
+		g.eventHandlerFunc(EventTypeRaw, dispatch.Sequence, dispatch.ShardID, EventRaw{
+			EventType: dispatch.EventType,
+			Payload:   bytes.NewReader(dispatch.Data),
+		})
+	}
+	if decode {
+		g.eventHandlerFunc(dispatch.EventType, dispatch.Sequence, dispatch.ShardID, eventData)
+	}
+}
+
+func (g *replayGatewayImpl) setErr(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.err = err
+}
@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"github.com/disgoorg/log"
+)
+
+// DefaultReplayConfig returns a ReplayConfig with sensible defaults.
+func DefaultReplayConfig() *ReplayConfig {
+	return &ReplayConfig{
+		Logger:     log.Default(),
+		Speed:      1,
+		ShardID:    0,
+		ShardCount: 1,
+	}
+}
+
+// ReplayConfig lets you configure your replay Gateway instance.
+type ReplayConfig struct {
+	// Logger is the logger of the replay Gateway. Defaults to log.Default().
+	Logger log.Logger
+	// Speed is the factor the recorded delays between dispatches are divided by.
+	// 1 replays in real time, 10 replays ten times as fast and 0 replays as fast as possible. Defaults to 1.
+	Speed float64
+	// ShardID is the shard ID of the replay Gateway. Defaults to 0.
+	ShardID int
+	// ShardCount is the shard count of the replay Gateway. If it is greater than 1, only dispatches recorded by ShardID are replayed.
+	// Otherwise, all dispatches are replayed with the shard ID they were recorded with. Defaults to 1.
+	ShardCount int
+	// EventFilter decides which dispatches are decoded and passed to the EventHandlerFunc, like Config.EventFilter. Defaults to nil (decode all dispatches).
+	EventFilter EventFilterFunc
+	// EnableRawEvents is whether the replay Gateway should emit EventRaw. Defaults to false.
+	EnableRawEvents bool
+}
+
+// ReplayConfigOpt is a type alias for a function that takes a ReplayConfig and is used to configure your replay Gateway.
+type ReplayConfigOpt func(config *ReplayConfig)
+
+// Apply applies the given ReplayConfigOpt(s) to the ReplayConfig
+func (c *ReplayConfig) Apply(opts []ReplayConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithReplayLogger sets the Logger for the replay Gateway.
+func WithReplayLogger(logger log.Logger) ReplayConfigOpt {
+	return func(config *ReplayConfig) {
+		config.Logger = logger
+	}
+}
+
+// WithReplaySpeed sets the speed factor of the replay. 0 replays as fast as possible.
+func WithReplaySpeed(speed float64) ReplayConfigOpt {
+	return func(config *ReplayConfig) {
+		config.Speed = speed
+	}
+}
+
+// WithReplayShard sets the shard ID & shard count of the replay Gateway.
+func WithReplayShard(shardID int, shardCount int) ReplayConfigOpt {
+	return func(config *ReplayConfig) {
+		config.ShardID = shardID
+		config.ShardCount = shardCount
+	}
+}
+
+// WithReplayEventFilter sets the EventFilterFunc which decides which dispatches are decoded.
+func WithReplayEventFilter(eventFilter EventFilterFunc) ReplayConfigOpt {
+	return func(config *ReplayConfig) {
+		config.EventFilter = eventFilter
+	}
+}
+
+// WithReplayEnableRawEvents enables/disables the EventTypeRaw.
+func WithReplayEnableRawEvents(enableRawEvents bool) ReplayConfigOpt {
+	return func(config *ReplayConfig) {
+		config.EnableRawEvents = enableRawEvents
+	}
+}
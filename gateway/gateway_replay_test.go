@@ -0,0 +1,31 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	assert.NoError(t, recorder.Record(0, 1, EventTypeReady, []byte(`{"v":10,"session_id":"abc"}`)))
+	assert.NoError(t, recorder.Record(1, 1, EventTypeReady, []byte(`{"v":10,"session_id":"def"}`)))
+	assert.NoError(t, recorder.Record(0, 2, EventTypeMessageDelete, []byte(`{"id":"1","channel_id":"2"}`)))
+
+	var received []EventType
+	replay := NewReplay(&buf, func(eventType EventType, sequenceNumber int, shardID int, event EventData) {
+		assert.Equal(t, 0, shardID)
+		received = append(received, eventType)
+	}, WithReplaySpeed(0), WithReplayShard(0, 2))
+
+	assert.NoError(t, replay.Open(context.Background()))
+	<-replay.Done()
+
+	assert.NoError(t, replay.Err())
+	assert.Equal(t, []EventType{EventTypeReady, EventTypeMessageDelete}, received)
+	assert.Equal(t, "abc", *replay.SessionID())
+	assert.Equal(t, 2, *replay.LastSequenceReceived())
+}
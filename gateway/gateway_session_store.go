@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+)
+
+// Session holds the data required to resume a Gateway session.
+type Session struct {
+	ID         string  `json:"id"`
+	ResumeURL  *string `json:"resume_url"`
+	Sequence   int     `json:"sequence"`
+	ShardCount int     `json:"shard_count"`
+}
+
+// SessionStore persists Session(s) so a Gateway can resume its session after a process restart instead of identifying again.
+// A Gateway only resumes a stored Session if it was created with the same shard count.
+// Sessions are invalidated by Discord when the Gateway is closed with websocket.CloseNormalClosure or websocket.CloseGoingAway,
+// use Gateway.CloseWithCode with another code like websocket.CloseServiceRestart, sharding.ShardManager.CloseResumable or bot.Client.CloseResumable
+// to keep them resumable.
+type SessionStore interface {
+	// Session returns the stored Session for the given shardID or nil if there is none.
+	Session(shardID int) (*Session, error)
+
+	// SetSession stores the Session for the given shardID.
+	// This is called for every received sequence, so implementations should be cheap.
+	SetSession(shardID int, session Session) error
+
+	// DeleteSession deletes the stored Session for the given shardID.
+	DeleteSession(shardID int) error
+
+	// Flush persists all pending changes. This is called when a Gateway is closed with a resumable close code.
+	Flush() error
+}
+
+var _ SessionStore = (*fileSessionStore)(nil)
+
+// NewFileSessionStore returns a SessionStore which persists all Session(s) as JSON in the given file.
+// New sessions and deletions are written immediately, sequence updates at most once per flushInterval.
+func NewFileSessionStore(path string, flushInterval time.Duration) (SessionStore, error) {
+	store := &fileSessionStore{
+		path:          path,
+		flushInterval: flushInterval,
+		sessions:      map[int]Session{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &store.sessions); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+type fileSessionStore struct {
+	path          string
+	flushInterval time.Duration
+
+	mu        sync.Mutex
+	sessions  map[int]Session
+	dirty     bool
+	lastFlush time.Time
+}
+
+func (s *fileSessionStore) Session(shardID int) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[shardID]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (s *fileSessionStore) SetSession(shardID int, session Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	oldSession, ok := s.sessions[shardID]
+	s.sessions[shardID] = session
+	s.dirty = true
+
+	if ok && oldSession.ID == session.ID && time.Since(s.lastFlush) < s.flushInterval {
+		return nil
+	}
+	return s.flush()
+}
+
+func (s *fileSessionStore) DeleteSession(shardID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[shardID]; !ok {
+		return nil
+	}
+	delete(s.sessions, shardID)
+	s.dirty = true
+	return s.flush()
+}
+
+func (s *fileSessionStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	return s.flush()
+}
+
+func (s *fileSessionStore) flush() error {
+	data, err := json.Marshal(s.sessions)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so we never leave a partially written file behind
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+
+	s.dirty = false
+	s.lastFlush = time.Now()
+	return nil
+}
@@ -0,0 +1,20 @@
+package gateway
+
+import (
+	"github.com/disgoorg/json"
+)
+
+// Dispatch is a raw dispatch received by a Gateway.
+type Dispatch struct {
+	ShardID   int             `json:"shard_id"`
+	Sequence  int             `json:"s"`
+	EventType EventType       `json:"t"`
+	Data      json.RawMessage `json:"d"`
+}
+
+// Sink receives every raw Dispatch of a Gateway, for example to publish it to a message bus for worker processes.
+// A Sink has to be safe for concurrent use if it is shared by multiple shards.
+type Sink interface {
+	// Publish publishes the Dispatch. It is called from the read loop of the Gateway, so it should not block for long.
+	Publish(dispatch Dispatch) error
+}
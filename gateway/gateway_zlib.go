@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// zlibSuffix is the Z_SYNC_FLUSH suffix Discord appends to the last frame of each zlib-stream message.
+var zlibSuffix = []byte{0x00, 0x00, 0xff, 0xff}
+
+// zlibWindowSize is the maximum distance deflate back-references can reach into previously decompressed data.
+const zlibWindowSize = 32 * 1024
+
+func newZlibStreamDecompressor() *zlibStreamDecompressor {
+	return &zlibStreamDecompressor{
+		window: make([]byte, 0, zlibWindowSize),
+	}
+}
+
+// zlibStreamDecompressor inflates a zlib-stream gateway connection.
+// Discord shares one deflate context across the whole connection and flushes it with Z_SYNC_FLUSH after every message.
+// As compress/flate can't continue reading after it ran out of input, the reader is reset for every message
+// with the last 32KiB of decompressed data as dictionary, which restores the shared inflate context.
+type zlibStreamDecompressor struct {
+	in         bytes.Buffer
+	src        bytes.Reader
+	out        bytes.Buffer
+	window     []byte
+	reader     io.ReadCloser
+	headerRead bool
+}
+
+// decompress buffers the given frame and returns the decompressed message once a frame ends with the zlibSuffix.
+// The returned data is only valid until the next call to decompress.
+func (d *zlibStreamDecompressor) decompress(data []byte) ([]byte, bool, error) {
+	d.in.Write(data)
+	if !bytes.HasSuffix(d.in.Bytes(), zlibSuffix) {
+		return nil, false, nil
+	}
+	defer d.in.Reset()
+
+	in := d.in.Bytes()
+	if !d.headerRead {
+		if len(in) < 2 {
+			return nil, false, errors.New("zlib-stream header too short")
+		}
+		if in[0]&0x0f != 8 || (uint16(in[0])<<8|uint16(in[1]))%31 != 0 || in[1]&0x20 != 0 {
+			return nil, false, fmt.Errorf("invalid zlib-stream header: %x", in[:2])
+		}
+		in = in[2:]
+		d.headerRead = true
+	}
+
+	d.src.Reset(in)
+	if d.reader == nil {
+		d.reader = flate.NewReaderDict(&d.src, d.window)
+	} else if err := d.reader.(flate.Resetter).Reset(&d.src, d.window); err != nil {
+		return nil, false, err
+	}
+
+	d.out.Reset()
+	// the stream never ends, so reading past the sync flush always returns io.ErrUnexpectedEOF
+	if _, err := d.out.ReadFrom(d.reader); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, false, fmt.Errorf("failed to inflate zlib-stream: %w", err)
+	}
+
+	out := d.out.Bytes()
+	if len(out) >= zlibWindowSize {
+		d.window = append(d.window[:0], out[len(out)-zlibWindowSize:]...)
+	} else {
+		if overflow := len(d.window) + len(out) - zlibWindowSize; overflow > 0 {
+			d.window = append(d.window[:0], d.window[overflow:]...)
+		}
+		d.window = append(d.window, out...)
+	}
+	return out, true, nil
+}
@@ -0,0 +1,43 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/zlib"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZlibStreamDecompressor(t *testing.T) {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+
+	messages := []string{
+		`{"op":10,"d":{"heartbeat_interval":41250}}`,
+		`{"op":11}`,
+		`{"op":0,"s":1,"t":"READY","d":{"v":10,"session_id":"` + strings.Repeat("a", 40000) + `"}}`,
+		`{"op":0,"s":2,"t":"RESUMED","d":{"session_id":"` + strings.Repeat("a", 40000) + `"}}`,
+	}
+
+	d := newZlibStreamDecompressor()
+	for _, message := range messages {
+		compressed.Reset()
+		_, _ = w.Write([]byte(message))
+		assert.NoError(t, w.Flush())
+
+		// split every message into two frames to make sure partial frames are buffered
+		frame := compressed.Bytes()
+		half := len(frame) / 2
+
+		data, complete, err := d.decompress(frame[:half])
+		assert.NoError(t, err)
+		assert.False(t, complete)
+		assert.Nil(t, data)
+
+		data, complete, err = d.decompress(frame[half:])
+		assert.NoError(t, err)
+		assert.True(t, complete)
+		assert.Equal(t, message, string(data))
+	}
+}
@@ -0,0 +1,335 @@
+package gatewaytest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+	"github.com/gorilla/websocket"
+
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/disgo/gateway/etf"
+)
+
+// ErrConnClosed is returned by Conn.Next & Conn.Expect when the connection was closed before a matching message was received.
+var ErrConnClosed = errors.New("gatewaytest: connection closed")
+
+func newConn(server *Server, ws *websocket.Conn, encoding gateway.Encoding) *Conn {
+	return &Conn{
+		server:        server,
+		ws:            ws,
+		encoding:      encoding,
+		ackHeartbeats: server.config.AckHeartbeats,
+		received:      make(chan struct{}),
+		closed:        make(chan struct{}),
+	}
+}
+
+// Conn is a single gateway connection accepted by the Server.
+// It can be used to script the server side of the connection and to assert on the messages the client sent.
+type Conn struct {
+	server   *Server
+	ws       *websocket.Conn
+	encoding gateway.Encoding
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	ackHeartbeats bool
+	session       *session
+	messages      []gateway.Message
+	received      chan struct{}
+	next          int
+	closing       bool
+	closeErr      error
+	closed        chan struct{}
+}
+
+// Next waits for the next message sent by the client which was not returned by Next or Expect yet.
+func (c *Conn) Next(ctx context.Context) (gateway.Message, error) {
+	for {
+		c.mu.Lock()
+		if c.next < len(c.messages) {
+			message := c.messages[c.next]
+			c.next++
+			c.mu.Unlock()
+			return message, nil
+		}
+		received := c.received
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return gateway.Message{}, ctx.Err()
+		case <-c.closed:
+			// drain messages received before the connection was closed
+			c.mu.Lock()
+			remaining := c.next < len(c.messages)
+			c.mu.Unlock()
+			if !remaining {
+				return gateway.Message{}, ErrConnClosed
+			}
+		case <-received:
+		}
+	}
+}
+
+// Expect waits for the next message with the given gateway.Opcode sent by the client. Messages with other gateway.Opcode(s) are skipped.
+func (c *Conn) Expect(ctx context.Context, op gateway.Opcode) (gateway.Message, error) {
+	for {
+		message, err := c.Next(ctx)
+		if err != nil {
+			return gateway.Message{}, err
+		}
+		if message.Op == op {
+			return message, nil
+		}
+	}
+}
+
+// Received returns all messages the client sent on this connection so far.
+func (c *Conn) Received() []gateway.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]gateway.Message(nil), c.messages...)
+}
+
+// SessionID returns the ID of the session the client identified or resumed with, or "" if it did neither yet.
+func (c *Conn) SessionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.session == nil {
+		return ""
+	}
+	return c.session.id
+}
+
+// SetAckHeartbeats sets whether heartbeats on this connection are acknowledged. Disable it to simulate a zombied connection.
+func (c *Conn) SetAckHeartbeats(ackHeartbeats bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ackHeartbeats = ackHeartbeats
+}
+
+// Dispatch sends a dispatch with the given gateway.EventType and data to the client.
+// The client needs to be identified or resumed. The dispatch is replayed when the session is resumed on another connection.
+// After Close, dispatches are only added to the session, simulating events the client missed while disconnected.
+func (c *Conn) Dispatch(eventType gateway.EventType, d any) error {
+	c.mu.Lock()
+	sess := c.session
+	closing := c.closing
+	c.mu.Unlock()
+	if sess == nil {
+		return errors.New("gatewaytest: dispatch before identify or resume")
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sequence := len(sess.dispatches) + 1
+	sess.dispatches = append(sess.dispatches, dispatch{
+		sequence:  sequence,
+		eventType: eventType,
+		data:      d,
+	})
+	if closing {
+		return nil
+	}
+	return c.send(gateway.OpcodeDispatch, sequence, eventType, d)
+}
+
+// Send sends a message with the given gateway.Opcode and data to the client.
+func (c *Conn) Send(op gateway.Opcode, d any) error {
+	return c.send(op, 0, "", d)
+}
+
+// Reconnect asks the client to reconnect and resume.
+func (c *Conn) Reconnect() error {
+	return c.Send(gateway.OpcodeReconnect, nil)
+}
+
+// InvalidSession tells the client that its session is invalid. If resumable is false, the session is deleted.
+func (c *Conn) InvalidSession(resumable bool) error {
+	if !resumable {
+		c.mu.Lock()
+		if c.session != nil {
+			c.server.deleteSession(c.session.id)
+			c.session = nil
+		}
+		c.mu.Unlock()
+	}
+	return c.Send(gateway.OpcodeInvalidSession, resumable)
+}
+
+// Close sends a close frame with the given close code & text and closes the connection.
+func (c *Conn) Close(code int, text string) error {
+	c.mu.Lock()
+	c.closing = true
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err := c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+	if closeErr := c.ws.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Closed returns a channel which is closed when the connection is closed.
+func (c *Conn) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// CloseErr returns the error which closed the connection, or nil if it is still open.
+// If the client closed the connection, this is a *websocket.CloseError containing the close code.
+func (c *Conn) CloseErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeErr
+}
+
+type payload struct {
+	Op gateway.Opcode    `json:"op"`
+	S  int               `json:"s,omitempty"`
+	T  gateway.EventType `json:"t,omitempty"`
+	D  any               `json:"d"`
+}
+
+func (c *Conn) send(op gateway.Opcode, s int, t gateway.EventType, d any) error {
+	data, err := json.Marshal(payload{Op: op, S: s, T: t, D: d})
+	if err != nil {
+		return err
+	}
+
+	messageType := websocket.TextMessage
+	if c.encoding == gateway.EncodingETF {
+		if data, err = etf.FromJSON(data); err != nil {
+			return err
+		}
+		messageType = websocket.BinaryMessage
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteMessage(messageType, data)
+}
+
+func (c *Conn) listen() {
+	defer func() {
+		_ = c.ws.Close()
+		close(c.closed)
+	}()
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.mu.Lock()
+			c.closeErr = err
+			c.mu.Unlock()
+			return
+		}
+
+		if c.encoding == gateway.EncodingETF {
+			if data, err = etf.ToJSON(data); err != nil {
+				c.server.config.Logger.Error("gatewaytest: failed to transcode etf: ", err)
+				continue
+			}
+		}
+
+		var message gateway.Message
+		if err = json.Unmarshal(data, &message); err != nil {
+			c.server.config.Logger.Error("gatewaytest: failed to decode message: ", err)
+			_ = c.closeWith(gateway.CloseEventCodeDecodeError)
+			return
+		}
+
+		c.mu.Lock()
+		c.messages = append(c.messages, message)
+		close(c.received)
+		c.received = make(chan struct{})
+		c.mu.Unlock()
+
+		if err = c.handle(message); err != nil {
+			c.server.config.Logger.Error("gatewaytest: failed to handle message: ", err)
+		}
+	}
+}
+
+func (c *Conn) handle(message gateway.Message) error {
+	c.mu.Lock()
+	ackHeartbeats := c.ackHeartbeats
+	authenticated := c.session != nil
+	c.mu.Unlock()
+
+	switch d := message.D.(type) {
+	case gateway.MessageDataHeartbeat:
+		if !ackHeartbeats {
+			return nil
+		}
+		return c.Send(gateway.OpcodeHeartbeatACK, nil)
+
+	case gateway.MessageDataIdentify:
+		if authenticated {
+			return c.closeWith(gateway.CloseEventCodeAlreadyAuthenticated)
+		}
+		if !c.validToken(d.Token) {
+			return c.closeWith(gateway.CloseEventCodeAuthenticationFailed)
+		}
+		shard := [2]int{0, 1}
+		if d.Shard != nil {
+			shard = *d.Shard
+		}
+		sess := c.server.newSession(shard)
+		c.mu.Lock()
+		c.session = sess
+		c.mu.Unlock()
+
+		return c.Dispatch(gateway.EventTypeReady, gateway.EventReady{
+			Version:          gateway.Version,
+			User:             c.server.config.User,
+			Guilds:           c.server.config.Guilds,
+			SessionID:        sess.id,
+			ResumeGatewayURL: c.server.URL(),
+			Shard:            shard,
+		})
+
+	case gateway.MessageDataResume:
+		if authenticated {
+			return c.closeWith(gateway.CloseEventCodeAlreadyAuthenticated)
+		}
+		if !c.validToken(d.Token) {
+			return c.closeWith(gateway.CloseEventCodeAuthenticationFailed)
+		}
+		sess := c.server.session(d.SessionID)
+		if sess == nil || d.Seq > sess.sequence() {
+			return c.Send(gateway.OpcodeInvalidSession, false)
+		}
+		c.mu.Lock()
+		c.session = sess
+		c.mu.Unlock()
+
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		for _, missed := range sess.dispatches[d.Seq:] {
+			if err := c.send(gateway.OpcodeDispatch, missed.sequence, missed.eventType, missed.data); err != nil {
+				return err
+			}
+		}
+		return c.send(gateway.OpcodeDispatch, len(sess.dispatches), gateway.EventTypeResumed, struct{}{})
+
+	default:
+		if !authenticated {
+			return c.closeWith(gateway.CloseEventCodeNotAuthenticated)
+		}
+		return nil
+	}
+}
+
+func (c *Conn) validToken(token string) bool {
+	return c.server.config.Token == "" || c.server.config.Token == token
+}
+
+func (c *Conn) closeWith(closeEventCode gateway.CloseEventCode) error {
+	return c.Close(closeEventCode.Code, closeEventCode.Description)
+}
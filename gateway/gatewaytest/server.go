@@ -0,0 +1,178 @@
+// Package gatewaytest provides an in-process websocket server speaking the Discord gateway protocol for integration tests.
+//
+// The Server answers heartbeats, identifies & resumes like Discord does and keeps the dispatches of each session, so resuming replays missed dispatches.
+// Everything else is scripted by the test through the Conn of each connection:
+// dispatching events, sending reconnect or invalid session opcodes, closing with specific close codes and asserting on the messages the client sent.
+//
+//	server := gatewaytest.NewServer(gatewaytest.WithHeartbeatInterval(time.Second))
+//	defer server.Close()
+//
+//	g := gateway.New(token, eventHandlerFunc, nil, gateway.WithURL(server.URL()))
+//	_ = g.Open(ctx)
+//
+//	conn, _ := server.Accept(ctx)
+//	identify, _ := conn.Expect(ctx, gateway.OpcodeIdentify)
+//	_ = conn.Dispatch(gateway.EventTypeGuildCreate, guild)
+//	_ = conn.Close(4000, "unknown error")
+package gatewaytest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// NewServer starts a new Server with the given ConfigOpt(s). Close it after use.
+func NewServer(opts ...ConfigOpt) *Server {
+	config := DefaultConfig()
+	config.Apply(opts)
+
+	s := &Server{
+		config:   *config,
+		sessions: map[string]*session{},
+		accepted: make(chan struct{}),
+	}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Server is an in-process websocket server speaking the Discord gateway protocol.
+type Server struct {
+	config     Config
+	httpServer *httptest.Server
+	upgrader   websocket.Upgrader
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	conns    []*Conn
+	accepted chan struct{}
+	next     int
+}
+
+// URL returns the websocket URL of the Server. Use it as gateway.Config.URL.
+func (s *Server) URL() string {
+	return "ws" + strings.TrimPrefix(s.httpServer.URL, "http")
+}
+
+// GatewayCreateFunc returns a gateway.CreateFunc which creates gateway.Gateway(s) connected to the Server.
+// Use it as sharding.Config.GatewayCreateFunc.
+func (s *Server) GatewayCreateFunc() gateway.CreateFunc {
+	return func(token string, eventHandlerFunc gateway.EventHandlerFunc, closeHandlerFunc gateway.CloseHandlerFunc, opts ...gateway.ConfigOpt) gateway.Gateway {
+		return gateway.New(token, eventHandlerFunc, closeHandlerFunc, append(opts, gateway.WithURL(s.URL()))...)
+	}
+}
+
+// Accept waits for the next connection which was not returned by Accept yet.
+func (s *Server) Accept(ctx context.Context) (*Conn, error) {
+	for {
+		s.mu.Lock()
+		if s.next < len(s.conns) {
+			conn := s.conns[s.next]
+			s.next++
+			s.mu.Unlock()
+			return conn, nil
+		}
+		accepted := s.accepted
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-accepted:
+		}
+	}
+}
+
+// Conns returns all connections the Server accepted so far.
+func (s *Server) Conns() []*Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Conn(nil), s.conns...)
+}
+
+// Close closes all connections and shuts down the Server.
+func (s *Server) Close() {
+	for _, conn := range s.Conns() {
+		_ = conn.ws.Close()
+	}
+	s.httpServer.Close()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.config.Logger.Error("gatewaytest: failed to upgrade connection: ", err)
+		return
+	}
+
+	conn := newConn(s, ws, gateway.Encoding(r.URL.Query().Get("encoding")))
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	close(s.accepted)
+	s.accepted = make(chan struct{})
+	s.mu.Unlock()
+
+	if err = conn.send(gateway.OpcodeHello, 0, "", gateway.MessageDataHello{
+		HeartbeatInterval: int(s.config.HeartbeatInterval.Milliseconds()),
+	}); err != nil {
+		s.config.Logger.Error("gatewaytest: failed to send hello: ", err)
+		_ = ws.Close()
+		return
+	}
+	conn.listen()
+}
+
+func (s *Server) newSession(shard [2]int) *session {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess := &session{
+		id:    hex.EncodeToString(b),
+		shard: shard,
+	}
+	s.sessions[sess.id] = sess
+	return sess
+}
+
+func (s *Server) session(sessionID string) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[sessionID]
+}
+
+func (s *Server) deleteSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+}
+
+// session holds all dispatches sent in a session, so they can be replayed on resume.
+type session struct {
+	id    string
+	shard [2]int
+
+	mu         sync.Mutex
+	dispatches []dispatch
+}
+
+type dispatch struct {
+	sequence  int
+	eventType gateway.EventType
+	data      any
+}
+
+func (s *session) sequence() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.dispatches)
+}
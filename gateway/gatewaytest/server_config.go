@@ -0,0 +1,88 @@
+package gatewaytest
+
+import (
+	"time"
+
+	"github.com/disgoorg/log"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() *Config {
+	return &Config{
+		Logger:            log.Default(),
+		HeartbeatInterval: 41250 * time.Millisecond,
+		AckHeartbeats:     true,
+	}
+}
+
+// Config lets you configure your Server instance.
+type Config struct {
+	// Logger is the logger of the Server. Defaults to log.Default().
+	Logger log.Logger
+	// HeartbeatInterval is the heartbeat interval sent in the Hello payload. Defaults to 41.25 seconds.
+	HeartbeatInterval time.Duration
+	// AckHeartbeats is whether new connections acknowledge heartbeats. Defaults to true.
+	AckHeartbeats bool
+	// Token is the token clients have to identify & resume with. If empty, all tokens are accepted. Defaults to "".
+	Token string
+	// User is the user sent in the Ready payload.
+	User discord.OAuth2User
+	// Guilds are the guilds sent in the Ready payload.
+	Guilds []discord.UnavailableGuild
+}
+
+// ConfigOpt is a type alias for a function that takes a Config and is used to configure your Server.
+type ConfigOpt func(config *Config)
+
+// Apply applies the given ConfigOpt(s) to the Config
+func (c *Config) Apply(opts []ConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithLogger sets the Logger for the Server.
+func WithLogger(logger log.Logger) ConfigOpt {
+	return func(config *Config) {
+		config.Logger = logger
+	}
+}
+
+// WithHeartbeatInterval sets the heartbeat interval sent in the Hello payload.
+func WithHeartbeatInterval(heartbeatInterval time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.HeartbeatInterval = heartbeatInterval
+	}
+}
+
+// WithAckHeartbeats sets whether new connections acknowledge heartbeats.
+// Use Conn.SetAckHeartbeats to change it for a single connection.
+func WithAckHeartbeats(ackHeartbeats bool) ConfigOpt {
+	return func(config *Config) {
+		config.AckHeartbeats = ackHeartbeats
+	}
+}
+
+// WithToken sets the token clients have to identify & resume with.
+// Connections with another token are closed with gateway.CloseEventCodeAuthenticationFailed.
+func WithToken(token string) ConfigOpt {
+	return func(config *Config) {
+		config.Token = token
+	}
+}
+
+// WithUser sets the user sent in the Ready payload.
+func WithUser(user discord.OAuth2User) ConfigOpt {
+	return func(config *Config) {
+		config.User = user
+	}
+}
+
+// WithGuilds sets the guilds sent in the Ready payload.
+func WithGuilds(guilds ...discord.UnavailableGuild) ConfigOpt {
+	return func(config *Config) {
+		config.Guilds = guilds
+	}
+}
@@ -0,0 +1,261 @@
+package gatewaytest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/json"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+func TestServerResume(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(WithToken("token"))
+	defer server.Close()
+
+	events := make(chan gateway.EventType, 10)
+	g := gateway.New("token", func(eventType gateway.EventType, _ int, _ int, _ gateway.EventData) {
+		if eventType != gateway.EventTypeRaw && eventType != gateway.EventTypeStatusChange && eventType != gateway.EventTypeHeartbeatAck {
+			events <- eventType
+		}
+	}, nil,
+		gateway.WithURL(server.URL()),
+		gateway.WithReconnectPolicy(&gateway.BackoffReconnectPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}),
+	)
+	defer g.Close(context.Background())
+
+	require.NoError(t, g.Open(ctx))
+
+	conn, err := server.Accept(ctx)
+	require.NoError(t, err)
+	identify, err := conn.Expect(ctx, gateway.OpcodeIdentify)
+	require.NoError(t, err)
+	assert.Equal(t, "token", identify.D.(gateway.MessageDataIdentify).Token)
+	assert.Equal(t, gateway.EventTypeReady, nextEvent(ctx, events))
+
+	// close with a resumable close code and dispatch an event the client misses
+	require.NoError(t, conn.Close(gateway.CloseEventCodeUnknownError.Code, gateway.CloseEventCodeUnknownError.Description))
+	sessionID := conn.SessionID()
+	require.NoError(t, conn.Dispatch(gateway.EventTypeMessageDelete, json.RawMessage(`{"id":"1","channel_id":"2"}`)))
+
+	conn, err = server.Accept(ctx)
+	require.NoError(t, err)
+	resume, err := conn.Expect(ctx, gateway.OpcodeResume)
+	require.NoError(t, err)
+	assert.Equal(t, sessionID, resume.D.(gateway.MessageDataResume).SessionID)
+	assert.Equal(t, gateway.EventTypeMessageDelete, nextEvent(ctx, events))
+	assert.Equal(t, gateway.EventTypeResumed, nextEvent(ctx, events))
+
+	// a non-resumable invalid session makes the client close the connection and identify again on a new one
+	require.NoError(t, conn.InvalidSession(false))
+	select {
+	case <-conn.Closed():
+	case <-ctx.Done():
+		t.Fatal("client did not close the connection")
+	}
+	conn, err = server.Accept(ctx)
+	require.NoError(t, err)
+	_, err = conn.Expect(ctx, gateway.OpcodeIdentify)
+	require.NoError(t, err)
+	assert.Equal(t, gateway.EventTypeReady, nextEvent(ctx, events))
+	assert.NotEqual(t, sessionID, conn.SessionID())
+}
+
+func TestServerZombieConnection(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(WithHeartbeatInterval(50 * time.Millisecond))
+	defer server.Close()
+
+	events := make(chan gateway.EventType, 10)
+	causes := make(chan gateway.StatusChangeCause, 10)
+	g := gateway.New("token", func(eventType gateway.EventType, _ int, _ int, event gateway.EventData) {
+		if e, ok := event.(gateway.EventStatusChange); ok {
+			if e.NewStatus == gateway.StatusDisconnected {
+				causes <- e.Cause
+			}
+			return
+		}
+		if eventType != gateway.EventTypeRaw && eventType != gateway.EventTypeHeartbeatAck {
+			events <- eventType
+		}
+	}, nil,
+		gateway.WithURL(server.URL()),
+		gateway.WithReconnectPolicy(&gateway.BackoffReconnectPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}),
+	)
+	defer g.Close(context.Background())
+
+	require.NoError(t, g.Open(ctx))
+	conn, err := server.Accept(ctx)
+	require.NoError(t, err)
+	_, err = conn.Expect(ctx, gateway.OpcodeIdentify)
+	require.NoError(t, err)
+	assert.Equal(t, gateway.EventTypeReady, nextEvent(ctx, events))
+	sessionID := conn.SessionID()
+
+	// without ACKs the client has to detect the zombied connection, reconnect and resume
+	conn.SetAckHeartbeats(false)
+	select {
+	case <-conn.Closed():
+	case <-ctx.Done():
+		t.Fatal("client did not close the zombied connection")
+	}
+	select {
+	case cause := <-causes:
+		assert.Equal(t, gateway.StatusChangeCauseHeartbeatTimeout, cause)
+	case <-ctx.Done():
+		t.Fatal("client did not report the heartbeat timeout")
+	}
+
+	conn, err = server.Accept(ctx)
+	require.NoError(t, err)
+	resume, err := conn.Expect(ctx, gateway.OpcodeResume)
+	require.NoError(t, err)
+	assert.Equal(t, sessionID, resume.D.(gateway.MessageDataResume).SessionID)
+	assert.Equal(t, gateway.EventTypeResumed, nextEvent(ctx, events))
+}
+
+func TestServerStatusChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer()
+	defer server.Close()
+
+	changes := make(chan gateway.StatusChange, 20)
+	g := gateway.New("token", func(eventType gateway.EventType, _ int, _ int, event gateway.EventData) {
+		if e, ok := event.(gateway.EventStatusChange); ok {
+			changes <- e.StatusChange
+		}
+	}, nil,
+		gateway.WithURL(server.URL()),
+		gateway.WithAutoReconnect(false),
+	)
+
+	require.NoError(t, g.Open(ctx))
+	conn, err := server.Accept(ctx)
+	require.NoError(t, err)
+
+	// changes are reported in order until the handshake is done
+	nextChange := func() gateway.StatusChange {
+		select {
+		case change := <-changes:
+			return change
+		case <-ctx.Done():
+			t.Fatal("status change was not reported")
+			return gateway.StatusChange{}
+		}
+	}
+	var change gateway.StatusChange
+	for change.NewStatus != gateway.StatusReady {
+		change = nextChange()
+		assert.Equal(t, gateway.StatusChangeCauseHandshake, change.Cause)
+	}
+
+	require.NoError(t, conn.Close(gateway.CloseEventCodeUnknownError.Code, gateway.CloseEventCodeUnknownError.Description))
+	change = nextChange()
+	assert.Equal(t, gateway.StatusReady, change.OldStatus)
+	assert.Equal(t, gateway.StatusDisconnected, change.NewStatus)
+	assert.Equal(t, gateway.StatusChangeCauseCloseCode, change.Cause)
+	assert.Equal(t, gateway.CloseEventCodeUnknownError.Code, change.CloseCode)
+	assert.Equal(t, gateway.StatusDisconnected, g.Status())
+}
+
+func TestServerEventFilter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer()
+	defer server.Close()
+
+	events := make(chan gateway.EventType, 10)
+	g := gateway.New("token", func(eventType gateway.EventType, _ int, _ int, event gateway.EventData) {
+		if eventType != gateway.EventTypeRaw && eventType != gateway.EventTypeStatusChange && eventType != gateway.EventTypeHeartbeatAck {
+			events <- eventType
+		}
+	}, nil,
+		gateway.WithURL(server.URL()),
+		gateway.WithEventFilter(func(eventType gateway.EventType) bool {
+			return eventType == gateway.EventTypeMessageDelete
+		}),
+	)
+	defer g.Close(context.Background())
+
+	require.NoError(t, g.Open(ctx))
+	conn, err := server.Accept(ctx)
+	require.NoError(t, err)
+	// READY is always passed on, as the Gateway needs it
+	assert.Equal(t, gateway.EventTypeReady, nextEvent(ctx, events))
+
+	// filtered dispatches are skipped, even if they could not be decoded
+	require.NoError(t, conn.Dispatch(gateway.EventTypeMessageCreate, json.RawMessage(`{"id":"invalid"}`)))
+	require.NoError(t, conn.Dispatch(gateway.EventTypeMessageDelete, json.RawMessage(`{"id":"1","channel_id":"2"}`)))
+	assert.Equal(t, gateway.EventTypeMessageDelete, nextEvent(ctx, events))
+	assert.Equal(t, 3, *g.LastSequenceReceived())
+}
+
+func TestServerFileSessionStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "sessions.json")
+
+	events := make(chan gateway.EventType, 10)
+	newGateway := func() gateway.Gateway {
+		// every Gateway reads the file again, like a restarted process
+		store, err := gateway.NewFileSessionStore(path, time.Minute)
+		require.NoError(t, err)
+		return gateway.New("token", func(eventType gateway.EventType, _ int, _ int, _ gateway.EventData) {
+			if eventType != gateway.EventTypeRaw && eventType != gateway.EventTypeStatusChange && eventType != gateway.EventTypeHeartbeatAck {
+				events <- eventType
+			}
+		}, nil,
+			gateway.WithURL(server.URL()),
+			gateway.WithSessionStore(store),
+		)
+	}
+
+	g := newGateway()
+	require.NoError(t, g.Open(ctx))
+	conn, err := server.Accept(ctx)
+	require.NoError(t, err)
+	_, err = conn.Expect(ctx, gateway.OpcodeIdentify)
+	require.NoError(t, err)
+	require.NoError(t, conn.Dispatch(gateway.EventTypeMessageDelete, json.RawMessage(`{"id":"1","channel_id":"2"}`)))
+	assert.Equal(t, gateway.EventTypeReady, nextEvent(ctx, events))
+	assert.Equal(t, gateway.EventTypeMessageDelete, nextEvent(ctx, events))
+	sessionID := conn.SessionID()
+
+	// a resumable close flushes the sequence, so the next Gateway resumes without replaying the received dispatches
+	g.CloseWithCode(ctx, websocket.CloseServiceRestart, "restarting")
+
+	g = newGateway()
+	defer g.Close(context.Background())
+	require.NoError(t, g.Open(ctx))
+	conn, err = server.Accept(ctx)
+	require.NoError(t, err)
+	resume, err := conn.Expect(ctx, gateway.OpcodeResume)
+	require.NoError(t, err)
+	assert.Equal(t, sessionID, resume.D.(gateway.MessageDataResume).SessionID)
+	assert.Equal(t, 2, resume.D.(gateway.MessageDataResume).Seq)
+}
+
+func nextEvent(ctx context.Context, events <-chan gateway.EventType) gateway.EventType {
+	select {
+	case <-ctx.Done():
+		return ""
+	case eventType := <-events:
+		return eventType
+	}
+}
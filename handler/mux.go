@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/disgoorg/disgo/bot"
@@ -29,6 +30,11 @@ type Mux struct {
 	notFoundHandler NotFoundHandler
 }
 
+// EventTypes returns the types of the events the Mux receives.
+func (r *Mux) EventTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf((*events.InteractionCreate)(nil))}
+}
+
 // OnEvent is called when a new event is received.
 func (r *Mux) OnEvent(event bot.Event) {
 	e, ok := event.(*events.InteractionCreate)
@@ -33,6 +33,10 @@ func GetGatewayHandlers() map[gateway.EventType]bot.GatewayEventHandler {
 var allEventHandlers = []bot.GatewayEventHandler{
 	bot.NewGatewayEventHandler(gateway.EventTypeRaw, gatewayHandlerRaw),
 	bot.NewGatewayEventHandler(gateway.EventTypeHeartbeatAck, gatewayHandlerHeartbeatAck),
+	bot.NewGatewayEventHandler(gateway.EventTypeStatusChange, gatewayHandlerStatusChange),
+	bot.NewGatewayEventHandler(gateway.EventTypeIdentifyBudgetLow, gatewayHandlerIdentifyBudgetLow),
+	bot.NewGatewayEventHandler(gateway.EventTypeShardReady, gatewayHandlerShardReady),
+	bot.NewGatewayEventHandler(gateway.EventTypeAllShardsReady, gatewayHandlerAllShardsReady),
 	bot.NewGatewayEventHandler(gateway.EventTypeReady, gatewayHandlerReady),
 	bot.NewGatewayEventHandler(gateway.EventTypeResumed, gatewayHandlerResumed),
 
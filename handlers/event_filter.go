@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"reflect"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// DefaultGatewayEventFilterFunc returns a gateway.EventFilterFunc which only lets the gateway.Gateway decode dispatches
+// which are needed by the enabled cache.Flags, the bot.Client itself or which are emitted as bot.Event(s) an EventListener listens to.
+func DefaultGatewayEventFilterFunc(client bot.Client) gateway.EventFilterFunc {
+	return func(eventType gateway.EventType) bool {
+		requirement, ok := gatewayEventRequirements[eventType]
+		if !ok || requirement.always {
+			return true
+		}
+		if client.Caches().CacheFlags()&requirement.cacheFlags != cache.FlagsNone {
+			return true
+		}
+		for _, e := range requirement.events {
+			if client.EventManager().ListensTo(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// gatewayEventRequirement describes why a dispatch is needed.
+type gatewayEventRequirement struct {
+	// always is true for dispatches which are needed by the bot.Client itself.
+	always bool
+	// cacheFlags are the cache.Flags of which at least one needs the dispatch.
+	cacheFlags cache.Flags
+	// events are the bot.Event(s) emitted for the dispatch.
+	events []reflect.Type
+}
+
+func eventTypes(events ...bot.Event) []reflect.Type {
+	types := make([]reflect.Type, len(events))
+	for i, event := range events {
+		types[i] = eventType(event)
+	}
+	return types
+}
+
+var gatewayEventRequirements = map[gateway.EventType]gatewayEventRequirement{
+	gateway.EventTypeReady:             {always: true},
+	gateway.EventTypeResumed:           {events: eventTypes((*events.Resumed)(nil))},
+	gateway.EventTypeGuildCreate:       {always: true},
+	gateway.EventTypeGuildUpdate:       {always: true},
+	gateway.EventTypeGuildDelete:       {always: true},
+	gateway.EventTypeUserUpdate:        {always: true},
+	gateway.EventTypeGuildMembersChunk: {always: true},
+	gateway.EventTypeVoiceStateUpdate:  {always: true},
+	gateway.EventTypeVoiceServerUpdate: {always: true},
+
+	gateway.EventTypeApplicationCommandPermissionsUpdate: {events: eventTypes((*events.GuildApplicationCommandPermissionsUpdate)(nil))},
+
+	gateway.EventTypeAutoModerationRuleCreate:      {events: eventTypes((*events.AutoModerationRuleCreate)(nil))},
+	gateway.EventTypeAutoModerationRuleUpdate:      {events: eventTypes((*events.AutoModerationRuleUpdate)(nil))},
+	gateway.EventTypeAutoModerationRuleDelete:      {events: eventTypes((*events.AutoModerationRuleDelete)(nil))},
+	gateway.EventTypeAutoModerationActionExecution: {events: eventTypes((*events.AutoModerationActionExecution)(nil))},
+
+	gateway.EventTypeChannelCreate:     {cacheFlags: cache.FlagChannels, events: eventTypes((*events.GuildChannelCreate)(nil))},
+	gateway.EventTypeChannelUpdate:     {cacheFlags: cache.FlagChannels | cache.FlagThreadMembers, events: eventTypes((*events.GuildChannelUpdate)(nil), (*events.ThreadHide)(nil))},
+	gateway.EventTypeChannelDelete:     {cacheFlags: cache.FlagChannels, events: eventTypes((*events.GuildChannelDelete)(nil))},
+	gateway.EventTypeChannelPinsUpdate: {cacheFlags: cache.FlagChannels, events: eventTypes((*events.DMChannelPinsUpdate)(nil), (*events.GuildChannelPinsUpdate)(nil))},
+
+	gateway.EventTypeThreadCreate:        {cacheFlags: cache.FlagChannels | cache.FlagThreadMembers, events: eventTypes((*events.ThreadCreate)(nil))},
+	gateway.EventTypeThreadUpdate:        {cacheFlags: cache.FlagChannels, events: eventTypes((*events.ThreadUpdate)(nil))},
+	gateway.EventTypeThreadDelete:        {cacheFlags: cache.FlagChannels | cache.FlagThreadMembers, events: eventTypes((*events.ThreadDelete)(nil))},
+	gateway.EventTypeThreadListSync:      {cacheFlags: cache.FlagChannels, events: eventTypes((*events.ThreadShow)(nil))},
+	gateway.EventTypeThreadMemberUpdate:  {cacheFlags: cache.FlagThreadMembers},
+	gateway.EventTypeThreadMembersUpdate: {cacheFlags: cache.FlagChannels | cache.FlagThreadMembers | cache.FlagMembers | cache.FlagPresences, events: eventTypes((*events.ThreadMemberAdd)(nil), (*events.ThreadMemberRemove)(nil))},
+
+	gateway.EventTypeGuildAuditLogEntryCreate: {events: eventTypes((*events.GuildAuditLogEntryCreate)(nil))},
+	gateway.EventTypeGuildBanAdd:              {events: eventTypes((*events.GuildBan)(nil))},
+	gateway.EventTypeGuildBanRemove:           {events: eventTypes((*events.GuildUnban)(nil))},
+	gateway.EventTypeGuildEmojisUpdate:        {cacheFlags: cache.FlagEmojis, events: eventTypes((*events.EmojisUpdate)(nil), (*events.EmojiCreate)(nil), (*events.EmojiUpdate)(nil), (*events.EmojiDelete)(nil))},
+	gateway.EventTypeGuildStickersUpdate:      {cacheFlags: cache.FlagStickers, events: eventTypes((*events.StickersUpdate)(nil), (*events.StickerCreate)(nil), (*events.StickerUpdate)(nil), (*events.StickerDelete)(nil))},
+	gateway.EventTypeGuildIntegrationsUpdate:  {events: eventTypes((*events.GuildIntegrationsUpdate)(nil))},
+
+	gateway.EventTypeGuildMemberAdd:    {cacheFlags: cache.FlagGuilds | cache.FlagMembers, events: eventTypes((*events.GuildMemberJoin)(nil))},
+	gateway.EventTypeGuildMemberUpdate: {cacheFlags: cache.FlagMembers, events: eventTypes((*events.GuildMemberUpdate)(nil))},
+	gateway.EventTypeGuildMemberRemove: {cacheFlags: cache.FlagGuilds | cache.FlagMembers, events: eventTypes((*events.GuildMemberLeave)(nil))},
+
+	gateway.EventTypeGuildRoleCreate: {cacheFlags: cache.FlagRoles, events: eventTypes((*events.RoleCreate)(nil))},
+	gateway.EventTypeGuildRoleUpdate: {cacheFlags: cache.FlagRoles, events: eventTypes((*events.RoleUpdate)(nil))},
+	gateway.EventTypeGuildRoleDelete: {cacheFlags: cache.FlagRoles, events: eventTypes((*events.RoleDelete)(nil))},
+
+	gateway.EventTypeGuildScheduledEventCreate:     {cacheFlags: cache.FlagGuildScheduledEvents, events: eventTypes((*events.GuildScheduledEventCreate)(nil))},
+	gateway.EventTypeGuildScheduledEventUpdate:     {cacheFlags: cache.FlagGuildScheduledEvents, events: eventTypes((*events.GuildScheduledEventUpdate)(nil))},
+	gateway.EventTypeGuildScheduledEventDelete:     {cacheFlags: cache.FlagGuildScheduledEvents, events: eventTypes((*events.GuildScheduledEventDelete)(nil))},
+	gateway.EventTypeGuildScheduledEventUserAdd:    {events: eventTypes((*events.GuildScheduledEventUserAdd)(nil))},
+	gateway.EventTypeGuildScheduledEventUserRemove: {events: eventTypes((*events.GuildScheduledEventUserRemove)(nil))},
+
+	gateway.EventTypeIntegrationCreate: {events: eventTypes((*events.IntegrationCreate)(nil))},
+	gateway.EventTypeIntegrationUpdate: {events: eventTypes((*events.IntegrationUpdate)(nil))},
+	gateway.EventTypeIntegrationDelete: {events: eventTypes((*events.IntegrationDelete)(nil))},
+
+	gateway.EventTypeInteractionCreate: {events: eventTypes((*events.InteractionCreate)(nil), (*events.ApplicationCommandInteractionCreate)(nil), (*events.ComponentInteractionCreate)(nil), (*events.AutocompleteInteractionCreate)(nil), (*events.ModalSubmitInteractionCreate)(nil))},
+
+	gateway.EventTypeInviteCreate: {events: eventTypes((*events.InviteCreate)(nil))},
+	gateway.EventTypeInviteDelete: {events: eventTypes((*events.InviteDelete)(nil))},
+
+	gateway.EventTypeMessageCreate:     {cacheFlags: cache.FlagMessages | cache.FlagChannels, events: eventTypes((*events.MessageCreate)(nil), (*events.DMMessageCreate)(nil), (*events.GuildMessageCreate)(nil))},
+	gateway.EventTypeMessageUpdate:     {cacheFlags: cache.FlagMessages, events: eventTypes((*events.MessageUpdate)(nil), (*events.DMMessageUpdate)(nil), (*events.GuildMessageUpdate)(nil))},
+	gateway.EventTypeMessageDelete:     {cacheFlags: cache.FlagMessages | cache.FlagChannels, events: eventTypes((*events.MessageDelete)(nil), (*events.DMMessageDelete)(nil), (*events.GuildMessageDelete)(nil))},
+	gateway.EventTypeMessageDeleteBulk: {cacheFlags: cache.FlagMessages | cache.FlagChannels, events: eventTypes((*events.MessageDelete)(nil), (*events.DMMessageDelete)(nil), (*events.GuildMessageDelete)(nil))},
+
+	gateway.EventTypeMessageReactionAdd:         {cacheFlags: cache.FlagMembers, events: eventTypes((*events.MessageReactionAdd)(nil), (*events.DMMessageReactionAdd)(nil), (*events.GuildMessageReactionAdd)(nil))},
+	gateway.EventTypeMessageReactionRemove:      {events: eventTypes((*events.MessageReactionRemove)(nil), (*events.DMMessageReactionRemove)(nil), (*events.GuildMessageReactionRemove)(nil))},
+	gateway.EventTypeMessageReactionRemoveAll:   {events: eventTypes((*events.MessageReactionRemoveAll)(nil), (*events.DMMessageReactionRemoveAll)(nil), (*events.GuildMessageReactionRemoveAll)(nil))},
+	gateway.EventTypeMessageReactionRemoveEmoji: {events: eventTypes((*events.MessageReactionRemoveEmoji)(nil), (*events.DMMessageReactionRemoveEmoji)(nil), (*events.GuildMessageReactionRemoveEmoji)(nil))},
+
+	gateway.EventTypePresenceUpdate: {cacheFlags: cache.FlagPresences, events: eventTypes((*events.UserStatusUpdate)(nil), (*events.UserClientStatusUpdate)(nil), (*events.UserActivityStart)(nil), (*events.UserActivityUpdate)(nil), (*events.UserActivityStop)(nil))},
+
+	gateway.EventTypeStageInstanceCreate: {cacheFlags: cache.FlagStageInstances, events: eventTypes((*events.StageInstanceCreate)(nil))},
+	gateway.EventTypeStageInstanceUpdate: {cacheFlags: cache.FlagStageInstances, events: eventTypes((*events.StageInstanceUpdate)(nil))},
+	gateway.EventTypeStageInstanceDelete: {cacheFlags: cache.FlagStageInstances, events: eventTypes((*events.StageInstanceDelete)(nil))},
+
+	gateway.EventTypeTypingStart: {cacheFlags: cache.FlagMembers, events: eventTypes((*events.UserTypingStart)(nil), (*events.DMUserTypingStart)(nil), (*events.GuildMemberTypingStart)(nil))},
+
+	gateway.EventTypeWebhooksUpdate: {events: eventTypes((*events.WebhooksUpdate)(nil))},
+}
@@ -77,6 +77,7 @@ func gatewayHandlerGuildCreate(client bot.Client, sequenceNumber int, shardID in
 				GenericEvent: events.NewGenericEvent(client, sequenceNumber, shardID),
 			})
 		}
+		client.ReadyTracker().HandleGuildLoaded(shardID, event.ID)
 		if client.MemberChunkingManager().MemberChunkingFilter()(event.ID) {
 			go func() {
 				if _, err := client.MemberChunkingManager().RequestMembersWithQuery(event.ID, "", 0); err != nil {
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"reflect"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// RequiredIntents returns the minimal gateway.Intents needed to receive all bot.Event(s) the bot.Client listens to and to fill its enabled cache.Flags.
+// Only bot.Event(s) which bot.TypedEventListener(s) explicitly listen to are taken into account, see bot.EventManager.ListensToTyped.
+// EventListener(s) which don't implement bot.TypedEventListener would require every intent, including the privileged ones, so they are ignored.
+func RequiredIntents(client bot.Client) gateway.Intents {
+	intents := gateway.IntentsNone
+	cacheFlags := client.Caches().CacheFlags()
+	for flag, flagIntents := range cacheFlagIntents {
+		if cacheFlags.Has(flag) {
+			intents = intents.Add(flagIntents)
+		}
+	}
+	for eventType, eventIntents := range eventIntents {
+		if client.EventManager().ListensToTyped(eventType) {
+			intents = intents.Add(eventIntents)
+		}
+	}
+	return intents
+}
+
+var cacheFlagIntents = map[cache.Flags]gateway.Intents{
+	cache.FlagGuilds:               gateway.IntentGuilds,
+	cache.FlagGuildScheduledEvents: gateway.IntentGuilds | gateway.IntentGuildScheduledEvents,
+	cache.FlagMembers:              gateway.IntentGuilds | gateway.IntentGuildMembers,
+	cache.FlagThreadMembers:        gateway.IntentGuilds,
+	cache.FlagMessages:             gateway.IntentGuildMessages | gateway.IntentDirectMessages,
+	cache.FlagPresences:            gateway.IntentGuilds | gateway.IntentGuildPresences,
+	cache.FlagChannels:             gateway.IntentGuilds,
+	cache.FlagRoles:                gateway.IntentGuilds,
+	cache.FlagEmojis:               gateway.IntentGuilds | gateway.IntentGuildEmojisAndStickers,
+	cache.FlagStickers:             gateway.IntentGuilds | gateway.IntentGuildEmojisAndStickers,
+	cache.FlagVoiceStates:          gateway.IntentGuilds | gateway.IntentGuildVoiceStates,
+	cache.FlagStageInstances:       gateway.IntentGuilds,
+}
+
+func eventType(event bot.Event) reflect.Type {
+	return reflect.TypeOf(event)
+}
+
+var eventIntents = map[reflect.Type]gateway.Intents{
+	eventType((*events.AutoModerationRuleCreate)(nil)):      gateway.IntentAutoModerationConfiguration,
+	eventType((*events.AutoModerationRuleUpdate)(nil)):      gateway.IntentAutoModerationConfiguration,
+	eventType((*events.AutoModerationRuleDelete)(nil)):      gateway.IntentAutoModerationConfiguration,
+	eventType((*events.AutoModerationActionExecution)(nil)): gateway.IntentAutoModerationExecution,
+
+	eventType((*events.GuildChannelCreate)(nil)):     gateway.IntentGuilds,
+	eventType((*events.GuildChannelUpdate)(nil)):     gateway.IntentGuilds,
+	eventType((*events.GuildChannelDelete)(nil)):     gateway.IntentGuilds,
+	eventType((*events.GuildChannelPinsUpdate)(nil)): gateway.IntentGuilds,
+	eventType((*events.DMChannelPinsUpdate)(nil)):    gateway.IntentDirectMessages,
+
+	eventType((*events.ThreadCreate)(nil)):       gateway.IntentGuilds,
+	eventType((*events.ThreadUpdate)(nil)):       gateway.IntentGuilds,
+	eventType((*events.ThreadDelete)(nil)):       gateway.IntentGuilds,
+	eventType((*events.ThreadShow)(nil)):         gateway.IntentGuilds,
+	eventType((*events.ThreadHide)(nil)):         gateway.IntentGuilds,
+	eventType((*events.ThreadMemberAdd)(nil)):    gateway.IntentGuilds | gateway.IntentGuildMembers,
+	eventType((*events.ThreadMemberUpdate)(nil)): gateway.IntentGuilds,
+	eventType((*events.ThreadMemberRemove)(nil)): gateway.IntentGuilds | gateway.IntentGuildMembers,
+
+	eventType((*events.GuildReady)(nil)):       gateway.IntentGuilds,
+	eventType((*events.GuildsReady)(nil)):      gateway.IntentGuilds,
+	eventType((*events.GuildAvailable)(nil)):   gateway.IntentGuilds,
+	eventType((*events.GuildUnavailable)(nil)): gateway.IntentGuilds,
+	eventType((*events.GuildJoin)(nil)):        gateway.IntentGuilds,
+	eventType((*events.GuildLeave)(nil)):       gateway.IntentGuilds,
+	eventType((*events.GuildUpdate)(nil)):      gateway.IntentGuilds,
+
+	eventType((*events.GuildAuditLogEntryCreate)(nil)): gateway.IntentGuildModeration,
+	eventType((*events.GuildBan)(nil)):                 gateway.IntentGuildModeration,
+	eventType((*events.GuildUnban)(nil)):               gateway.IntentGuildModeration,
+
+	eventType((*events.EmojisUpdate)(nil)):   gateway.IntentGuildEmojisAndStickers,
+	eventType((*events.EmojiCreate)(nil)):    gateway.IntentGuildEmojisAndStickers,
+	eventType((*events.EmojiUpdate)(nil)):    gateway.IntentGuildEmojisAndStickers,
+	eventType((*events.EmojiDelete)(nil)):    gateway.IntentGuildEmojisAndStickers,
+	eventType((*events.StickersUpdate)(nil)): gateway.IntentGuildEmojisAndStickers,
+	eventType((*events.StickerCreate)(nil)):  gateway.IntentGuildEmojisAndStickers,
+	eventType((*events.StickerUpdate)(nil)):  gateway.IntentGuildEmojisAndStickers,
+	eventType((*events.StickerDelete)(nil)):  gateway.IntentGuildEmojisAndStickers,
+
+	eventType((*events.GuildIntegrationsUpdate)(nil)): gateway.IntentGuildIntegrations,
+	eventType((*events.IntegrationCreate)(nil)):       gateway.IntentGuildIntegrations,
+	eventType((*events.IntegrationUpdate)(nil)):       gateway.IntentGuildIntegrations,
+	eventType((*events.IntegrationDelete)(nil)):       gateway.IntentGuildIntegrations,
+
+	eventType((*events.GuildMemberJoin)(nil)):   gateway.IntentGuildMembers,
+	eventType((*events.GuildMemberUpdate)(nil)): gateway.IntentGuildMembers,
+	eventType((*events.GuildMemberLeave)(nil)):  gateway.IntentGuildMembers,
+
+	eventType((*events.RoleCreate)(nil)): gateway.IntentGuilds,
+	eventType((*events.RoleUpdate)(nil)): gateway.IntentGuilds,
+	eventType((*events.RoleDelete)(nil)): gateway.IntentGuilds,
+
+	eventType((*events.GuildScheduledEventCreate)(nil)):     gateway.IntentGuildScheduledEvents,
+	eventType((*events.GuildScheduledEventUpdate)(nil)):     gateway.IntentGuildScheduledEvents,
+	eventType((*events.GuildScheduledEventDelete)(nil)):     gateway.IntentGuildScheduledEvents,
+	eventType((*events.GuildScheduledEventUserAdd)(nil)):    gateway.IntentGuildScheduledEvents,
+	eventType((*events.GuildScheduledEventUserRemove)(nil)): gateway.IntentGuildScheduledEvents,
+
+	eventType((*events.InviteCreate)(nil)): gateway.IntentGuildInvites,
+	eventType((*events.InviteDelete)(nil)): gateway.IntentGuildInvites,
+
+	eventType((*events.MessageCreate)(nil)):      gateway.IntentGuildMessages | gateway.IntentDirectMessages,
+	eventType((*events.MessageUpdate)(nil)):      gateway.IntentGuildMessages | gateway.IntentDirectMessages,
+	eventType((*events.MessageDelete)(nil)):      gateway.IntentGuildMessages | gateway.IntentDirectMessages,
+	eventType((*events.GuildMessageCreate)(nil)): gateway.IntentGuildMessages,
+	eventType((*events.GuildMessageUpdate)(nil)): gateway.IntentGuildMessages,
+	eventType((*events.GuildMessageDelete)(nil)): gateway.IntentGuildMessages,
+	eventType((*events.DMMessageCreate)(nil)):    gateway.IntentDirectMessages,
+	eventType((*events.DMMessageUpdate)(nil)):    gateway.IntentDirectMessages,
+	eventType((*events.DMMessageDelete)(nil)):    gateway.IntentDirectMessages,
+
+	eventType((*events.MessageReactionAdd)(nil)):              gateway.IntentGuildMessageReactions | gateway.IntentDirectMessageReactions,
+	eventType((*events.MessageReactionRemove)(nil)):           gateway.IntentGuildMessageReactions | gateway.IntentDirectMessageReactions,
+	eventType((*events.MessageReactionRemoveEmoji)(nil)):      gateway.IntentGuildMessageReactions | gateway.IntentDirectMessageReactions,
+	eventType((*events.MessageReactionRemoveAll)(nil)):        gateway.IntentGuildMessageReactions | gateway.IntentDirectMessageReactions,
+	eventType((*events.GuildMessageReactionAdd)(nil)):         gateway.IntentGuildMessageReactions,
+	eventType((*events.GuildMessageReactionRemove)(nil)):      gateway.IntentGuildMessageReactions,
+	eventType((*events.GuildMessageReactionRemoveEmoji)(nil)): gateway.IntentGuildMessageReactions,
+	eventType((*events.GuildMessageReactionRemoveAll)(nil)):   gateway.IntentGuildMessageReactions,
+	eventType((*events.DMMessageReactionAdd)(nil)):            gateway.IntentDirectMessageReactions,
+	eventType((*events.DMMessageReactionRemove)(nil)):         gateway.IntentDirectMessageReactions,
+	eventType((*events.DMMessageReactionRemoveEmoji)(nil)):    gateway.IntentDirectMessageReactions,
+	eventType((*events.DMMessageReactionRemoveAll)(nil)):      gateway.IntentDirectMessageReactions,
+
+	eventType((*events.UserStatusUpdate)(nil)):       gateway.IntentGuildPresences,
+	eventType((*events.UserClientStatusUpdate)(nil)): gateway.IntentGuildPresences,
+	eventType((*events.UserActivityStart)(nil)):      gateway.IntentGuildPresences,
+	eventType((*events.UserActivityUpdate)(nil)):     gateway.IntentGuildPresences,
+	eventType((*events.UserActivityStop)(nil)):       gateway.IntentGuildPresences,
+
+	eventType((*events.StageInstanceCreate)(nil)): gateway.IntentGuilds,
+	eventType((*events.StageInstanceUpdate)(nil)): gateway.IntentGuilds,
+	eventType((*events.StageInstanceDelete)(nil)): gateway.IntentGuilds,
+
+	eventType((*events.UserTypingStart)(nil)):        gateway.IntentGuildMessageTyping | gateway.IntentDirectMessageTyping,
+	eventType((*events.GuildMemberTypingStart)(nil)): gateway.IntentGuildMessageTyping,
+	eventType((*events.DMUserTypingStart)(nil)):      gateway.IntentDirectMessageTyping,
+
+	eventType((*events.GuildVoiceStateUpdate)(nil)): gateway.IntentGuildVoiceStates,
+	eventType((*events.GuildVoiceJoin)(nil)):        gateway.IntentGuildVoiceStates,
+	eventType((*events.GuildVoiceMove)(nil)):        gateway.IntentGuildVoiceStates,
+	eventType((*events.GuildVoiceLeave)(nil)):       gateway.IntentGuildVoiceStates,
+
+	eventType((*events.WebhooksUpdate)(nil)): gateway.IntentGuildWebhooks,
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+type untypedListener struct{}
+
+func (untypedListener) OnEvent(bot.Event) {}
+
+func TestRequiredIntents(t *testing.T) {
+	config := bot.DefaultConfig(GetGatewayHandlers(), GetHTTPServerHandler())
+	config.RequiredIntentsFunc = RequiredIntents
+	config.Apply([]bot.ConfigOpt{
+		bot.WithCacheConfigOpts(cache.WithCaches(cache.FlagRoles)),
+		bot.WithEventListeners(
+			bot.NewListenerFunc(func(e *events.GuildMessageCreate) {}),
+			bot.NewListenerFunc(func(e *events.DMMessageReactionAdd) {}),
+			// untyped listeners would require every intent, so they are ignored
+			untypedListener{},
+		),
+	})
+
+	// the token only needs to contain the application ID
+	client, err := bot.BuildClient("MTIz.token", *config, DefaultGatewayEventHandlerFunc, DefaultHTTPServerEventHandlerFunc, "linux", "disgo", "", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, gateway.IntentGuilds|gateway.IntentGuildMessages|gateway.IntentDirectMessageReactions, client.RequiredIntents())
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"github.com/disgoorg/snowflake/v2"
+
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgo/gateway"
@@ -31,10 +33,66 @@ func gatewayHandlerReady(client bot.Client, sequenceNumber int, shardID int, eve
 		GenericEvent: events.NewGenericEvent(client, sequenceNumber, shardID),
 		EventReady:   event,
 	})
+
+	guildIDs := make([]snowflake.ID, len(event.Guilds))
+	for i, guild := range event.Guilds {
+		guildIDs[i] = guild.ID
+	}
+	client.ReadyTracker().HandleReady(shardID, guildIDs)
 }
 
 func gatewayHandlerResumed(client bot.Client, sequenceNumber int, shardID int, _ gateway.EventData) {
 	client.EventManager().DispatchEvent(&events.Resumed{
 		GenericEvent: events.NewGenericEvent(client, sequenceNumber, shardID),
 	})
+	client.ReadyTracker().HandleResumed(shardID)
+}
+
+func gatewayHandlerStatusChange(client bot.Client, sequenceNumber int, shardID int, event gateway.EventStatusChange) {
+	genericEvent := events.NewGenericEvent(client, sequenceNumber, shardID)
+	if client.HasShardManager() {
+		client.EventManager().DispatchEvent(&events.ShardStatusChange{
+			GenericEvent: genericEvent,
+			StatusChange: event.StatusChange,
+		})
+		return
+	}
+	client.EventManager().DispatchEvent(&events.GatewayStatusChange{
+		GenericEvent: genericEvent,
+		StatusChange: event.StatusChange,
+	})
+}
+
+func gatewayHandlerIdentifyBudgetLow(client bot.Client, sequenceNumber int, shardID int, event gateway.EventIdentifyBudgetLow) {
+	client.EventManager().DispatchEvent(&events.IdentifyBudgetLow{
+		GenericEvent:           events.NewGenericEvent(client, sequenceNumber, shardID),
+		EventIdentifyBudgetLow: event,
+	})
+}
+
+func gatewayHandlerShardReady(client bot.Client, sequenceNumber int, shardID int, event gateway.EventShardReady) {
+	client.EventManager().DispatchEvent(&events.ShardReady{
+		GenericEvent:    events.NewGenericEvent(client, sequenceNumber, shardID),
+		EventShardReady: event,
+	})
+
+	if len(event.UnavailableGuildIDs) == 0 {
+		return
+	}
+	// guilds which did not load within the guild load timeout are not waited for anymore
+	for _, guildID := range event.UnavailableGuildIDs {
+		client.Caches().SetGuildUnready(guildID, false)
+	}
+	if len(client.Caches().UnreadyGuildIDs()) == 0 {
+		client.EventManager().DispatchEvent(&events.GuildsReady{
+			GenericEvent: events.NewGenericEvent(client, sequenceNumber, shardID),
+		})
+	}
+}
+
+func gatewayHandlerAllShardsReady(client bot.Client, sequenceNumber int, shardID int, event gateway.EventAllShardsReady) {
+	client.EventManager().DispatchEvent(&events.AllShardsReady{
+		GenericEvent:        events.NewGenericEvent(client, sequenceNumber, shardID),
+		EventAllShardsReady: event,
+	})
 }
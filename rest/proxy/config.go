@@ -0,0 +1,80 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/disgoorg/log"
+
+	"github.com/disgoorg/disgo/rest"
+)
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() *Config {
+	return &Config{
+		Logger:                 log.Default(),
+		HTTPClient:             &http.Client{},
+		UpstreamURL:            "https://discord.com",
+		RateLimiterIdleTimeout: 10 * time.Minute,
+	}
+}
+
+// Config lets you configure your Server.
+type Config struct {
+	// Logger is the logger of the Server. Defaults to log.Default().
+	Logger log.Logger
+	// HTTPClient is the http.Client requests are forwarded with. Requests are cancelled together with the incoming request, so it needs no timeout. Defaults to &http.Client{}.
+	HTTPClient *http.Client
+	// UpstreamURL is where requests are forwarded to. The path of the incoming request is kept, so point rest.Config.URL to the proxy including /api/v10. Defaults to https://discord.com.
+	UpstreamURL string
+	// RateLimiterConfigOpts are the rest.RateLimiterConfigOpt(s) which are applied to the rest.RateLimiter of each token.
+	// Use rest.WithBucketStore to share the rate limits between multiple proxies.
+	RateLimiterConfigOpts []rest.RateLimiterConfigOpt
+	// RateLimiterIdleTimeout is how long the rest.RateLimiter of a token is kept without requests before it is closed and removed. Defaults to 10 minutes.
+	RateLimiterIdleTimeout time.Duration
+}
+
+// ConfigOpt is a type alias for a function that takes a Config and is used to configure your Server.
+type ConfigOpt func(config *Config)
+
+// Apply applies the given ConfigOpt(s) to the Config
+func (c *Config) Apply(opts []ConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithLogger sets the logger of the Server.
+func WithLogger(logger log.Logger) ConfigOpt {
+	return func(config *Config) {
+		config.Logger = logger
+	}
+}
+
+// WithHTTPClient sets the http.Client requests are forwarded with.
+func WithHTTPClient(httpClient *http.Client) ConfigOpt {
+	return func(config *Config) {
+		config.HTTPClient = httpClient
+	}
+}
+
+// WithUpstreamURL sets where requests are forwarded to.
+func WithUpstreamURL(upstreamURL string) ConfigOpt {
+	return func(config *Config) {
+		config.UpstreamURL = upstreamURL
+	}
+}
+
+// WithRateLimiterConfigOpts lets you configure the rest.RateLimiter of each token.
+func WithRateLimiterConfigOpts(opts ...rest.RateLimiterConfigOpt) ConfigOpt {
+	return func(config *Config) {
+		config.RateLimiterConfigOpts = append(config.RateLimiterConfigOpts, opts...)
+	}
+}
+
+// WithRateLimiterIdleTimeout sets how long the rest.RateLimiter of a token is kept without requests.
+func WithRateLimiterIdleTimeout(idleTimeout time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.RateLimiterIdleTimeout = idleTimeout
+	}
+}
@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/disgoorg/json"
+
+	"github.com/disgoorg/disgo/rest"
+)
+
+// BucketMetrics are the metrics of a route template and the rate limit bucket Discord reported for it, summed up over all major parameters & tokens.
+type BucketMetrics struct {
+	Route string `json:"route"`
+	// Bucket is the rate limit bucket hash Discord reported for the route. Requests before Discord reported one have no Bucket.
+	Bucket string `json:"bucket"`
+	// Requests is the number of requests which were forwarded to Discord, including retries.
+	Requests uint64 `json:"requests"`
+	// RateLimited is the number of 429 Too Many Requests responses.
+	RateLimited uint64 `json:"rate_limited"`
+	// Errors is the number of requests which could not be forwarded.
+	Errors uint64 `json:"errors"`
+	// Cancelled is the number of requests which were cancelled by the client.
+	Cancelled uint64 `json:"cancelled"`
+	// Wait is the total time requests waited for the rate limit.
+	Wait time.Duration `json:"wait"`
+	// LastStatus is the status code of the last response from Discord.
+	LastStatus int `json:"last_status"`
+}
+
+// updateMetrics updates the BucketMetrics of the route of the endpoint and its last known bucket hash, which is taken from the response if there is one.
+// Major parameters are not part of the key, so the number of BucketMetrics is bounded by the number of routes.
+func (s *Server) updateMetrics(endpoint *rest.CompiledEndpoint, rs *http.Response, updateFunc func(metrics *BucketMetrics)) {
+	route := endpoint.Endpoint.Method + "+" + endpoint.Endpoint.Route
+
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+	if rs != nil {
+		if hash := rs.Header.Get("X-RateLimit-Bucket"); hash != "" {
+			s.routeBuckets[route] = hash
+		}
+	}
+	hash := s.routeBuckets[route]
+	key := route
+	if hash != "" {
+		key += "+" + hash
+	}
+	metrics, ok := s.metrics[key]
+	if !ok {
+		metrics = &BucketMetrics{Route: endpoint.Endpoint.Route, Bucket: hash}
+		s.metrics[key] = metrics
+	}
+	updateFunc(metrics)
+}
+
+// Metrics returns a copy of the BucketMetrics of all buckets as a map keyed by method, route template and bucket hash.
+func (s *Server) Metrics() map[string]BucketMetrics {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+	metrics := make(map[string]BucketMetrics, len(s.metrics))
+	for key, bucketMetrics := range s.metrics {
+		metrics[key] = *bucketMetrics
+	}
+	return metrics
+}
+
+// MetricsHandler returns a http.Handler which responds with the Metrics as json.
+// Serve it on another port or path than the Server, as all requests to the Server are forwarded to Discord.
+func (s *Server) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.Metrics()); err != nil {
+			s.config.Logger.Error("failed to write metrics: ", err)
+		}
+	})
+}
@@ -0,0 +1,249 @@
+// Package proxy is a Discord REST API proxy with centralized rate limiting, so many clients using the same tokens share their rate limits.
+//
+// Serve the Server and point the rest.Client(s) to it with a disabled rate limiter:
+//
+//	server := proxy.New()
+//	go http.ListenAndServe(":7979", server)
+//
+//	client, _ := disgo.New(token, bot.WithRestClientConfigOpts(
+//		rest.WithURL("http://rest-proxy:7979/api/v10"),
+//		rest.WithRateLimiter(rest.NewNoopRateLimiter()),
+//	))
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/rest"
+)
+
+var _ http.Handler = (*Server)(nil)
+
+// hopHeaders are the headers which only apply to a single connection and are not forwarded.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// New creates a new Server with the given ConfigOpt(s).
+func New(opts ...ConfigOpt) *Server {
+	config := DefaultConfig()
+	config.Apply(opts)
+
+	s := &Server{
+		config:       *config,
+		upstreamURL:  strings.TrimSuffix(config.UpstreamURL, "/"),
+		rateLimiters: map[string]*tokenRateLimiter{},
+		routeBuckets: map[string]string{},
+		metrics:      map[string]*BucketMetrics{},
+		closed:       make(chan struct{}),
+	}
+	if config.RateLimiterIdleTimeout > 0 {
+		go s.evictRateLimiters()
+	}
+	return s
+}
+
+// Server is a http.Handler which forwards Discord API requests to Discord.
+// Every token gets its own rest.RateLimiter, requests without token share one. Requests are queued until their rate limit bucket is available
+// and retried after 429 Too Many Requests up to the rest.RateLimiter.MaxRetries() times. When a client cancels a request, it is dropped from the queue.
+// The rest.RateLimiter of a token is closed and removed after Config.RateLimiterIdleTimeout without requests.
+type Server struct {
+	config      Config
+	upstreamURL string
+
+	rateLimitersMu sync.Mutex
+	rateLimiters   map[string]*tokenRateLimiter
+
+	metricsMu sync.Mutex
+	// method + route -> bucket hash
+	routeBuckets map[string]string
+	metrics      map[string]*BucketMetrics
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// tokenRateLimiter is the rest.RateLimiter of a token with its usage.
+type tokenRateLimiter struct {
+	rest.RateLimiter
+	// requests is the number of requests currently using the rest.RateLimiter
+	requests int
+	lastUsed time.Time
+}
+
+// rateLimiter returns the rest.RateLimiter of the given Authorization header and creates it if it does not exist yet.
+// It is used until releaseRateLimiter is called.
+func (s *Server) rateLimiter(authorization string) *tokenRateLimiter {
+	s.rateLimitersMu.Lock()
+	defer s.rateLimitersMu.Unlock()
+	rateLimiter, ok := s.rateLimiters[authorization]
+	if !ok {
+		rateLimiter = &tokenRateLimiter{RateLimiter: rest.NewRateLimiter(s.config.RateLimiterConfigOpts...)}
+		s.rateLimiters[authorization] = rateLimiter
+	}
+	rateLimiter.requests++
+	return rateLimiter
+}
+
+func (s *Server) releaseRateLimiter(rateLimiter *tokenRateLimiter) {
+	s.rateLimitersMu.Lock()
+	defer s.rateLimitersMu.Unlock()
+	rateLimiter.requests--
+	rateLimiter.lastUsed = time.Now()
+}
+
+// evictRateLimiters closes & removes the rest.RateLimiter(s) which were not used for Config.RateLimiterIdleTimeout until the Server is closed.
+func (s *Server) evictRateLimiters() {
+	ticker := time.NewTicker(s.config.RateLimiterIdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+		}
+
+		var idle []rest.RateLimiter
+		s.rateLimitersMu.Lock()
+		for authorization, rateLimiter := range s.rateLimiters {
+			if rateLimiter.requests == 0 && time.Since(rateLimiter.lastUsed) >= s.config.RateLimiterIdleTimeout {
+				idle = append(idle, rateLimiter.RateLimiter)
+				delete(s.rateLimiters, authorization)
+			}
+		}
+		s.rateLimitersMu.Unlock()
+
+		// idle rest.RateLimiter(s) have no pending requests, so closing them does not block
+		for _, rateLimiter := range idle {
+			rateLimiter.Close(context.Background())
+		}
+		if len(idle) > 0 {
+			s.config.Logger.Debugf("closed %d idle rate limiters", len(idle))
+		}
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	endpoint := compileEndpoint(r.Method, r.URL.Path)
+	key := endpoint.Endpoint.Method + "+" + endpoint.Endpoint.Route
+	if endpoint.MajorParams != "" {
+		key += "+" + endpoint.MajorParams
+	}
+	updateMetrics := func(updateFunc func(metrics *BucketMetrics)) {
+		s.updateMetrics(endpoint, nil, updateFunc)
+	}
+
+	rqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.config.Logger.Debug("failed to read request body: ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rateLimiter := s.rateLimiter(r.Header.Get("Authorization"))
+	defer s.releaseRateLimiter(rateLimiter)
+	for try := 1; ; try++ {
+		start := time.Now()
+		if err = rateLimiter.WaitBucket(ctx, endpoint); err != nil {
+			s.fail(ctx, w, err, updateMetrics)
+			return
+		}
+		wait := time.Since(start)
+
+		var rq *http.Request
+		if rq, err = http.NewRequestWithContext(ctx, r.Method, s.upstreamURL+r.URL.RequestURI(), bytes.NewReader(rqBody)); err != nil {
+			_ = rateLimiter.UnlockBucket(endpoint, nil)
+			s.fail(ctx, w, err, updateMetrics)
+			return
+		}
+		copyHeaders(rq.Header, r.Header)
+
+		rs, err := s.config.HTTPClient.Do(rq)
+		if err != nil {
+			_ = rateLimiter.UnlockBucket(endpoint, nil)
+			s.fail(ctx, w, err, updateMetrics)
+			return
+		}
+		rsBody, err := io.ReadAll(rs.Body)
+		_ = rs.Body.Close()
+		if unlockErr := rateLimiter.UnlockBucket(endpoint, rs); unlockErr != nil {
+			s.config.Logger.Errorf("failed to unlock bucket of %s: %s", key, unlockErr)
+		}
+		if err != nil {
+			s.fail(ctx, w, err, updateMetrics)
+			return
+		}
+
+		s.updateMetrics(endpoint, rs, func(metrics *BucketMetrics) {
+			metrics.Requests++
+			metrics.Wait += wait
+			metrics.LastStatus = rs.StatusCode
+			if rs.StatusCode == http.StatusTooManyRequests {
+				metrics.RateLimited++
+			}
+		})
+
+		if rs.StatusCode == http.StatusTooManyRequests && try < rateLimiter.MaxRetries() {
+			s.config.Logger.Debugf("rate limited on %s, retrying", key)
+			continue
+		}
+
+		copyHeaders(w.Header(), rs.Header)
+		w.WriteHeader(rs.StatusCode)
+		if _, err = w.Write(rsBody); err != nil {
+			s.config.Logger.Debug("failed to write response: ", err)
+		}
+		return
+	}
+}
+
+// fail records the error of a request and responds with 502 Bad Gateway unless the client cancelled the request.
+func (s *Server) fail(ctx context.Context, w http.ResponseWriter, err error, updateMetrics func(updateFunc func(metrics *BucketMetrics))) {
+	if ctx.Err() != nil {
+		updateMetrics(func(metrics *BucketMetrics) {
+			metrics.Cancelled++
+		})
+		return
+	}
+	s.config.Logger.Error("failed to forward request: ", err)
+	updateMetrics(func(metrics *BucketMetrics) {
+		metrics.Errors++
+	})
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// Close closes the rest.RateLimiter(s) of all tokens and awaits all pending requests. You can use a cancelling context to abort the waiting.
+func (s *Server) Close(ctx context.Context) {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	s.rateLimitersMu.Lock()
+	defer s.rateLimitersMu.Unlock()
+	for _, rateLimiter := range s.rateLimiters {
+		rateLimiter.Close(ctx)
+	}
+	s.config.HTTPClient.CloseIdleConnections()
+}
+
+func copyHeaders(dst http.Header, src http.Header) {
+	for name, values := range src {
+		dst[name] = append([]string(nil), values...)
+	}
+	for _, name := range hopHeaders {
+		dst.Del(name)
+	}
+}
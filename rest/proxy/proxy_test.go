@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/rest"
+)
+
+func TestCompileEndpoint(t *testing.T) {
+	endpoint := compileEndpoint(http.MethodPut, "/api/v10/channels/1/messages/2/reactions/%F0%9F%91%8D/@me")
+	assert.Equal(t, "/api/v10/channels/{channel.id}/messages/{id}/reactions/{emoji}/@me", endpoint.Endpoint.Route)
+	assert.Equal(t, "channel.id=1", endpoint.MajorParams)
+
+	endpoint = compileEndpoint(http.MethodPost, "/api/v10/webhooks/1/token")
+	assert.Equal(t, "/api/v10/webhooks/{webhook.id}/{webhook.token}", endpoint.Endpoint.Route)
+	assert.Equal(t, "webhook.id=1", endpoint.MajorParams)
+}
+
+func TestServer(t *testing.T) {
+	var requests int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v10/channels/1", r.URL.Path)
+		assert.Equal(t, "Bot token", r.Header.Get("Authorization"))
+		w.Header().Set("Via", "1.1 google")
+		w.Header().Set("X-RateLimit-Bucket", "abc")
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5")
+		w.Header().Set("X-RateLimit-Remaining", "4")
+		w.Header().Set("X-RateLimit-Reset-After", "1")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer upstream.Close()
+
+	server := New(WithUpstreamURL(upstream.URL))
+	proxy := httptest.NewServer(server)
+	defer proxy.Close()
+
+	client := rest.NewClient("token", rest.WithURL(proxy.URL+"/api/v10"), rest.WithRateLimiter(rest.NewNoopRateLimiter()))
+	var rsBody map[string]string
+	assert.NoError(t, client.Do(rest.GetChannel.Compile(nil, 1), nil, &rsBody))
+	assert.Equal(t, "1", rsBody["id"])
+
+	metrics := server.Metrics()["GET+/api/v10/channels/{channel.id}+abc"]
+	assert.Equal(t, "abc", metrics.Bucket)
+	assert.Equal(t, uint64(2), metrics.Requests)
+	assert.Equal(t, uint64(1), metrics.RateLimited)
+	assert.Equal(t, http.StatusOK, metrics.LastStatus)
+}
+
+func TestServerEvictsIdleRateLimiters(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	server := New(WithUpstreamURL(upstream.URL), WithRateLimiterIdleTimeout(50*time.Millisecond))
+	defer server.Close(context.Background())
+	proxy := httptest.NewServer(server)
+	defer proxy.Close()
+
+	for _, token := range []string{"token1", "token2"} {
+		client := rest.NewClient(token, rest.WithURL(proxy.URL+"/api/v10"), rest.WithRateLimiter(rest.NewNoopRateLimiter()))
+		assert.NoError(t, client.Do(rest.GetChannel.Compile(nil, 1), nil, nil))
+	}
+
+	assert.Eventually(t, func() bool {
+		server.rateLimitersMu.Lock()
+		defer server.rateLimitersMu.Unlock()
+		return len(server.rateLimiters) == 0
+	}, time.Second, 10*time.Millisecond)
+}
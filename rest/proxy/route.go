@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/rest"
+)
+
+// majorParams are the path segments which are followed by a major parameter and the name of the parameter
+var majorParams = map[string]string{
+	"channels": "channel.id",
+	"guilds":   "guild.id",
+	"webhooks": "webhook.id",
+}
+
+// compileEndpoint returns the rest.CompiledEndpoint of the given request.
+// IDs, tokens & emojis in the path are replaced by placeholders to get the route template, major parameters are kept as rest.CompiledEndpoint.MajorParams.
+func compileEndpoint(method string, path string) *rest.CompiledEndpoint {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	route := make([]string, len(segments))
+	var params []string
+	for i, segment := range segments {
+		route[i] = segment
+		if i == 0 {
+			continue
+		}
+		previous := segments[i-1]
+		switch {
+		case majorParams[previous] != "" && isID(segment):
+			name := majorParams[previous]
+			route[i] = "{" + name + "}"
+			params = append(params, name+"="+segment)
+
+		case i > 1 && segments[i-2] == "webhooks" && isID(previous):
+			route[i] = "{webhook.token}"
+
+		case i > 1 && segments[i-2] == "interactions" && isID(previous):
+			route[i] = "{interaction.token}"
+			params = append(params, "interaction.token="+segment)
+
+		case previous == "reactions":
+			route[i] = "{emoji}"
+
+		case isID(segment):
+			route[i] = "{id}"
+		}
+	}
+
+	return &rest.CompiledEndpoint{
+		Endpoint: &rest.Endpoint{
+			Method: method,
+			Route:  "/" + strings.Join(route, "/"),
+		},
+		URL:         path,
+		MajorParams: strings.Join(params, ":"),
+	}
+}
+
+func isID(segment string) bool {
+	_, err := strconv.ParseUint(segment, 10, 64)
+	return err == nil
+}
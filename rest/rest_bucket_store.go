@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bucket is the rate limit state of a route with its major parameters.
+type Bucket struct {
+	// ID is the rate limit bucket hash Discord reported for the route. Routes with the same hash share their rate limit.
+	ID string
+	// Limit is the number of requests which can be made until Reset. It is -1 as long as it is unknown.
+	Limit int
+	// Remaining is the number of requests which can still be made until Reset.
+	Remaining int
+	// Reset is when the bucket resets.
+	Reset time.Time
+}
+
+// BucketStore holds the rate limit state of the default RateLimiter: the Bucket(s), the route hashes Discord reported and the global rate limit.
+// Share a BucketStore between all processes using the same token, so they respect each other's rate limits. See ListenBucketStore & NewBucketStoreClient.
+// If a shared BucketStore fails, the RateLimiter falls back to the rate limits known to its process, see WithBucketStore.
+//
+// A route is the method and the route template of an Endpoint. Routes are mapped to the Bucket of the hash Discord reported for them, which might be shared with other routes.
+type BucketStore interface {
+	// Reserve takes one request from the Bucket of the given route and major parameters.
+	// If the Bucket or the global rate limit is exhausted, nothing is taken and the time the caller should try again at is returned instead.
+	Reserve(ctx context.Context, route string, majorParams string) (time.Time, error)
+
+	// Update stores the Bucket Discord reported for the given route and major parameters and maps the route to bucket.ID.
+	// A negative Limit or Remaining keeps the stored value.
+	Update(ctx context.Context, route string, majorParams string, bucket Bucket) error
+
+	// SetGlobalReset sets when the global rate limit resets.
+	SetGlobalReset(ctx context.Context, reset time.Time) error
+
+	// Cleanup removes all Bucket(s) which reset before now.
+	Cleanup(ctx context.Context) error
+
+	// Snapshot returns a copy of the stored state.
+	Snapshot(ctx context.Context) (BucketStoreSnapshot, error)
+}
+
+// BucketStoreSnapshot is a copy of the state of a BucketStore.
+type BucketStoreSnapshot struct {
+	// Global is when the global rate limit resets.
+	Global time.Time
+	// Hashes maps routes to the hash Discord reported for them.
+	Hashes map[string]string
+	// Buckets maps hashes, or routes with an unknown hash, plus major parameters to their Bucket.
+	Buckets map[string]Bucket
+}
+
+var _ BucketStore = (*memoryBucketStore)(nil)
+
+// NewMemoryBucketStore returns a BucketStore which keeps its state in memory. It is the default BucketStore of the RateLimiter.
+func NewMemoryBucketStore() BucketStore {
+	return &memoryBucketStore{
+		hashes:  map[string]string{},
+		buckets: map[string]*Bucket{},
+	}
+}
+
+type memoryBucketStore struct {
+	mu sync.Mutex
+	// global Rate Limit
+	global time.Time
+	// route -> hash
+	hashes map[string]string
+	// hash + major parameters -> Bucket
+	buckets map[string]*Bucket
+}
+
+func (s *memoryBucketStore) key(route string, majorParams string) string {
+	hash, ok := s.hashes[route]
+	if !ok {
+		hash = route
+	}
+	if majorParams != "" {
+		hash += "+" + majorParams
+	}
+	return hash
+}
+
+func (s *memoryBucketStore) Reserve(_ context.Context, route string, majorParams string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(route, majorParams)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &Bucket{
+			Remaining: 1,
+			// we don't know the limit yet
+			Limit: -1,
+		}
+		s.buckets[key] = b
+	}
+
+	now := time.Now()
+	if b.Remaining <= 0 && b.Reset.After(now) {
+		return b.Reset, nil
+	}
+	if s.global.After(now) {
+		return s.global, nil
+	}
+	if b.Limit > 0 && !b.Reset.After(now) {
+		b.Remaining = b.Limit
+	}
+	if b.Remaining > 0 {
+		b.Remaining--
+	}
+	return time.Time{}, nil
+}
+
+func (s *memoryBucketStore) Update(_ context.Context, route string, majorParams string, bucket Bucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if bucket.ID != "" && s.hashes[route] != bucket.ID {
+		// the bucket was stored under the route or an old hash until now
+		delete(s.buckets, s.key(route, majorParams))
+		s.hashes[route] = bucket.ID
+	}
+	key := s.key(route, majorParams)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &Bucket{Remaining: 1, Limit: -1}
+		s.buckets[key] = b
+	}
+	b.ID = bucket.ID
+	if bucket.Limit >= 0 {
+		b.Limit = bucket.Limit
+	}
+	if bucket.Remaining >= 0 {
+		b.Remaining = bucket.Remaining
+	}
+	b.Reset = bucket.Reset
+	return nil
+}
+
+func (s *memoryBucketStore) SetGlobalReset(_ context.Context, reset time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.global = reset
+	return nil
+}
+
+func (s *memoryBucketStore) Cleanup(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, b := range s.buckets {
+		if b.Reset.Before(now) {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
+
+func (s *memoryBucketStore) Snapshot(_ context.Context) (BucketStoreSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := BucketStoreSnapshot{
+		Global:  s.global,
+		Hashes:  make(map[string]string, len(s.hashes)),
+		Buckets: make(map[string]Bucket, len(s.buckets)),
+	}
+	for route, hash := range s.hashes {
+		snapshot.Hashes[route] = hash
+	}
+	for key, b := range s.buckets {
+		snapshot.Buckets[key] = *b
+	}
+	return snapshot, nil
+}
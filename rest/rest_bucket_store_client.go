@@ -0,0 +1,264 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+var _ BucketStore = (*BucketStoreClient)(nil)
+
+// NewBucketStoreClient creates a new BucketStoreClient backed by the BucketStoreServer listening on the given address.
+// Pass it to WithBucketStore to share the rate limits with all other processes using the same BucketStoreServer.
+func NewBucketStoreClient(addr string, opts ...BucketStoreConfigOpt) *BucketStoreClient {
+	config := DefaultBucketStoreConfig()
+	config.Apply(opts)
+
+	return &BucketStoreClient{
+		config: *config,
+		addr:   addr,
+	}
+}
+
+// BucketStoreClient is a BucketStore which keeps its state in a BucketStoreServer.
+// It connects lazily, keeps the connection alive with pings and reconnects on the next call after the connection died.
+// Only Reserve & Snapshot wait for an answer, Update, SetGlobalReset & Cleanup are sent without waiting.
+type BucketStoreClient struct {
+	config BucketStoreConfig
+	addr   string
+
+	mu     sync.Mutex
+	conn   *bucketStoreConn
+	closed bool
+}
+
+// bucketStoreConn is a connection of a BucketStoreClient with its requests waiting for an answer.
+type bucketStoreConn struct {
+	conn net.Conn
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan bucketStoreFrame
+
+	closeOnce sync.Once
+	err       error
+	done      chan struct{}
+}
+
+func (c *bucketStoreConn) close(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
+
+func (c *bucketStoreConn) write(ctx context.Context, timeout time.Duration, f bucketStoreFrame) error {
+	data, err := marshalBucketStoreFrame(f)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err = c.conn.SetWriteDeadline(deadline); err == nil {
+		_, err = c.conn.Write(data)
+	}
+	if err != nil {
+		c.close(err)
+	}
+	return err
+}
+
+func (c *BucketStoreClient) getConn(ctx context.Context) (*bucketStoreConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, net.ErrClosed
+	}
+	if c.conn != nil {
+		select {
+		case <-c.conn.done:
+		default:
+			return c.conn, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = &bucketStoreConn{
+		conn:    conn,
+		pending: map[uint64]chan bucketStoreFrame{},
+		done:    make(chan struct{}),
+	}
+	go c.listen(c.conn)
+	go c.ping(c.conn)
+	return c.conn, nil
+}
+
+func (c *BucketStoreClient) listen(conn *bucketStoreConn) {
+	err := readBucketStoreFrames(conn.conn, 3*c.config.PingInterval, func(f bucketStoreFrame) {
+		conn.mu.Lock()
+		ch, ok := conn.pending[f.ID]
+		delete(conn.pending, f.ID)
+		conn.mu.Unlock()
+		if ok {
+			ch <- f
+		}
+	})
+	if err == nil {
+		err = net.ErrClosed
+	}
+	conn.close(err)
+}
+
+func (c *BucketStoreClient) ping(conn *bucketStoreConn) {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+			if err := conn.write(context.Background(), c.config.Timeout, bucketStoreFrame{Op: bucketStoreOpPing}); err != nil {
+				c.config.Logger.Error("failed to ping bucket store server: ", err)
+				return
+			}
+		}
+	}
+}
+
+// send sends the frame without waiting for an answer.
+func (c *BucketStoreClient) send(ctx context.Context, f bucketStoreFrame) error {
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.write(ctx, c.config.Timeout, f)
+}
+
+// request sends the frame and waits for the answer. If the BucketStoreServer does not answer within the Timeout, the connection is closed.
+func (c *BucketStoreClient) request(ctx context.Context, f bucketStoreFrame) (bucketStoreFrame, error) {
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		return bucketStoreFrame{}, err
+	}
+
+	ch := make(chan bucketStoreFrame, 1)
+	conn.mu.Lock()
+	conn.nextID++
+	f.ID = conn.nextID
+	conn.pending[f.ID] = ch
+	conn.mu.Unlock()
+	defer func() {
+		conn.mu.Lock()
+		delete(conn.pending, f.ID)
+		conn.mu.Unlock()
+	}()
+
+	if err = conn.write(ctx, c.config.Timeout, f); err != nil {
+		return bucketStoreFrame{}, err
+	}
+
+	timer := time.NewTimer(c.config.Timeout)
+	defer timer.Stop()
+	select {
+	case rs := <-ch:
+		if rs.Error != "" {
+			return bucketStoreFrame{}, errors.New(rs.Error)
+		}
+		return rs, nil
+	case <-conn.done:
+		return bucketStoreFrame{}, conn.err
+	case <-ctx.Done():
+		return bucketStoreFrame{}, ctx.Err()
+	case <-timer.C:
+		err = errors.New("bucket store server did not answer in time")
+		conn.close(err)
+		return bucketStoreFrame{}, err
+	}
+}
+
+func (c *BucketStoreClient) Reserve(ctx context.Context, route string, majorParams string) (time.Time, error) {
+	rs, err := c.request(ctx, bucketStoreFrame{Op: bucketStoreOpReserve, Route: route, MajorParams: majorParams})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if rs.ResetAfter <= 0 {
+		return time.Time{}, nil
+	}
+	return time.Now().Add(rs.ResetAfter), nil
+}
+
+func (c *BucketStoreClient) Update(ctx context.Context, route string, majorParams string, bucket Bucket) error {
+	return c.send(ctx, bucketStoreFrame{
+		Op:          bucketStoreOpUpdate,
+		Route:       route,
+		MajorParams: majorParams,
+		Bucket: &bucketStoreBucket{
+			ID:         bucket.ID,
+			Limit:      bucket.Limit,
+			Remaining:  bucket.Remaining,
+			ResetAfter: time.Until(bucket.Reset),
+		},
+	})
+}
+
+func (c *BucketStoreClient) SetGlobalReset(ctx context.Context, reset time.Time) error {
+	return c.send(ctx, bucketStoreFrame{Op: bucketStoreOpGlobal, ResetAfter: time.Until(reset)})
+}
+
+func (c *BucketStoreClient) Cleanup(ctx context.Context) error {
+	return c.send(ctx, bucketStoreFrame{Op: bucketStoreOpCleanup})
+}
+
+func (c *BucketStoreClient) Snapshot(ctx context.Context) (BucketStoreSnapshot, error) {
+	rs, err := c.request(ctx, bucketStoreFrame{Op: bucketStoreOpSnapshot})
+	if err != nil {
+		return BucketStoreSnapshot{}, err
+	}
+	if rs.Snapshot == nil {
+		return BucketStoreSnapshot{}, errors.New("bucket store server sent no snapshot")
+	}
+	now := time.Now()
+	snapshot := BucketStoreSnapshot{
+		Hashes:  rs.Snapshot.Hashes,
+		Buckets: make(map[string]Bucket, len(rs.Snapshot.Buckets)),
+	}
+	if rs.Snapshot.GlobalResetAfter > 0 {
+		snapshot.Global = now.Add(rs.Snapshot.GlobalResetAfter)
+	}
+	for key, bucket := range rs.Snapshot.Buckets {
+		snapshot.Buckets[key] = Bucket{
+			ID:        bucket.ID,
+			Limit:     bucket.Limit,
+			Remaining: bucket.Remaining,
+			Reset:     now.Add(bucket.ResetAfter),
+		}
+	}
+	return snapshot, nil
+}
+
+// Close closes the connection to the BucketStoreServer. The BucketStoreClient can't be used afterwards.
+func (c *BucketStoreClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn != nil {
+		c.conn.close(net.ErrClosed)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"time"
+
+	"github.com/disgoorg/log"
+)
+
+// DefaultBucketStoreConfig is the configuration of the BucketStoreClient & BucketStoreServer which is used by default.
+func DefaultBucketStoreConfig() *BucketStoreConfig {
+	return &BucketStoreConfig{
+		Logger:       log.Default(),
+		Timeout:      5 * time.Second,
+		PingInterval: 5 * time.Second,
+	}
+}
+
+// BucketStoreConfig is the configuration of the BucketStoreClient & BucketStoreServer.
+type BucketStoreConfig struct {
+	Logger log.Logger
+	// Timeout is how long the BucketStoreClient waits for an answer of the BucketStoreServer before it considers the connection dead.
+	Timeout time.Duration
+	// PingInterval is how often the BucketStoreClient pings the BucketStoreServer. Connections which are silent for 3 PingIntervals are closed on both sides.
+	PingInterval time.Duration
+}
+
+// BucketStoreConfigOpt can be used to supply optional parameters to NewBucketStoreClient & ListenBucketStore.
+type BucketStoreConfigOpt func(config *BucketStoreConfig)
+
+// Apply applies the given BucketStoreConfigOpt(s) to the BucketStoreConfig.
+func (c *BucketStoreConfig) Apply(opts []BucketStoreConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithBucketStoreLogger applies a custom logger to the BucketStoreClient or BucketStoreServer.
+func WithBucketStoreLogger(logger log.Logger) BucketStoreConfigOpt {
+	return func(config *BucketStoreConfig) {
+		config.Logger = logger
+	}
+}
+
+// WithBucketStoreTimeout sets how long the BucketStoreClient waits for an answer of the BucketStoreServer.
+func WithBucketStoreTimeout(timeout time.Duration) BucketStoreConfigOpt {
+	return func(config *BucketStoreConfig) {
+		config.Timeout = timeout
+	}
+}
+
+// WithBucketStorePingInterval sets how often the BucketStoreClient pings the BucketStoreServer to detect dead connections.
+func WithBucketStorePingInterval(pingInterval time.Duration) BucketStoreConfigOpt {
+	return func(config *BucketStoreConfig) {
+		config.PingInterval = pingInterval
+	}
+}
@@ -0,0 +1,247 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+)
+
+type bucketStoreOp string
+
+const (
+	bucketStoreOpReserve  bucketStoreOp = "reserve"
+	bucketStoreOpUpdate   bucketStoreOp = "update"
+	bucketStoreOpGlobal   bucketStoreOp = "global"
+	bucketStoreOpCleanup  bucketStoreOp = "cleanup"
+	bucketStoreOpSnapshot bucketStoreOp = "snapshot"
+	bucketStoreOpPing     bucketStoreOp = "ping"
+)
+
+// bucketStoreFrame is a single newline-delimited JSON message between a BucketStoreClient and a BucketStoreServer.
+// Times are sent relative to now, so the clocks of the processes don't have to be in sync.
+type bucketStoreFrame struct {
+	// ID matches a response to its request. Requests without an ID are not answered, except pings.
+	ID          uint64                    `json:"id,omitempty"`
+	Op          bucketStoreOp             `json:"op,omitempty"`
+	Route       string                    `json:"route,omitempty"`
+	MajorParams string                    `json:"major_params,omitempty"`
+	Bucket      *bucketStoreBucket        `json:"bucket,omitempty"`
+	Snapshot    *bucketStoreFrameSnapshot `json:"snapshot,omitempty"`
+	// ResetAfter is when the global rate limit resets for global requests & when to try again for reserve responses.
+	ResetAfter time.Duration `json:"reset_after,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+type bucketStoreBucket struct {
+	ID         string        `json:"id"`
+	Limit      int           `json:"limit"`
+	Remaining  int           `json:"remaining"`
+	ResetAfter time.Duration `json:"reset_after"`
+}
+
+type bucketStoreFrameSnapshot struct {
+	GlobalResetAfter time.Duration                `json:"global_reset_after"`
+	Hashes           map[string]string            `json:"hashes"`
+	Buckets          map[string]bucketStoreBucket `json:"buckets"`
+}
+
+func marshalBucketStoreFrame(f bucketStoreFrame) ([]byte, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// readBucketStoreFrames calls handleFunc for every frame received until the connection is closed.
+// The connection is considered dead if nothing is received within timeout.
+func readBucketStoreFrames(conn net.Conn, timeout time.Duration, handleFunc func(f bucketStoreFrame)) error {
+	reader := bufio.NewReader(conn)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var f bucketStoreFrame
+			if jErr := json.Unmarshal(line, &f); jErr != nil {
+				return jErr
+			}
+			handleFunc(f)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// ListenBucketStore starts a BucketStoreServer listening on the given address which shares the given BucketStore. If it is nil, a new NewMemoryBucketStore() is used.
+// Point the BucketStoreClient(s) of all processes using the same token to it.
+func ListenBucketStore(addr string, store BucketStore, opts ...BucketStoreConfigOpt) (*BucketStoreServer, error) {
+	config := DefaultBucketStoreConfig()
+	config.Apply(opts)
+
+	if store == nil {
+		store = NewMemoryBucketStore()
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &BucketStoreServer{
+		config:   *config,
+		store:    store,
+		listener: listener,
+		conns:    map[net.Conn]struct{}{},
+	}
+	go s.accept()
+	return s, nil
+}
+
+// BucketStoreServer is the reference implementation of a BucketStore shared between multiple processes over TCP.
+// Connections of BucketStoreClient(s) which are silent for 3 PingIntervals are closed.
+type BucketStoreServer struct {
+	config   BucketStoreConfig
+	store    BucketStore
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// Addr returns the address the BucketStoreServer listens on.
+func (s *BucketStoreServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *BucketStoreServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.config.Logger.Error("failed to accept bucket store client: ", err)
+			}
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.handleConn(conn)
+	}
+}
+
+func (s *BucketStoreServer) handleConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	// frames of a connection are handled in order, so an update is always applied before a later reserve of the same client
+	if err := readBucketStoreFrames(conn, 3*s.config.PingInterval, func(f bucketStoreFrame) {
+		rs, err := s.handle(f)
+		if err != nil {
+			s.config.Logger.Debugf("failed to handle bucket store %s request: %s", f.Op, err)
+			rs.Error = err.Error()
+		}
+		if f.ID == 0 && f.Op != bucketStoreOpPing {
+			return
+		}
+		rs.ID = f.ID
+		data, err := marshalBucketStoreFrame(rs)
+		if err != nil {
+			s.config.Logger.Error("failed to marshal bucket store response: ", err)
+			return
+		}
+		if err = conn.SetWriteDeadline(time.Now().Add(s.config.Timeout)); err == nil {
+			_, err = conn.Write(data)
+		}
+		if err != nil {
+			s.config.Logger.Error("failed to write bucket store response, disconnecting client: ", err)
+			_ = conn.Close()
+		}
+	}); err != nil {
+		s.config.Logger.Debug("bucket store client disconnected: ", err)
+	}
+}
+
+func (s *BucketStoreServer) handle(f bucketStoreFrame) (bucketStoreFrame, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	switch f.Op {
+	case bucketStoreOpPing:
+		return bucketStoreFrame{}, nil
+
+	case bucketStoreOpReserve:
+		until, err := s.store.Reserve(ctx, f.Route, f.MajorParams)
+		if err != nil || until.IsZero() {
+			return bucketStoreFrame{}, err
+		}
+		return bucketStoreFrame{ResetAfter: time.Until(until)}, nil
+
+	case bucketStoreOpUpdate:
+		if f.Bucket == nil {
+			return bucketStoreFrame{}, errors.New("missing bucket")
+		}
+		return bucketStoreFrame{}, s.store.Update(ctx, f.Route, f.MajorParams, Bucket{
+			ID:        f.Bucket.ID,
+			Limit:     f.Bucket.Limit,
+			Remaining: f.Bucket.Remaining,
+			Reset:     time.Now().Add(f.Bucket.ResetAfter),
+		})
+
+	case bucketStoreOpGlobal:
+		return bucketStoreFrame{}, s.store.SetGlobalReset(ctx, time.Now().Add(f.ResetAfter))
+
+	case bucketStoreOpCleanup:
+		return bucketStoreFrame{}, s.store.Cleanup(ctx)
+
+	case bucketStoreOpSnapshot:
+		snapshot, err := s.store.Snapshot(ctx)
+		if err != nil {
+			return bucketStoreFrame{}, err
+		}
+		buckets := make(map[string]bucketStoreBucket, len(snapshot.Buckets))
+		for key, bucket := range snapshot.Buckets {
+			buckets[key] = bucketStoreBucket{
+				ID:         bucket.ID,
+				Limit:      bucket.Limit,
+				Remaining:  bucket.Remaining,
+				ResetAfter: time.Until(bucket.Reset),
+			}
+		}
+		return bucketStoreFrame{Snapshot: &bucketStoreFrameSnapshot{
+			GlobalResetAfter: time.Until(snapshot.Global),
+			Hashes:           snapshot.Hashes,
+			Buckets:          buckets,
+		}}, nil
+
+	default:
+		return bucketStoreFrame{}, errors.New("unknown op " + string(f.Op))
+	}
+}
+
+// Close stops listening and disconnects all BucketStoreClient(s).
+func (s *BucketStoreServer) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+		delete(s.conns, conn)
+	}
+	s.mu.Unlock()
+	return err
+}
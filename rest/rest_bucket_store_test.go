@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedBucketStore(t *testing.T) {
+	server, err := ListenBucketStore("127.0.0.1:0", nil)
+	require.NoError(t, err)
+	defer server.Close()
+
+	client1 := NewBucketStoreClient(server.Addr().String())
+	defer client1.Close()
+	client2 := NewBucketStoreClient(server.Addr().String())
+	defer client2.Close()
+
+	rateLimiter1 := NewRateLimiter(WithBucketStore(client1))
+	rateLimiter2 := NewRateLimiter(WithBucketStore(client2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	endpoint := GetChannel.Compile(nil, 1)
+
+	assert.NoError(t, rateLimiter1.WaitBucket(ctx, endpoint))
+	assert.NoError(t, rateLimiter1.UnlockBucket(endpoint, &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"X-Ratelimit-Bucket":      []string{"abc"},
+			"X-Ratelimit-Limit":       []string{"1"},
+			"X-Ratelimit-Remaining":   []string{"0"},
+			"X-Ratelimit-Reset-After": []string{"1"},
+		},
+	}))
+	// updates are sent without waiting, a request on the same connection waits until they are applied
+	_, err = client1.Snapshot(ctx)
+	require.NoError(t, err)
+
+	// the other process has to wait for the bucket to reset
+	start := time.Now()
+	assert.NoError(t, rateLimiter2.WaitBucket(ctx, endpoint))
+	assert.NoError(t, rateLimiter2.UnlockBucket(endpoint, nil))
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+
+	// the global rate limit applies to all routes of all processes
+	assert.NoError(t, rateLimiter1.WaitBucket(ctx, GetUser.Compile(nil, 1)))
+	assert.NoError(t, rateLimiter1.UnlockBucket(GetUser.Compile(nil, 1), &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header: http.Header{
+			"X-Ratelimit-Bucket": []string{"def"},
+			"X-Ratelimit-Global": []string{"true"},
+			"Retry-After":        []string{"1"},
+		},
+	}))
+	_, err = client1.Snapshot(ctx)
+	require.NoError(t, err)
+
+	start = time.Now()
+	assert.NoError(t, rateLimiter2.WaitBucket(ctx, GetGuild.Compile(nil, 1)))
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestBucketStoreClientReconnect(t *testing.T) {
+	server, err := ListenBucketStore("127.0.0.1:0", nil)
+	require.NoError(t, err)
+	addr := server.Addr().String()
+
+	client := NewBucketStoreClient(addr, WithBucketStoreTimeout(time.Second))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.Reserve(ctx, "GET+/users/{user.id}", "")
+	assert.NoError(t, err)
+
+	// requests fail while the server is down
+	require.NoError(t, server.Close())
+	_, err = client.Reserve(ctx, "GET+/users/{user.id}", "")
+	assert.Error(t, err)
+
+	// and succeed again once it is back
+	server, err = ListenBucketStore(addr, nil)
+	require.NoError(t, err)
+	defer server.Close()
+	_, err = client.Reserve(ctx, "GET+/users/{user.id}", "")
+	assert.NoError(t, err)
+}
+
+var errBucketStoreUnavailable = errors.New("unavailable")
+
+type failingBucketStore struct{}
+
+func (failingBucketStore) Reserve(context.Context, string, string) (time.Time, error) {
+	return time.Time{}, errBucketStoreUnavailable
+}
+
+func (failingBucketStore) Update(context.Context, string, string, Bucket) error {
+	return errBucketStoreUnavailable
+}
+
+func (failingBucketStore) SetGlobalReset(context.Context, time.Time) error {
+	return errBucketStoreUnavailable
+}
+
+func (failingBucketStore) Cleanup(context.Context) error {
+	return errBucketStoreUnavailable
+}
+
+func (failingBucketStore) Snapshot(context.Context) (BucketStoreSnapshot, error) {
+	return BucketStoreSnapshot{}, errBucketStoreUnavailable
+}
+
+func TestRateLimiterBucketStoreFallback(t *testing.T) {
+	rateLimiter := NewRateLimiter(WithBucketStore(failingBucketStore{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	endpoint := GetChannel.Compile(nil, 1)
+
+	// requests are not failed while the store is unavailable
+	assert.NoError(t, rateLimiter.WaitBucket(ctx, endpoint))
+	assert.NoError(t, rateLimiter.UnlockBucket(endpoint, &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"X-Ratelimit-Bucket":      []string{"abc"},
+			"X-Ratelimit-Limit":       []string{"1"},
+			"X-Ratelimit-Remaining":   []string{"0"},
+			"X-Ratelimit-Reset-After": []string{"1"},
+		},
+	}))
+
+	// but still limited by the rate limits known locally
+	start := time.Now()
+	assert.NoError(t, rateLimiter.WaitBucket(ctx, endpoint))
+	assert.NoError(t, rateLimiter.UnlockBucket(endpoint, nil))
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+}
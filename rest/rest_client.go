@@ -60,7 +60,7 @@ func (c *clientImpl) RateLimiter() RateLimiter {
 	return c.config.RateLimiter
 }
 
-func (c *clientImpl) retry(endpoint *CompiledEndpoint, rqBody any, rsBody any, tries int, opts []RequestOpt) error {
+func (c *clientImpl) retry(endpoint *CompiledEndpoint, rqBody any, rsBody any, tries int, attempts []Attempt, opts []RequestOpt) error {
 	var (
 		rawRqBody   []byte
 		err         error
@@ -114,41 +114,39 @@ func (c *clientImpl) retry(endpoint *CompiledEndpoint, rqBody any, rsBody any, t
 		}
 	}
 
-	// wait for rate limits
-	err = c.RateLimiter().WaitBucket(config.Ctx, endpoint)
-	if err != nil {
-		return fmt.Errorf("error locking bucket in rest client: %w", err)
+	request := &Request{
+		Endpoint: endpoint,
+		Request:  config.Request.WithContext(config.Ctx),
+		Body:     rawRqBody,
+		Try:      tries,
+		Retries:  len(attempts) - rateLimitedAttempts(attempts),
 	}
-	rq = rq.WithContext(config.Ctx)
-
-	for _, check := range config.Checks {
-		if !check() {
-			_ = c.RateLimiter().UnlockBucket(endpoint, nil)
-			return discord.ErrCheckFailed
-		}
+	rs, err := chainMiddlewares(c.doer(config), c.config.Middlewares).Do(request)
+	if err == nil && (rs == nil || rs.Response == nil) {
+		// a Middleware answered the request without a response
+		return discord.ErrNoResponse
 	}
-
-	rs, err := c.HTTPClient().Do(config.Request)
-	if err != nil {
-		_ = c.RateLimiter().UnlockBucket(endpoint, nil)
-		return fmt.Errorf("error doing request in rest client: %w", err)
+	attempt := Attempt{Try: tries, Err: err}
+	if rs != nil && rs.Response != nil {
+		attempt.StatusCode = rs.Response.StatusCode
 	}
 
-	if err = c.RateLimiter().UnlockBucket(endpoint, rs); err != nil {
-		return fmt.Errorf("error unlocking bucket in rest client: %w", err)
-	}
-
-	var rawRsBody []byte
-	if rs.Body != nil {
-		if rawRsBody, err = io.ReadAll(rs.Body); err != nil {
-			return fmt.Errorf("error reading response body in rest client: %w", err)
+	if err != nil {
+		if c.config.RetryPolicy != nil {
+			if delay, ok := c.config.RetryPolicy.Retry(request, nil, err); ok {
+				return c.retryAfter(config.Ctx, request, endpoint, rqBody, rsBody, tries, append(attempts, attempt), delay, opts)
+			}
 		}
-		c.config.Logger.Tracef("response from %s, code %d, body: %s", endpoint.URL, rs.StatusCode, string(rawRsBody))
+		if len(attempts) > 0 {
+			return Error{Request: request.Request, RqBody: rawRqBody, Err: err, Attempts: append(attempts, attempt)}
+		}
+		return err
 	}
+	rq, rawRsBody := request.Request, rs.Body
 
-	switch rs.StatusCode {
+	switch rs.Response.StatusCode {
 	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
-		if rsBody != nil && rs.Body != nil {
+		if rsBody != nil && len(rawRsBody) > 0 {
 			if err = json.Unmarshal(rawRsBody, rsBody); err != nil {
 				wErr := fmt.Errorf("error unmarshalling response body: %w", err)
 				c.config.Logger.Error(wErr)
@@ -158,16 +156,92 @@ func (c *clientImpl) retry(endpoint *CompiledEndpoint, rqBody any, rsBody any, t
 		return nil
 
 	case http.StatusTooManyRequests:
-		if tries >= c.RateLimiter().MaxRetries() {
-			return NewError(rq, rawRqBody, rs, rawRsBody)
+		attempts = append(attempts, attempt)
+		if rateLimitedAttempts(attempts) >= c.RateLimiter().MaxRetries() {
+			return newErrorWithAttempts(rq, rawRqBody, rs, attempts)
 		}
-		return c.retry(endpoint, rqBody, rsBody, tries+1, opts)
+		return c.retry(endpoint, rqBody, rsBody, tries+1, attempts, opts)
 
 	default:
-		return NewError(rq, rawRqBody, rs, rawRsBody)
+		if c.config.RetryPolicy != nil {
+			if delay, ok := c.config.RetryPolicy.Retry(request, rs, nil); ok {
+				return c.retryAfter(config.Ctx, request, endpoint, rqBody, rsBody, tries, append(attempts, attempt), delay, opts)
+			}
+		}
+		return newErrorWithAttempts(rq, rawRqBody, rs, append(attempts, attempt))
 	}
 }
 
+// retryAfter waits for the given delay and retries the request. The delay is recorded on the last Attempt.
+// If the context is done while waiting, an Error with all attempts is returned.
+func (c *clientImpl) retryAfter(ctx context.Context, request *Request, endpoint *CompiledEndpoint, rqBody any, rsBody any, tries int, attempts []Attempt, delay time.Duration, opts []RequestOpt) error {
+	attempts[len(attempts)-1].Delay = delay
+	c.config.Logger.Debugf("retrying request to %s in %s after try %d", endpoint.URL, delay, tries)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return Error{Request: request.Request, RqBody: request.Body, Err: ctx.Err(), Attempts: attempts}
+	case <-timer.C:
+	}
+	return c.retry(endpoint, rqBody, rsBody, tries+1, attempts, opts)
+}
+
+// rateLimitedAttempts returns how many of the given attempts were answered with 429 Too Many Requests.
+func rateLimitedAttempts(attempts []Attempt) int {
+	var n int
+	for _, attempt := range attempts {
+		if attempt.StatusCode == http.StatusTooManyRequests {
+			n++
+		}
+	}
+	return n
+}
+
+func newErrorWithAttempts(rq *http.Request, rqBody []byte, rs *Response, attempts []Attempt) error {
+	err := NewError(rq, rqBody, rs.Response, rs.Body).(Error)
+	err.Attempts = attempts
+	return err
+}
+
+// doer returns the innermost Doer of the Middleware chain, which waits for the RateLimiter, runs the Check(s) and sends the request.
+func (c *clientImpl) doer(config *RequestConfig) Doer {
+	return DoerFunc(func(rq *Request) (*Response, error) {
+		// wait for rate limits
+		if err := c.RateLimiter().WaitBucket(rq.Request.Context(), rq.Endpoint); err != nil {
+			return nil, fmt.Errorf("error locking bucket in rest client: %w", err)
+		}
+
+		for _, check := range config.Checks {
+			if !check() {
+				_ = c.RateLimiter().UnlockBucket(rq.Endpoint, nil)
+				return nil, discord.ErrCheckFailed
+			}
+		}
+
+		rs, err := c.HTTPClient().Do(rq.Request)
+		if err != nil {
+			_ = c.RateLimiter().UnlockBucket(rq.Endpoint, nil)
+			return nil, fmt.Errorf("error doing request in rest client: %w", err)
+		}
+
+		if err = c.RateLimiter().UnlockBucket(rq.Endpoint, rs); err != nil {
+			return nil, fmt.Errorf("error unlocking bucket in rest client: %w", err)
+		}
+
+		var rawRsBody []byte
+		if rs.Body != nil {
+			defer rs.Body.Close()
+			if rawRsBody, err = io.ReadAll(rs.Body); err != nil {
+				return nil, fmt.Errorf("error reading response body in rest client: %w", err)
+			}
+			c.config.Logger.Tracef("response from %s, code %d, body: %s", rq.Endpoint.URL, rs.StatusCode, string(rawRsBody))
+		}
+		return &Response{Response: rs, Body: rawRsBody}, nil
+	})
+}
+
 func (c *clientImpl) Do(endpoint *CompiledEndpoint, rqBody any, rsBody any, opts ...RequestOpt) error {
-	return c.retry(endpoint, rqBody, rsBody, 1, opts)
+	return c.retry(endpoint, rqBody, rsBody, 1, nil, opts)
 }
@@ -11,9 +11,10 @@ import (
 // DefaultConfig is the configuration which is used by default
 func DefaultConfig() *Config {
 	return &Config{
-		Logger:     log.Default(),
-		HTTPClient: &http.Client{Timeout: 20 * time.Second},
-		URL:        fmt.Sprintf("%sv%d", API, Version),
+		Logger:      log.Default(),
+		HTTPClient:  &http.Client{Timeout: 20 * time.Second},
+		URL:         fmt.Sprintf("%sv%d", API, Version),
+		RetryPolicy: DefaultRetryPolicy(),
 	}
 }
 
@@ -25,6 +26,8 @@ type Config struct {
 	RateRateLimiterConfigOpts []RateLimiterConfigOpt
 	URL                       string
 	UserAgent                 string
+	Middlewares               []Middleware
+	RetryPolicy               RetryPolicy
 }
 
 // ConfigOpt can be used to supply optional parameters to NewClient
@@ -81,3 +84,17 @@ func WithUserAgent(userAgent string) ConfigOpt {
 		config.UserAgent = userAgent
 	}
 }
+
+// WithMiddlewares adds Middleware(s) to the chain every request passes through. The first Middleware is the outermost.
+func WithMiddlewares(middlewares ...Middleware) ConfigOpt {
+	return func(config *Config) {
+		config.Middlewares = append(config.Middlewares, middlewares...)
+	}
+}
+
+// WithRetryPolicy sets the RetryPolicy which decides which failed requests are retried. Defaults to DefaultRetryPolicy(), nil disables retries.
+func WithRetryPolicy(retryPolicy RetryPolicy) ConfigOpt {
+	return func(config *Config) {
+		config.RetryPolicy = retryPolicy
+	}
+}
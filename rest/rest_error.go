@@ -3,6 +3,7 @@ package rest
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/disgoorg/json"
 )
@@ -11,6 +12,18 @@ import (
 // See https://discord.com/developers/docs/topics/opcodes-and-status-codes#json-json-error-codes
 type JSONErrorCode int
 
+// Attempt is a single try of a request.
+type Attempt struct {
+	// Try is the number of the try, starting at 1.
+	Try int
+	// StatusCode is the status code of the response or 0 if there was none.
+	StatusCode int
+	// Err is the error which occurred while doing the request.
+	Err error
+	// Delay is how long the Client waited before the next try.
+	Delay time.Duration
+}
+
 var _ error = (*Error)(nil)
 
 // Error holds the http.Response & an error related to a REST request
@@ -19,6 +32,10 @@ type Error struct {
 	RqBody   []byte         `json:"-"`
 	Response *http.Response `json:"-"`
 	RsBody   []byte         `json:"-"`
+	// Err is the error of the last try if it failed without a response. This is only set if the request was retried.
+	Err error `json:"-"`
+	// Attempts are all tries of the request.
+	Attempts []Attempt `json:"-"`
 
 	Code    JSONErrorCode   `json:"code"`
 	Errors  json.RawMessage `json:"errors"`
@@ -55,9 +72,17 @@ func (e Error) Error() string {
 	if e.Code != 0 {
 		return fmt.Sprintf("%d: %s", e.Code, e.Message)
 	}
+	if e.Response == nil {
+		return fmt.Sprintf("failed after %d attempts: %s", len(e.Attempts), e.Err)
+	}
 	return fmt.Sprintf("Status: %s, Body: %s", e.Response.Status, string(e.RsBody))
 }
 
+// Unwrap returns the error of the last try if it failed without a response
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
 // Error returns the error formatted as string
 func (e Error) String() string {
 	return e.Error()
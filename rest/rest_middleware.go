@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"net/http"
+)
+
+// Request is a request to a CompiledEndpoint which is passed through the Middleware(s) of the Client.
+type Request struct {
+	// Endpoint is the CompiledEndpoint of the request.
+	// Endpoint.Endpoint.Route is the route template and Endpoint.MajorParams are the major parameters of the request.
+	Endpoint *CompiledEndpoint
+	// Request is the http.Request which is sent. Its context is the context of the request.
+	Request *http.Request
+	// Body is the raw request body. Changing it has no effect, replace the body of the http.Request instead.
+	Body []byte
+	// Try is the number of the current try, starting at 1.
+	Try int
+	// Retries is the number of previous tries which were retried by the RetryPolicy. Retries after a 429 are not counted.
+	Retries int
+}
+
+// Response is the response to a Request.
+type Response struct {
+	// Response is the http.Response to the Request. Its body is already read into Body.
+	Response *http.Response
+	// Body is the raw response body. It is decoded into the response of the request, the body of the http.Response is not read again.
+	Body []byte
+}
+
+// Doer does a Request and returns its Response.
+type Doer interface {
+	Do(rq *Request) (*Response, error)
+}
+
+// DoerFunc is a function which implements Doer.
+type DoerFunc func(rq *Request) (*Response, error)
+
+// Do calls the DoerFunc.
+func (f DoerFunc) Do(rq *Request) (*Response, error) {
+	return f(rq)
+}
+
+// Middleware wraps the next Doer in the chain.
+// It can inspect or modify the Request before calling next and inspect or replace the Response after, or answer the Request without calling next at all.
+// A Response which answers the Request has to contain an http.Response, otherwise the Client returns discord.ErrNoResponse.
+// The innermost Doer waits for the RateLimiter, runs the Check(s) and sends the request with the http.Client.
+type Middleware func(next Doer) Doer
+
+// chainMiddlewares wraps the given Doer with the given Middleware(s). The first Middleware is the outermost.
+func chainMiddlewares(doer Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+	return doer
+}
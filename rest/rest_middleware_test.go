@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+func TestClientMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test", r.Header.Get("X-Test"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	client := NewClient("token",
+		WithURL(server.URL),
+		WithMiddlewares(
+			func(next Doer) Doer {
+				return DoerFunc(func(rq *Request) (*Response, error) {
+					rs, err := next.Do(rq)
+					calls = append(calls, rq.Endpoint.Endpoint.Route, rq.Endpoint.MajorParams, http.StatusText(rs.Response.StatusCode))
+					return rs, err
+				})
+			},
+			func(next Doer) Doer {
+				return DoerFunc(func(rq *Request) (*Response, error) {
+					rq.Request.Header.Set("X-Test", "test")
+					return next.Do(rq)
+				})
+			},
+		),
+	)
+
+	var rsBody map[string]string
+	assert.NoError(t, client.Do(GetChannel.Compile(nil, 1), nil, &rsBody))
+	assert.Equal(t, "1", rsBody["id"])
+	assert.Equal(t, []string{"/channels/{channel.id}", "channel.id=1", "OK"}, calls)
+}
+
+func TestClientMiddlewareFaultInjection(t *testing.T) {
+	client := NewClient("token",
+		WithURL("http://localhost:0"),
+		WithMiddlewares(func(next Doer) Doer {
+			return DoerFunc(func(rq *Request) (*Response, error) {
+				return &Response{
+					Response: &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(""))},
+				}, nil
+			})
+		}),
+	)
+
+	err := client.Do(GetChannel.Compile(nil, 1), nil, nil)
+	var restErr Error
+	assert.ErrorAs(t, err, &restErr)
+	assert.Equal(t, http.StatusInternalServerError, restErr.Response.StatusCode)
+}
+
+func TestClientMiddlewareNoResponse(t *testing.T) {
+	for _, rs := range []*Response{nil, {}} {
+		client := NewClient("token",
+			WithURL("http://localhost:0"),
+			WithMiddlewares(func(next Doer) Doer {
+				return DoerFunc(func(rq *Request) (*Response, error) {
+					return rs, nil
+				})
+			}),
+		)
+		assert.ErrorIs(t, client.Do(GetChannel.Compile(nil, 1), nil, nil), discord.ErrNoResponse)
+	}
+}
+
+func TestClientMiddlewareSyntheticResponse(t *testing.T) {
+	client := NewClient("token",
+		WithURL("http://localhost:0"),
+		WithMiddlewares(func(next Doer) Doer {
+			return DoerFunc(func(rq *Request) (*Response, error) {
+				// answer from a cache without an http.Response body
+				return &Response{
+					Response: &http.Response{StatusCode: http.StatusOK},
+					Body:     []byte(`{"id":"1"}`),
+				}, nil
+			})
+		}),
+	)
+
+	var rsBody map[string]string
+	assert.NoError(t, client.Do(GetChannel.Compile(nil, 1), nil, &rsBody))
+	assert.Equal(t, "1", rsBody["id"])
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sasha-s/go-csync"
@@ -28,6 +30,42 @@ type RateLimiter interface {
 
 	// UnlockBucket unlocks the given bucket and calculates the rate limit for the next request
 	UnlockBucket(endpoint *CompiledEndpoint, rs *http.Response) error
+
+	// Snapshot returns a read-only copy of the current rate limit state
+	Snapshot(ctx context.Context) (RateLimitSnapshot, error)
+}
+
+// RateLimitSnapshot is a copy of the state of a RateLimiter.
+type RateLimitSnapshot struct {
+	// Global is when the global rate limit resets. It is zero or in the past if there is none.
+	Global time.Time
+	// Buckets are all known buckets sorted by their key.
+	Buckets []BucketSnapshot
+}
+
+// BucketSnapshot is a copy of the state of a Bucket.
+type BucketSnapshot struct {
+	// Key identifies the Bucket. It is the hash Discord reported, or the route as long as the hash is unknown, plus the major parameters.
+	Key string
+	Bucket
+	// Waiters is the number of requests of this process which wait for the Bucket.
+	Waiters int
+}
+
+// RateLimitHit describes a 429 Too Many Requests response.
+type RateLimitHit struct {
+	// Endpoint is the CompiledEndpoint of the request. Endpoint.Endpoint.Route is the route template and Endpoint.MajorParams are the major parameters of the request.
+	Endpoint *CompiledEndpoint
+	// BucketID is the rate limit bucket hash Discord reported. It is empty for rate limits by Cloudflare.
+	BucketID string
+	// Scope is the scope Discord reported: user, global or shared. Shared rate limits do not count against the bot.
+	Scope string
+	// RetryAfter is how long Discord asked to wait before retrying.
+	RetryAfter time.Duration
+	// Global is true if the global rate limit was hit.
+	Global bool
+	// Cloudflare is true if the request was rejected by Cloudflare instead of Discord, for example because of too many invalid requests.
+	Cloudflare bool
 }
 
 // NewRateLimiter return a new default RateLimiter with the given RateLimiterConfigOpt(s).
@@ -37,8 +75,14 @@ func NewRateLimiter(opts ...RateLimiterConfigOpt) RateLimiter {
 
 	rateLimiter := &rateLimiterImpl{
 		config:  *config,
-		hashes:  map[*Endpoint]string{},
+		store:   config.BucketStore,
 		buckets: map[string]*bucket{},
+		closed:  make(chan struct{}),
+	}
+	if rateLimiter.store == nil {
+		rateLimiter.store = NewMemoryBucketStore()
+	} else {
+		rateLimiter.fallback = NewMemoryBucketStore()
 	}
 
 	go rateLimiter.cleanup()
@@ -50,15 +94,18 @@ type (
 	rateLimiterImpl struct {
 		config RateLimiterConfig
 
-		// global Rate Limit
-		global time.Time
+		// store holds the rate limit state which might be shared with other processes
+		store BucketStore
+		// fallback holds the rate limit state known to this process and is used while a configured BucketStore fails
+		fallback BucketStore
 
-		// APIRoute -> Hash
-		hashes   map[*Endpoint]string
-		hashesMu sync.Mutex
-		// Hash + Major Parameter -> bucket
+		// Route + Major Parameter -> bucket
 		buckets   map[string]*bucket
 		bucketsMu sync.Mutex
+
+		// closed stops the cleanup goroutine
+		closeOnce sync.Once
+		closed    chan struct{}
 	}
 )
 
@@ -68,36 +115,60 @@ func (l *rateLimiterImpl) MaxRetries() int {
 
 func (l *rateLimiterImpl) cleanup() {
 	ticker := time.NewTicker(l.config.CleanupInterval)
-	for range ticker.C {
-		l.doCleanup()
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.closed:
+			return
+		case <-ticker.C:
+			l.doCleanup()
+		}
 	}
 }
 
 func (l *rateLimiterImpl) doCleanup() {
 	l.bucketsMu.Lock()
-	defer l.bucketsMu.Unlock()
 	before := len(l.buckets)
-	now := time.Now()
 	for hash, b := range l.buckets {
-		if !b.mu.TryLock() {
+		// skip buckets which are in use or about to be locked by WaitBucket
+		if atomic.LoadInt32(&b.waiters) > 0 || !b.mu.TryLock() {
 			continue
 		}
-		if b.Reset.Before(now) {
-			l.config.Logger.Debugf("cleaning up bucket, Hash: %s, ID: %s, Reset: %s", hash, b.ID, b.Reset)
-			delete(l.buckets, hash)
-		}
+		l.config.Logger.Debugf("cleaning up bucket, Hash: %s", hash)
+		delete(l.buckets, hash)
 		b.mu.Unlock()
 	}
 	if before != len(l.buckets) {
 		l.config.Logger.Debugf("cleaned up %d rate limit buckets", before-len(l.buckets))
 	}
+	l.bucketsMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.CleanupInterval)
+	defer cancel()
+	if err := l.store.Cleanup(ctx); err != nil {
+		l.config.Logger.Error("failed to clean up bucket store: ", err)
+	}
+	if l.fallback != nil {
+		_ = l.fallback.Cleanup(ctx)
+	}
 }
 
 func (l *rateLimiterImpl) Close(ctx context.Context) {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+
+	l.bucketsMu.Lock()
+	buckets := make([]*bucket, 0, len(l.buckets))
+	for _, b := range l.buckets {
+		buckets = append(buckets, b)
+	}
+	l.bucketsMu.Unlock()
+
 	var wg sync.WaitGroup
-	for i := range l.buckets {
+	for i := range buckets {
 		wg.Add(1)
-		b := l.buckets[i]
+		b := buckets[i]
 		go func() {
 			_ = b.mu.CLock(ctx)
 			wg.Done()
@@ -106,31 +177,62 @@ func (l *rateLimiterImpl) Close(ctx context.Context) {
 	wg.Wait()
 }
 
+// Reset resets the rate limiter to its initial state. The state of a configured BucketStore is kept, as other processes might rely on it.
 func (l *rateLimiterImpl) Reset() {
+	l.bucketsMu.Lock()
+	defer l.bucketsMu.Unlock()
 	l.buckets = map[string]*bucket{}
-	l.bucketsMu = sync.Mutex{}
-	l.global = time.Time{}
-	l.hashes = map[*Endpoint]string{}
-	l.hashesMu = sync.Mutex{}
+	if l.config.BucketStore == nil {
+		l.store = NewMemoryBucketStore()
+	} else {
+		l.fallback = NewMemoryBucketStore()
+	}
 }
 
-func (l *rateLimiterImpl) getRouteHash(endpoint *CompiledEndpoint) string {
-	l.hashesMu.Lock()
-	hash, ok := l.hashes[endpoint.Endpoint]
-	if !ok {
-		// generate routeHash
-		hash = endpoint.Endpoint.Method + "+" + endpoint.Endpoint.Route
-		l.hashes[endpoint.Endpoint] = hash
+// reserve reserves a request from the BucketStore. If a configured BucketStore fails, the request is reserved from the fallback instead,
+// so an outage of a shared BucketStore only limits requests by the rate limits this process knows about instead of failing them.
+func (l *rateLimiterImpl) reserve(ctx context.Context, endpoint *CompiledEndpoint) (time.Time, error) {
+	until, err := l.store.Reserve(ctx, routeOf(endpoint), endpoint.MajorParams)
+	if err == nil || l.fallback == nil || ctx.Err() != nil {
+		return until, err
 	}
-	l.hashesMu.Unlock()
-	if endpoint.MajorParams != "" {
-		hash += "+" + endpoint.MajorParams
+	l.config.Logger.Warn("failed to reserve bucket from bucket store, falling back to local rate limits: ", err)
+	return l.fallback.Reserve(ctx, routeOf(endpoint), endpoint.MajorParams)
+}
+
+// update updates the Bucket in the BucketStore & the fallback. Errors of a configured BucketStore are only logged, see reserve.
+func (l *rateLimiterImpl) update(ctx context.Context, endpoint *CompiledEndpoint, bucket Bucket) error {
+	if l.fallback == nil {
+		return l.store.Update(ctx, routeOf(endpoint), endpoint.MajorParams, bucket)
+	}
+	if err := l.store.Update(ctx, routeOf(endpoint), endpoint.MajorParams, bucket); err != nil {
+		l.config.Logger.Warn("failed to update bucket in bucket store: ", err)
+	}
+	return l.fallback.Update(ctx, routeOf(endpoint), endpoint.MajorParams, bucket)
+}
+
+// setGlobalReset sets the global rate limit in the BucketStore & the fallback. Errors of a configured BucketStore are only logged, see reserve.
+func (l *rateLimiterImpl) setGlobalReset(ctx context.Context, reset time.Time) error {
+	if l.fallback == nil {
+		return l.store.SetGlobalReset(ctx, reset)
+	}
+	if err := l.store.SetGlobalReset(ctx, reset); err != nil {
+		l.config.Logger.Warn("failed to set global rate limit in bucket store: ", err)
 	}
-	return hash
+	return l.fallback.SetGlobalReset(ctx, reset)
 }
 
+func routeOf(endpoint *CompiledEndpoint) string {
+	return endpoint.Endpoint.Method + "+" + endpoint.Endpoint.Route
+}
+
+// getBucket returns the bucket of the endpoint. If create is true, a missing bucket is created and its waiters are incremented
+// before the buckets are unlocked, so the cleanup can't remove it before WaitBucket locked it. The caller has to decrement them again.
 func (l *rateLimiterImpl) getBucket(endpoint *CompiledEndpoint, create bool) *bucket {
-	hash := l.getRouteHash(endpoint)
+	hash := routeOf(endpoint)
+	if endpoint.MajorParams != "" {
+		hash += "+" + endpoint.MajorParams
+	}
 
 	l.config.Logger.Trace("locking buckets")
 	l.bucketsMu.Lock()
@@ -145,34 +247,40 @@ func (l *rateLimiterImpl) getBucket(endpoint *CompiledEndpoint, create bool) *bu
 		}
 
 		b = &bucket{
-			Remaining: 1,
-			// we don't know the limit yet
-			Limit: -1,
+			route:       routeOf(endpoint),
+			majorParams: endpoint.MajorParams,
 		}
 		l.buckets[hash] = b
 	}
+	if create {
+		atomic.AddInt32(&b.waiters, 1)
+	}
 	return b
 }
 
 func (l *rateLimiterImpl) WaitBucket(ctx context.Context, endpoint *CompiledEndpoint) error {
 	b := l.getBucket(endpoint, true)
-	l.config.Logger.Tracef("locking rest bucket, ID: %s, Limit: %d, Remaining: %d, Reset: %s", b.ID, b.Limit, b.Remaining, b.Reset)
+	defer atomic.AddInt32(&b.waiters, -1)
+	l.config.Logger.Tracef("locking rest bucket, Route: %s, Major Parameters: %s", routeOf(endpoint), endpoint.MajorParams)
 	if err := b.mu.CLock(ctx); err != nil {
 		return err
 	}
 
-	var until time.Time
-	now := time.Now()
+	for {
+		until, err := l.reserve(ctx, endpoint)
+		if err != nil {
+			b.mu.Unlock()
+			return fmt.Errorf("failed to reserve bucket: %w", err)
+		}
 
-	if b.Remaining == 0 && b.Reset.After(now) {
-		until = b.Reset
-	} else {
-		until = l.global
-	}
+		now := time.Now()
+		if !until.After(now) {
+			return nil
+		}
 
-	if until.After(now) {
 		// TODO: do we want to return early when we know the rate limit bigger than ctx deadline?
 		if deadline, ok := ctx.Deadline(); ok && until.After(deadline) {
+			b.mu.Unlock()
 			return context.DeadlineExceeded
 		}
 
@@ -183,7 +291,6 @@ func (l *rateLimiterImpl) WaitBucket(ctx context.Context, endpoint *CompiledEndp
 		case <-time.After(until.Sub(now)):
 		}
 	}
-	return nil
 }
 
 func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Response) error {
@@ -192,7 +299,7 @@ func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Resp
 		return nil
 	}
 	defer func() {
-		l.config.Logger.Tracef("unlocking rest bucket, ID: %s, Limit: %d, Remaining: %d, Reset: %s", b.ID, b.Limit, b.Remaining, b.Reset)
+		l.config.Logger.Tracef("unlocking rest bucket, Route: %s, Major Parameters: %s", routeOf(endpoint), endpoint.MajorParams)
 		b.mu.Unlock()
 	}()
 
@@ -202,13 +309,11 @@ func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Resp
 	}
 	bucketHeader := rs.Header.Get("X-RateLimit-Bucket")
 
-	// if we don't have a bucket header, we can't update anything
-	if bucketHeader == "" {
+	// if we don't have a bucket header or a rate limit, we can't update anything
+	if bucketHeader == "" && rs.StatusCode != http.StatusTooManyRequests {
 		return nil
 	}
 
-	b.ID = bucketHeader
-
 	global := rs.Header.Get("X-RateLimit-Global") != ""
 	cloudflare := rs.Header.Get("via") == ""
 	remainingHeader := rs.Header.Get("X-RateLimit-Remaining")
@@ -219,6 +324,10 @@ func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Resp
 
 	l.config.Logger.Tracef("code: %d, headers: global %t, cloudflare: %t, remaining: %s, limit: %s, reset: %s, retryAfter: %s", rs.StatusCode, global, cloudflare, remainingHeader, limitHeader, resetHeader, retryAfterHeader)
 
+	// the store might be remote, don't block forever
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
 	// we hit a rate limit. let's see if it was global cloudflare or a route specific one
 	if rs.StatusCode == http.StatusTooManyRequests {
 		retryAfter, err := strconv.Atoi(retryAfterHeader)
@@ -226,26 +335,43 @@ func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Resp
 			return fmt.Errorf("invalid retryAfter %s: %w", retryAfterHeader, err)
 		}
 		reset := time.Now().Add(time.Second * time.Duration(retryAfter))
+		if l.config.RateLimitHitHandlerFunc != nil {
+			l.config.RateLimitHitHandlerFunc(RateLimitHit{
+				Endpoint:   endpoint,
+				BucketID:   bucketHeader,
+				Scope:      rs.Header.Get("X-RateLimit-Scope"),
+				RetryAfter: time.Second * time.Duration(retryAfter),
+				Global:     global,
+				Cloudflare: cloudflare,
+			})
+		}
 		if global {
-			l.global = reset
 			l.config.Logger.Warnf("global rate limit exceeded, retry after: %ds", retryAfter)
+			return l.setGlobalReset(ctx, reset)
 		} else if cloudflare {
-			l.global = reset
 			l.config.Logger.Warnf("cloudflare rate limit exceeded, retry after: %ds", retryAfter)
-		} else {
-			b.Remaining = 0
-			b.Reset = reset
-			l.config.Logger.Warnf("rate limit on route %s exceeded, retry after: %ds", endpoint.URL, retryAfter)
+			return l.setGlobalReset(ctx, reset)
 		}
-		return nil
+		l.config.Logger.Warnf("rate limit on route %s exceeded, retry after: %ds", endpoint.URL, retryAfter)
+		return l.update(ctx, endpoint, Bucket{
+			ID:        bucketHeader,
+			Limit:     -1,
+			Remaining: 0,
+			Reset:     reset,
+		})
 	}
 
+	bucket := Bucket{
+		ID:        bucketHeader,
+		Limit:     -1,
+		Remaining: -1,
+	}
 	if limitHeader != "" {
 		limit, err := strconv.Atoi(limitHeader)
 		if err != nil {
 			return fmt.Errorf("invalid limit %s: %s", limitHeader, err)
 		}
-		b.Limit = limit
+		bucket.Limit = limit
 	}
 
 	if remainingHeader != "" {
@@ -253,7 +379,7 @@ func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Resp
 		if err != nil {
 			return fmt.Errorf("invalid remaining %s: %s", remainingHeader, err)
 		}
-		b.Remaining = remaining
+		bucket.Remaining = remaining
 	}
 
 	// we prioritize the reset after header over the reset header as it's more accurate due to clock differences
@@ -263,7 +389,7 @@ func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Resp
 			return fmt.Errorf("invalid reset after %s: %s", resetAfterHeader, err)
 		}
 
-		b.Reset = time.Now().Add(time.Duration(resetAfter) * time.Second)
+		bucket.Reset = time.Now().Add(time.Duration(resetAfter * float64(time.Second)))
 	} else if resetHeader != "" {
 		reset, err := strconv.ParseFloat(resetHeader, 64)
 		if err != nil {
@@ -271,17 +397,63 @@ func (l *rateLimiterImpl) UnlockBucket(endpoint *CompiledEndpoint, rs *http.Resp
 		}
 
 		sec := int64(reset)
-		b.Reset = time.Unix(sec, int64((reset-float64(sec))*float64(time.Second)))
+		bucket.Reset = time.Unix(sec, int64((reset-float64(sec))*float64(time.Second)))
 	} else {
 		return fmt.Errorf("no reset or reset after header found in response")
 	}
-	return nil
+	return l.update(ctx, endpoint, bucket)
+}
+
+func (l *rateLimiterImpl) Snapshot(ctx context.Context) (RateLimitSnapshot, error) {
+	storeSnapshot, err := l.store.Snapshot(ctx)
+	if err != nil {
+		return RateLimitSnapshot{}, err
+	}
+
+	buckets := make(map[string]*BucketSnapshot, len(storeSnapshot.Buckets))
+	for key, b := range storeSnapshot.Buckets {
+		buckets[key] = &BucketSnapshot{Key: key, Bucket: b}
+	}
+
+	l.bucketsMu.Lock()
+	for _, b := range l.buckets {
+		waiters := int(atomic.LoadInt32(&b.waiters))
+		if waiters == 0 {
+			continue
+		}
+		key, ok := storeSnapshot.Hashes[b.route]
+		if !ok {
+			key = b.route
+		}
+		if b.majorParams != "" {
+			key += "+" + b.majorParams
+		}
+		snapshot, ok := buckets[key]
+		if !ok {
+			snapshot = &BucketSnapshot{Key: key, Bucket: Bucket{Limit: -1}}
+			buckets[key] = snapshot
+		}
+		snapshot.Waiters += waiters
+	}
+	l.bucketsMu.Unlock()
+
+	snapshot := RateLimitSnapshot{
+		Global:  storeSnapshot.Global,
+		Buckets: make([]BucketSnapshot, 0, len(buckets)),
+	}
+	for _, b := range buckets {
+		snapshot.Buckets = append(snapshot.Buckets, *b)
+	}
+	sort.Slice(snapshot.Buckets, func(i, j int) bool {
+		return snapshot.Buckets[i].Key < snapshot.Buckets[j].Key
+	})
+	return snapshot, nil
 }
 
 type bucket struct {
-	mu        csync.Mutex
-	ID        string
-	Reset     time.Time
-	Remaining int
-	Limit     int
+	mu          csync.Mutex
+	route       string
+	majorParams string
+	// waiters is accessed atomically
+	waiters int32
 }
@@ -20,8 +20,14 @@ type RateLimiterConfig struct {
 	Logger          log.Logger
 	MaxRetries      int
 	CleanupInterval time.Duration
+	BucketStore     BucketStore
+
+	RateLimitHitHandlerFunc RateLimitHitHandlerFunc
 }
 
+// RateLimitHitHandlerFunc is called for every 429 Too Many Requests response. It is called synchronously and should not block.
+type RateLimitHitHandlerFunc func(hit RateLimitHit)
+
 // RateLimiterConfigOpt can be used to supply optional parameters to NewRateLimiter.
 type RateLimiterConfigOpt func(config *RateLimiterConfig)
 
@@ -52,3 +58,19 @@ func WithCleanupInterval(cleanupInterval time.Duration) RateLimiterConfigOpt {
 		config.CleanupInterval = cleanupInterval
 	}
 }
+
+// WithBucketStore tells the rest rate limiter to keep its rate limit state in the given BucketStore, which can be shared with other processes.
+// The rate limiter fails open: while the BucketStore returns errors, requests are limited by the rate limits this process knows about only.
+// Defaults to NewMemoryBucketStore().
+func WithBucketStore(bucketStore BucketStore) RateLimiterConfigOpt {
+	return func(config *RateLimiterConfig) {
+		config.BucketStore = bucketStore
+	}
+}
+
+// WithRateLimitHitHandlerFunc sets the RateLimitHitHandlerFunc which is called for every 429 Too Many Requests response, so you can alert on them or find the routes you hit too often.
+func WithRateLimitHitHandlerFunc(rateLimitHitHandlerFunc RateLimitHitHandlerFunc) RateLimiterConfigOpt {
+	return func(config *RateLimiterConfig) {
+		config.RateLimitHitHandlerFunc = rateLimitHitHandlerFunc
+	}
+}
@@ -21,3 +21,7 @@ func (l *noopRateLimiter) Reset() {}
 func (l *noopRateLimiter) WaitBucket(_ context.Context, _ *CompiledEndpoint) error { return nil }
 
 func (l *noopRateLimiter) UnlockBucket(_ *CompiledEndpoint, _ *http.Response) error { return nil }
+
+func (l *noopRateLimiter) Snapshot(_ context.Context) (RateLimitSnapshot, error) {
+	return RateLimitSnapshot{}, nil
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterSnapshot(t *testing.T) {
+	var hits []RateLimitHit
+	rateLimiter := NewRateLimiter(WithRateLimitHitHandlerFunc(func(hit RateLimitHit) {
+		hits = append(hits, hit)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	endpoint := GetChannel.Compile(nil, 1)
+
+	assert.NoError(t, rateLimiter.WaitBucket(ctx, endpoint))
+	assert.NoError(t, rateLimiter.UnlockBucket(endpoint, &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header: http.Header{
+			"Via":                []string{"1.1 google"},
+			"X-Ratelimit-Bucket": []string{"abc"},
+			"X-Ratelimit-Scope":  []string{"user"},
+			"Retry-After":        []string{"1"},
+		},
+	}))
+	assert.Equal(t, []RateLimitHit{{Endpoint: endpoint, BucketID: "abc", Scope: "user", RetryAfter: time.Second}}, hits)
+
+	// lock the bucket and queue another request
+	assert.NoError(t, rateLimiter.WaitBucket(ctx, endpoint))
+	waiting := make(chan error)
+	go func() {
+		waiting <- rateLimiter.WaitBucket(ctx, endpoint)
+	}()
+
+	var snapshot RateLimitSnapshot
+	assert.Eventually(t, func() bool {
+		var err error
+		snapshot, err = rateLimiter.Snapshot(ctx)
+		return err == nil && len(snapshot.Buckets) == 1 && snapshot.Buckets[0].Waiters == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "abc+channel.id=1", snapshot.Buckets[0].Key)
+	assert.Equal(t, "abc", snapshot.Buckets[0].ID)
+	assert.Equal(t, 0, snapshot.Buckets[0].Remaining)
+
+	assert.NoError(t, rateLimiter.UnlockBucket(endpoint, nil))
+	assert.NoError(t, <-waiting)
+	assert.NoError(t, rateLimiter.UnlockBucket(endpoint, nil))
+}
+
+func TestRateLimiterCleanupKeepsWaitedBuckets(t *testing.T) {
+	rateLimiter := NewRateLimiter().(*rateLimiterImpl)
+	defer rateLimiter.Close(context.Background())
+	endpoint := GetChannel.Compile(nil, 1)
+
+	// a bucket fetched by WaitBucket but not locked yet must survive the cleanup
+	b := rateLimiter.getBucket(endpoint, true)
+	rateLimiter.doCleanup()
+	assert.Same(t, b, rateLimiter.getBucket(endpoint, false))
+
+	atomic.AddInt32(&b.waiters, -1)
+	rateLimiter.doCleanup()
+	assert.Nil(t, rateLimiter.getBucket(endpoint, false))
+}
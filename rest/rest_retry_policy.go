@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/disgoorg/json"
+)
+
+// RetryPolicy decides whether a failed request is retried and how long the Client waits before.
+// 429 Too Many Requests are always retried according to the RateLimiter and never passed to the RetryPolicy.
+type RetryPolicy interface {
+	// Retry is called with the Response or the error of a failed Request. Request.Retries is the number of tries already retried by the RetryPolicy, 429 retries are not counted.
+	// It returns how long the Client should wait before the next try. If false is returned, the request is not retried.
+	Retry(rq *Request, rs *Response, err error) (time.Duration, bool)
+}
+
+var _ RetryPolicy = (*BackoffRetryPolicy)(nil)
+
+// DefaultRetryPolicy returns the BackoffRetryPolicy used by default.
+// It retries idempotent requests up to 3 times on 502, 503 & 504 and transient network errors with delays doubling from 500 milliseconds up to 10 seconds and 50% jitter.
+func DefaultRetryPolicy() *BackoffRetryPolicy {
+	return &BackoffRetryPolicy{
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.5,
+		MaxRetries:  3,
+		StatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		IdempotentMethods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodOptions,
+			http.MethodPut,
+			http.MethodDelete,
+		},
+	}
+}
+
+// BackoffRetryPolicy is a RetryPolicy with exponential backoff and jitter.
+// Requests with other methods than IdempotentMethods are only retried if their body contains an enforced nonce, as Discord might have processed them already.
+type BackoffRetryPolicy struct {
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay is the maximum delay between two tries.
+	MaxDelay time.Duration
+	// Jitter is the fraction of the delay which is randomized, between 0 and 1.
+	Jitter float64
+	// MaxRetries is the maximum number of retries of a request, not counting 429 retries.
+	MaxRetries int
+	// StatusCodes are the response status codes which are retried.
+	StatusCodes []int
+	// IdempotentMethods are the http methods which are safe to retry without a nonce.
+	IdempotentMethods []string
+}
+
+func (p *BackoffRetryPolicy) Retry(rq *Request, rs *Response, err error) (time.Duration, bool) {
+	if rq.Retries >= p.MaxRetries || rq.Request.Context().Err() != nil {
+		return 0, false
+	}
+	if rs != nil && !p.retryStatusCode(rs.Response.StatusCode) {
+		return 0, false
+	}
+	if err != nil && !IsTransientError(err) {
+		return 0, false
+	}
+	if !p.idempotent(rq) {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	for i := 0; i < rq.Retries && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay, true
+}
+
+func (p *BackoffRetryPolicy) retryStatusCode(statusCode int) bool {
+	for _, code := range p.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *BackoffRetryPolicy) idempotent(rq *Request) bool {
+	for _, method := range p.IdempotentMethods {
+		if method == rq.Request.Method {
+			return true
+		}
+	}
+	return HasNonce(rq)
+}
+
+// IsTransientError returns whether the given error of a request is a network error which might not happen again, like a reset connection or a timeout.
+func IsTransientError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// HasNonce returns whether the json body or the payload_json of the multipart body of the Request contains a nonce with enforce_nonce set.
+// Discord only deduplicates requests by their nonce if enforce_nonce is true.
+func HasNonce(rq *Request) bool {
+	payload := rq.Body
+	mediaType, params, _ := mime.ParseMediaType(rq.Request.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+	case "multipart/form-data":
+		payload = nil
+		reader := multipart.NewReader(bytes.NewReader(rq.Body), params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			if part.FormName() == "payload_json" {
+				payload, _ = io.ReadAll(part)
+				break
+			}
+		}
+	default:
+		return false
+	}
+
+	var v struct {
+		Nonce        json.RawMessage `json:"nonce"`
+		EnforceNonce bool            `json:"enforce_nonce"`
+	}
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return false
+	}
+	nonce := string(v.Nonce)
+	return v.EnforceNonce && nonce != "" && nonce != "null" && nonce != `""`
+}
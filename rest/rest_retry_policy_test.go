@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRetryPolicy(t *testing.T) {
+	var requests, unavailable int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1)%3 != 0 || atomic.LoadInt32(&unavailable) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	retryPolicy := DefaultRetryPolicy()
+	retryPolicy.BaseDelay = time.Millisecond
+	retryPolicy.MaxRetries = 2
+	client := NewClient("token", WithURL(server.URL), WithRetryPolicy(retryPolicy))
+
+	// idempotent requests are retried
+	assert.NoError(t, client.Do(GetChannel.Compile(nil, 1), nil, nil))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// requests without nonce are not retried
+	err := client.Do(CreateMessage.Compile(nil, 1), map[string]string{"content": "test"}, nil)
+	var restErr Error
+	assert.ErrorAs(t, err, &restErr)
+	assert.Equal(t, []Attempt{{Try: 1, StatusCode: http.StatusServiceUnavailable}}, restErr.Attempts)
+
+	// requests with a nonce which is not enforced are not retried
+	atomic.StoreInt32(&requests, 0)
+	err = client.Do(CreateMessage.Compile(nil, 1), map[string]any{"content": "test", "nonce": "1"}, nil)
+	assert.ErrorAs(t, err, &restErr)
+	assert.Len(t, restErr.Attempts, 1)
+
+	// requests with an enforced nonce are retried
+	atomic.StoreInt32(&requests, 0)
+	assert.NoError(t, client.Do(CreateMessage.Compile(nil, 1), map[string]any{"content": "test", "nonce": "1", "enforce_nonce": true}, nil))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// all attempts are recorded when giving up
+	atomic.StoreInt32(&unavailable, 1)
+	err = client.Do(GetChannel.Compile(nil, 1), nil, nil)
+	assert.ErrorAs(t, err, &restErr)
+	if assert.Len(t, restErr.Attempts, 3) {
+		assert.Equal(t, http.StatusServiceUnavailable, restErr.Attempts[2].StatusCode)
+		assert.NotZero(t, restErr.Attempts[0].Delay)
+	}
+}
+
+func TestClientRetryPolicyRateLimitBudget(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1, 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message":"rate limited","retry_after":0,"global":false}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	retryPolicy := DefaultRetryPolicy()
+	retryPolicy.BaseDelay = time.Millisecond
+	retryPolicy.MaxRetries = 2
+	client := NewClient("token", WithURL(server.URL), WithRetryPolicy(retryPolicy))
+
+	// the 429 does not use up a retry of the RetryPolicy
+	assert.NoError(t, client.Do(GetChannel.Compile(nil, 1), nil, nil))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+}
+
+func TestClientRetryPolicyCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	retryPolicy := DefaultRetryPolicy()
+	retryPolicy.BaseDelay = time.Minute
+	retryPolicy.MaxDelay = time.Minute
+	retryPolicy.Jitter = 0
+	client := NewClient("token", WithURL(server.URL), WithRetryPolicy(retryPolicy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := client.Do(GetChannel.Compile(nil, 1), nil, nil, WithCtx(ctx))
+	var restErr Error
+	assert.ErrorAs(t, err, &restErr)
+	assert.ErrorIs(t, restErr.Err, context.DeadlineExceeded)
+	assert.Equal(t, []Attempt{{Try: 1, StatusCode: http.StatusServiceUnavailable, Delay: time.Minute}}, restErr.Attempts)
+}
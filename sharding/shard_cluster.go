@@ -0,0 +1,59 @@
+package sharding
+
+import (
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// ClusterManager is a ShardManager which groups its shards into Cluster(s) inside one process.
+// Every Cluster has its own ShardManager and its own event dispatch goroutines, so a busy Cluster can't starve the others.
+// Closing a Cluster does not wait for its queued events to be dispatched, so event handlers are free to close it.
+// Create one with NewClusterManager.
+type ClusterManager interface {
+	ShardManager
+
+	// Clusters returns a copy of all Cluster(s) as a map.
+	Clusters() map[int]Cluster
+
+	// Cluster returns the Cluster with the given ID or nil if it does not exist.
+	Cluster(clusterID int) Cluster
+
+	// ClusterIDByGuild returns the ID of the Cluster which contains the shard of the given guild.
+	ClusterIDByGuild(guildID snowflake.ID) int
+
+	// Stats returns the ClusterStats of all Cluster(s) as a map.
+	Stats() map[int]ClusterStats
+}
+
+// Cluster is a group of consecutive shards managed by a ClusterManager.
+// Reshard is not supported by a Cluster and returns discord.ErrReshardNotSupported.
+type Cluster interface {
+	ShardManager
+
+	// ID returns the ID of the Cluster.
+	ID() int
+
+	// Stats returns the current ClusterStats of the Cluster.
+	Stats() ClusterStats
+}
+
+// ClusterStats is a snapshot of the state of a Cluster.
+type ClusterStats struct {
+	ClusterID int
+	// ShardIDs are the IDs of all shards of the Cluster.
+	ShardIDs []int
+	// ReadyShards is the number of shards which are ready.
+	ReadyShards int
+	// QueuedEvents is the number of events which wait to be dispatched.
+	QueuedEvents int
+	// HandledEvents is the number of events which were dispatched since the Cluster was created.
+	HandledEvents uint64
+	// AverageLatency is the average heartbeat latency of all ready shards.
+	AverageLatency time.Duration
+}
+
+// ClusterIDByShardID returns the cluster ID for the given shardID and shardsPerCluster.
+func ClusterIDByShardID(shardID int, shardsPerCluster int) int {
+	return shardID / shardsPerCluster
+}
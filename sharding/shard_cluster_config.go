@@ -0,0 +1,60 @@
+package sharding
+
+// DefaultClusterConfig returns a ClusterConfig with sensible defaults.
+func DefaultClusterConfig() *ClusterConfig {
+	return &ClusterConfig{
+		ShardsPerCluster: 16,
+		Workers:          4,
+		QueueSize:        256,
+	}
+}
+
+// ClusterConfig lets you configure the Cluster(s) of your ClusterManager.
+type ClusterConfig struct {
+	// ShardsPerCluster is how many consecutive shards are grouped into one Cluster. Defaults to 16.
+	ShardsPerCluster int
+	// Workers is the number of goroutines which dispatch the events of a Cluster. Events of one shard are always dispatched by the same worker in order. Defaults to 4.
+	Workers int
+	// QueueSize is how many events a worker queues before the shards of its Cluster block. Defaults to 256.
+	QueueSize int
+}
+
+// ClusterConfigOpt is a type alias for a function that takes a ClusterConfig and is used to configure your Cluster(s).
+type ClusterConfigOpt func(config *ClusterConfig)
+
+// Apply applies the given ClusterConfigOpt(s) to the ClusterConfig
+func (c *ClusterConfig) Apply(opts []ClusterConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.ShardsPerCluster < 1 {
+		c.ShardsPerCluster = 1
+	}
+	if c.Workers < 1 {
+		c.Workers = 1
+	}
+	if c.QueueSize < 0 {
+		c.QueueSize = 0
+	}
+}
+
+// WithShardsPerCluster sets how many consecutive shards are grouped into one Cluster.
+func WithShardsPerCluster(shardsPerCluster int) ClusterConfigOpt {
+	return func(config *ClusterConfig) {
+		config.ShardsPerCluster = shardsPerCluster
+	}
+}
+
+// WithClusterWorkers sets the number of goroutines which dispatch the events of a Cluster.
+func WithClusterWorkers(workers int) ClusterConfigOpt {
+	return func(config *ClusterConfig) {
+		config.Workers = workers
+	}
+}
+
+// WithClusterQueueSize sets how many events a worker queues before the shards of its Cluster block.
+func WithClusterQueueSize(queueSize int) ClusterConfigOpt {
+	return func(config *ClusterConfig) {
+		config.QueueSize = queueSize
+	}
+}
@@ -0,0 +1,394 @@
+package sharding
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var (
+	_ ClusterManager = (*clusterManagerImpl)(nil)
+	_ Cluster        = (*clusterImpl)(nil)
+)
+
+// NewClusterManager creates a new ClusterManager with the given token, eventHandlerFunc and ConfigOpt(s).
+// The configured shards are grouped into Cluster(s) of ClusterConfig.ShardsPerCluster shards, which share the RateLimiter and IdentifyBudget.
+// A ShardCoordinator and AutoScaling are not supported, and Reshard returns discord.ErrReshardNotSupported.
+// It can be passed to bot.WithShardManagerCreateFunc.
+func NewClusterManager(token string, eventHandlerFunc gateway.EventHandlerFunc, opts ...ConfigOpt) ShardManager {
+	config := DefaultConfig()
+	config.Apply(opts)
+
+	clusterConfig := DefaultClusterConfig()
+	clusterConfig.Apply(config.ClusterConfigOpts)
+
+	m := &clusterManagerImpl{
+		clusters:         map[int]*clusterImpl{},
+		token:            token,
+		eventHandlerFunc: eventHandlerFunc,
+		opts:             opts,
+		config:           *config,
+		clusterConfig:    *clusterConfig,
+	}
+	for shardID := range config.ShardIDs {
+		m.cluster(shardID)
+	}
+	return m
+}
+
+type clusterManagerImpl struct {
+	clusters   map[int]*clusterImpl
+	clustersMu sync.Mutex
+
+	token            string
+	eventHandlerFunc gateway.EventHandlerFunc
+	opts             []ConfigOpt
+	config           Config
+	clusterConfig    ClusterConfig
+}
+
+// cluster returns the cluster of the given shardID and creates it if it does not exist yet.
+func (m *clusterManagerImpl) cluster(shardID int) *clusterImpl {
+	clusterID := ClusterIDByShardID(shardID, m.clusterConfig.ShardsPerCluster)
+
+	m.clustersMu.Lock()
+	defer m.clustersMu.Unlock()
+	if cluster, ok := m.clusters[clusterID]; ok {
+		return cluster
+	}
+
+	shardIDs := map[int]struct{}{}
+	for id := range m.config.ShardIDs {
+		if ClusterIDByShardID(id, m.clusterConfig.ShardsPerCluster) == clusterID {
+			shardIDs[id] = struct{}{}
+		}
+	}
+
+	cluster := &clusterImpl{
+		id:               clusterID,
+		eventHandlerFunc: m.eventHandlerFunc,
+		config:           m.clusterConfig,
+	}
+	opts := make([]ConfigOpt, 0, len(m.opts)+1)
+	opts = append(opts, m.opts...)
+	opts = append(opts, func(config *Config) {
+		config.ShardIDs = shardIDs
+		config.AutoScaling = false
+		config.ShardCoordinator = nil
+		config.RateLimiter = m.config.RateLimiter
+		config.IdentifyBudget = m.config.IdentifyBudget
+	})
+	cluster.ShardManager = New(m.token, cluster.enqueue, opts...)
+	m.clusters[clusterID] = cluster
+	return cluster
+}
+
+// existingCluster returns the cluster of the given shardID or nil if it does not exist.
+func (m *clusterManagerImpl) existingCluster(shardID int) *clusterImpl {
+	m.clustersMu.Lock()
+	defer m.clustersMu.Unlock()
+	return m.clusters[ClusterIDByShardID(shardID, m.clusterConfig.ShardsPerCluster)]
+}
+
+// allClusters returns all clusters sorted by their ID.
+func (m *clusterManagerImpl) allClusters() []*clusterImpl {
+	m.clustersMu.Lock()
+	defer m.clustersMu.Unlock()
+	clusters := make([]*clusterImpl, 0, len(m.clusters))
+	for _, cluster := range m.clusters {
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].id < clusters[j].id
+	})
+	return clusters
+}
+
+func (m *clusterManagerImpl) Open(ctx context.Context) {
+	m.config.Logger.Debugf("opening %d clusters...", len(m.allClusters()))
+	var wg sync.WaitGroup
+	for _, cluster := range m.allClusters() {
+		wg.Add(1)
+		go func(cluster *clusterImpl) {
+			defer wg.Done()
+			cluster.Open(ctx)
+		}(cluster)
+	}
+	wg.Wait()
+}
+
+func (m *clusterManagerImpl) Close(ctx context.Context) {
+	m.close(ctx, (*clusterImpl).Close)
+}
+
+func (m *clusterManagerImpl) CloseResumable(ctx context.Context) {
+	m.close(ctx, (*clusterImpl).CloseResumable)
+}
+
+// close closes all clusters in parallel with the given closeFunc.
+func (m *clusterManagerImpl) close(ctx context.Context, closeFunc func(cluster *clusterImpl, ctx context.Context)) {
+	m.config.Logger.Debugf("closing %d clusters...", len(m.allClusters()))
+	var wg sync.WaitGroup
+	for _, cluster := range m.allClusters() {
+		wg.Add(1)
+		go func(cluster *clusterImpl) {
+			defer wg.Done()
+			closeFunc(cluster, ctx)
+		}(cluster)
+	}
+	wg.Wait()
+}
+
+func (m *clusterManagerImpl) OpenShard(ctx context.Context, shardID int) error {
+	cluster := m.cluster(shardID)
+	cluster.startWorkers()
+	return cluster.OpenShard(ctx, shardID)
+}
+
+func (m *clusterManagerImpl) CloseShard(ctx context.Context, shardID int) {
+	if cluster := m.existingCluster(shardID); cluster != nil {
+		cluster.CloseShard(ctx, shardID)
+	}
+}
+
+func (m *clusterManagerImpl) Reshard(_ context.Context, _ int) error {
+	return discord.ErrReshardNotSupported
+}
+
+func (m *clusterManagerImpl) ShardByGuildID(guildId snowflake.ID) gateway.Gateway {
+	if m.config.ShardCount == 0 {
+		return nil
+	}
+	return m.Shard(ShardIDByGuild(guildId, m.config.ShardCount))
+}
+
+func (m *clusterManagerImpl) Shard(shardID int) gateway.Gateway {
+	if cluster := m.existingCluster(shardID); cluster != nil {
+		return cluster.Shard(shardID)
+	}
+	return nil
+}
+
+func (m *clusterManagerImpl) Shards() map[int]gateway.Gateway {
+	shards := map[int]gateway.Gateway{}
+	for _, cluster := range m.allClusters() {
+		for shardID, shard := range cluster.Shards() {
+			shards[shardID] = shard
+		}
+	}
+	return shards
+}
+
+func (m *clusterManagerImpl) ShardIDs() []int {
+	var shardIDs []int
+	for _, cluster := range m.allClusters() {
+		shardIDs = append(shardIDs, cluster.ShardIDs()...)
+	}
+	sort.Ints(shardIDs)
+	return shardIDs
+}
+
+func (m *clusterManagerImpl) Health() map[int]ShardHealth {
+	health := map[int]ShardHealth{}
+	for _, cluster := range m.allClusters() {
+		for shardID, shardHealth := range cluster.Health() {
+			health[shardID] = shardHealth
+		}
+	}
+	return health
+}
+
+func (m *clusterManagerImpl) Clusters() map[int]Cluster {
+	m.clustersMu.Lock()
+	defer m.clustersMu.Unlock()
+	clusters := make(map[int]Cluster, len(m.clusters))
+	for clusterID, cluster := range m.clusters {
+		clusters[clusterID] = cluster
+	}
+	return clusters
+}
+
+func (m *clusterManagerImpl) Cluster(clusterID int) Cluster {
+	m.clustersMu.Lock()
+	defer m.clustersMu.Unlock()
+	if cluster, ok := m.clusters[clusterID]; ok {
+		return cluster
+	}
+	return nil
+}
+
+func (m *clusterManagerImpl) ClusterIDByGuild(guildID snowflake.ID) int {
+	if m.config.ShardCount == 0 {
+		return 0
+	}
+	return ClusterIDByShardID(ShardIDByGuild(guildID, m.config.ShardCount), m.clusterConfig.ShardsPerCluster)
+}
+
+func (m *clusterManagerImpl) Stats() map[int]ClusterStats {
+	stats := map[int]ClusterStats{}
+	for _, cluster := range m.allClusters() {
+		stats[cluster.id] = cluster.Stats()
+	}
+	return stats
+}
+
+// clusterEvent is an event queued for dispatching by a worker of a Cluster.
+type clusterEvent struct {
+	eventType      gateway.EventType
+	sequenceNumber int
+	shardID        int
+	event          gateway.EventData
+}
+
+type clusterImpl struct {
+	// accessed atomically, keep them first for 64-bit alignment
+	handled uint64
+	queued  int64
+
+	ShardManager
+	id               int
+	eventHandlerFunc gateway.EventHandlerFunc
+	config           ClusterConfig
+
+	// workersMu guards workers, it is never held while waiting for a queue
+	workersMu sync.RWMutex
+	// workers are the running workers, nil if they are not running or stopWorkers was called
+	workers *clusterWorkers
+}
+
+// clusterWorkers are the queues of the workers of a Cluster.
+type clusterWorkers struct {
+	queues []chan clusterEvent
+	// done is closed by stopWorkers, so enqueue stops waiting for full queues
+	done chan struct{}
+	// senders counts the enqueues which might still send to the queues. The queues are closed once all of them are done
+	senders sync.WaitGroup
+}
+
+// enqueue queues the event for the worker of its shard, so events of one shard stay in order.
+// If the workers are not running or are stopped while waiting for a full queue, the event is dispatched directly.
+func (c *clusterImpl) enqueue(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+	e := clusterEvent{eventType: eventType, sequenceNumber: sequenceNumber, shardID: shardID, event: event}
+	c.workersMu.RLock()
+	workers := c.workers
+	if workers != nil {
+		// registered under the lock, so stopWorkers can't close the queues while the event is sent
+		workers.senders.Add(1)
+	}
+	c.workersMu.RUnlock()
+	if workers == nil {
+		c.dispatch(e)
+		return
+	}
+	defer workers.senders.Done()
+
+	atomic.AddInt64(&c.queued, 1)
+	select {
+	case workers.queues[shardID%len(workers.queues)] <- e:
+	case <-workers.done:
+		atomic.AddInt64(&c.queued, -1)
+		c.dispatch(e)
+	}
+}
+
+func (c *clusterImpl) dispatch(e clusterEvent) {
+	c.eventHandlerFunc(e.eventType, e.sequenceNumber, e.shardID, e.event)
+	atomic.AddUint64(&c.handled, 1)
+}
+
+// startWorkers starts the workers of the Cluster if they are not running yet.
+func (c *clusterImpl) startWorkers() {
+	c.workersMu.Lock()
+	defer c.workersMu.Unlock()
+	if c.workers != nil {
+		return
+	}
+	c.workers = &clusterWorkers{
+		queues: make([]chan clusterEvent, c.config.Workers),
+		done:   make(chan struct{}),
+	}
+	for i := range c.workers.queues {
+		queue := make(chan clusterEvent, c.config.QueueSize)
+		c.workers.queues[i] = queue
+		go c.work(queue)
+	}
+}
+
+// work dispatches the events of the queue until it is closed.
+func (c *clusterImpl) work(queue <-chan clusterEvent) {
+	for e := range queue {
+		atomic.AddInt64(&c.queued, -1)
+		c.dispatch(e)
+	}
+}
+
+// stopWorkers stops the workers of the Cluster. Events enqueued afterwards are dispatched directly.
+// The workers exit once they dispatched all queued events, including the ones of enqueues which were already sending.
+// It does not wait for them, so event handlers are free to close the Cluster.
+func (c *clusterImpl) stopWorkers() {
+	c.workersMu.Lock()
+	defer c.workersMu.Unlock()
+	workers := c.workers
+	if workers == nil {
+		return
+	}
+	c.workers = nil
+	close(workers.done)
+	go func() {
+		workers.senders.Wait()
+		for _, queue := range workers.queues {
+			close(queue)
+		}
+	}()
+}
+
+func (c *clusterImpl) ID() int {
+	return c.id
+}
+
+func (c *clusterImpl) Open(ctx context.Context) {
+	c.startWorkers()
+	c.ShardManager.Open(ctx)
+}
+
+func (c *clusterImpl) Close(ctx context.Context) {
+	c.ShardManager.Close(ctx)
+	c.stopWorkers()
+}
+
+func (c *clusterImpl) CloseResumable(ctx context.Context) {
+	c.ShardManager.CloseResumable(ctx)
+	c.stopWorkers()
+}
+
+func (c *clusterImpl) Reshard(_ context.Context, _ int) error {
+	return discord.ErrReshardNotSupported
+}
+
+func (c *clusterImpl) Stats() ClusterStats {
+	stats := ClusterStats{
+		ClusterID:     c.id,
+		ShardIDs:      c.ShardIDs(),
+		QueuedEvents:  int(atomic.LoadInt64(&c.queued)),
+		HandledEvents: atomic.LoadUint64(&c.handled),
+	}
+	var latency time.Duration
+	for _, shard := range c.Shards() {
+		if shard.Status() != gateway.StatusReady {
+			continue
+		}
+		stats.ReadyShards++
+		latency += shard.Latency()
+	}
+	if stats.ReadyShards > 0 {
+		stats.AverageLatency = latency / time.Duration(stats.ReadyShards)
+	}
+	return stats
+}
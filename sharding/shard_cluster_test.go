@@ -0,0 +1,143 @@
+package sharding
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+type readyShard struct {
+	*fakeShard
+}
+
+func (s readyShard) Status() gateway.Status { return gateway.StatusReady }
+func (s readyShard) Latency() time.Duration { return time.Duration(s.shardID+1) * time.Millisecond }
+
+func TestClusterManager(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = map[int][]int{}
+	)
+	m := NewClusterManager("", func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[shardID] = append(received[shardID], sequenceNumber)
+	},
+		WithShardCount(4),
+		WithShardIDs(0, 1, 2, 3),
+		WithRateLimiter(NewNoopRateLimiter()),
+		WithClusterConfigOpts(WithShardsPerCluster(2), WithClusterWorkers(2)),
+		WithGatewayCreateFunc(func(token string, eventHandlerFunc gateway.EventHandlerFunc, closeHandlerFunc gateway.CloseHandlerFunc, opts ...gateway.ConfigOpt) gateway.Gateway {
+			config := gateway.DefaultConfig()
+			config.Apply(opts)
+			return readyShard{&fakeShard{shardID: config.ShardID, shardCount: config.ShardCount, eventHandlerFunc: eventHandlerFunc}}
+		}),
+	).(ClusterManager)
+
+	assert.Len(t, m.Clusters(), 2)
+	assert.Equal(t, []int{0, 1, 2, 3}, m.ShardIDs())
+
+	m.Open(context.Background())
+
+	guildID := snowflake.ID(3 << 22)
+	assert.Equal(t, 3, m.ShardByGuildID(guildID).ShardID())
+	assert.Equal(t, 1, m.ClusterIDByGuild(guildID))
+	assert.Len(t, m.Shards(), 4)
+	assert.ErrorIs(t, m.Reshard(context.Background(), 8), discord.ErrReshardNotSupported)
+	assert.Equal(t, 2, m.Cluster(1).Stats().ReadyShards)
+	assert.Equal(t, 3500*time.Microsecond, m.Cluster(1).Stats().AverageLatency)
+
+	m.Close(context.Background())
+
+	// the workers dispatch the queued events after the clusters are closed
+	assert.Eventually(t, func() bool {
+		stats := m.Stats()
+		return stats[0].HandledEvents == 4 && stats[1].HandledEvents == 4
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// every shard dispatched its Ready & GuildCreate in order
+	mu.Lock()
+	for shardID := 0; shardID < 4; shardID++ {
+		assert.Equal(t, []int{1, 2}, received[shardID])
+	}
+	mu.Unlock()
+
+	stats := m.Stats()
+	assert.Equal(t, []int{2, 3}, stats[1].ShardIDs)
+	assert.Equal(t, 0, stats[1].QueuedEvents)
+}
+
+func TestClusterCloseFromHandler(t *testing.T) {
+	var m ShardManager
+	closed := make(chan struct{})
+	m = NewClusterManager("", func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+		if eventType == gateway.EventTypeReady {
+			// the queue is full while the handler closes the cluster
+			m.Close(context.Background())
+			close(closed)
+		}
+	},
+		WithShardCount(1),
+		WithShardIDs(0),
+		WithRateLimiter(NewNoopRateLimiter()),
+		WithClusterConfigOpts(WithClusterWorkers(1), WithClusterQueueSize(0)),
+		WithGatewayCreateFunc(func(token string, eventHandlerFunc gateway.EventHandlerFunc, closeHandlerFunc gateway.CloseHandlerFunc, opts ...gateway.ConfigOpt) gateway.Gateway {
+			config := gateway.DefaultConfig()
+			config.Apply(opts)
+			return &fakeShard{shardID: config.ShardID, shardCount: config.ShardCount, eventHandlerFunc: eventHandlerFunc}
+		}),
+	)
+	m.Open(context.Background())
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("closing the cluster from an event handler deadlocked")
+	}
+}
+
+func TestClusterStopWorkersKeepsQueuedEvents(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		var handled sync.WaitGroup
+		cluster := &clusterImpl{
+			eventHandlerFunc: func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+				handled.Done()
+			},
+			config: ClusterConfig{Workers: 2, QueueSize: 10},
+		}
+		cluster.startWorkers()
+
+		const events = 20
+		handled.Add(events)
+		var enqueued sync.WaitGroup
+		for j := 0; j < events; j++ {
+			enqueued.Add(1)
+			go func(shardID int) {
+				defer enqueued.Done()
+				cluster.enqueue(gateway.EventTypeMessageCreate, 0, shardID, nil)
+			}(j)
+		}
+		cluster.stopWorkers()
+		enqueued.Wait()
+
+		done := make(chan struct{})
+		go func() {
+			handled.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("queued events were lost when stopping the workers")
+		}
+		assert.Equal(t, int64(0), atomic.LoadInt64(&cluster.queued))
+	}
+}
@@ -0,0 +1,16 @@
+package sharding
+
+import (
+	"context"
+)
+
+// ShardCoordinator hands out shard ID leases to the processes of a cluster, so every shard is connected by exactly one process.
+// Leases of processes which stop renewing them expire and are picked up by the remaining processes.
+type ShardCoordinator interface {
+	// AcquireShards renews the leases of this process and acquires unleased shards up to its fair share of the shardCount.
+	// It returns all shard IDs leased to this process. Shards missing from the result must be closed by the caller.
+	AcquireShards(ctx context.Context, shardCount int) ([]int, error)
+
+	// ReleaseShards releases all leases of this process, so other processes can pick them up immediately.
+	ReleaseShards(ctx context.Context) error
+}
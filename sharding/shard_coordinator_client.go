@@ -0,0 +1,109 @@
+package sharding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+)
+
+var _ CoordinatorClient = (*coordinatorClientImpl)(nil)
+
+// CoordinatorClient is a ShardCoordinator and a cluster wide RateLimiter backed by a CoordinatorServer.
+// Pass it to WithShardCoordinator, the ShardManager then also uses it as its RateLimiter unless you configure another one.
+type CoordinatorClient interface {
+	ShardCoordinator
+	RateLimiter
+}
+
+// NewCoordinatorClient creates a new CoordinatorClient talking to the CoordinatorServer at the given URL with the given CoordinatorClientConfigOpt(s).
+func NewCoordinatorClient(url string, opts ...CoordinatorClientConfigOpt) CoordinatorClient {
+	config := DefaultCoordinatorClientConfig()
+	config.Apply(opts)
+
+	return &coordinatorClientImpl{
+		config: *config,
+		url:    strings.TrimSuffix(url, "/"),
+		tokens: map[int]string{},
+	}
+}
+
+type coordinatorClientImpl struct {
+	config CoordinatorClientConfig
+	url    string
+
+	mu     sync.Mutex
+	tokens map[int]string
+}
+
+func (c *coordinatorClientImpl) do(ctx context.Context, path string, rqBody any, rsBody any) error {
+	body, err := json.Marshal(rqBody)
+	if err != nil {
+		return err
+	}
+	rq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	rq.Header.Set("Content-Type", "application/json")
+
+	rs, err := c.config.HTTPClient.Do(rq)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode != http.StatusOK && rs.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("shard coordinator responded with status %d", rs.StatusCode)
+	}
+	if rsBody == nil {
+		return nil
+	}
+	return json.NewDecoder(rs.Body).Decode(rsBody)
+}
+
+func (c *coordinatorClientImpl) AcquireShards(ctx context.Context, shardCount int) ([]int, error) {
+	var rs coordinatorAcquireResponse
+	if err := c.do(ctx, "/shards/acquire", coordinatorAcquireRequest{Owner: c.config.Owner, ShardCount: shardCount}, &rs); err != nil {
+		return nil, err
+	}
+	return rs.ShardIDs, nil
+}
+
+func (c *coordinatorClientImpl) ReleaseShards(ctx context.Context) error {
+	return c.do(ctx, "/shards/release", coordinatorReleaseRequest{Owner: c.config.Owner}, nil)
+}
+
+func (c *coordinatorClientImpl) Close(_ context.Context) {}
+
+func (c *coordinatorClientImpl) WaitBucket(ctx context.Context, shardID int) error {
+	var rs coordinatorLockResponse
+	if err := c.do(ctx, "/buckets/lock", coordinatorLockRequest{ShardID: shardID}, &rs); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.tokens[shardID] = rs.Token
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *coordinatorClientImpl) UnlockBucket(shardID int) {
+	c.mu.Lock()
+	token, ok := c.tokens[shardID]
+	delete(c.tokens, shardID)
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.do(ctx, "/buckets/unlock", coordinatorUnlockRequest{Token: token}, nil); err != nil {
+		c.config.Logger.Errorf("failed to unlock identify bucket of shard %d: %s", shardID, err)
+	}
+}
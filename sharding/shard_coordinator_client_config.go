@@ -0,0 +1,67 @@
+package sharding
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/disgoorg/log"
+)
+
+// DefaultCoordinatorClientConfig returns a CoordinatorClientConfig with sensible defaults.
+func DefaultCoordinatorClientConfig() *CoordinatorClientConfig {
+	return &CoordinatorClientConfig{
+		Logger:     log.Default(),
+		HTTPClient: &http.Client{},
+		Owner:      defaultCoordinatorOwner(),
+	}
+}
+
+// CoordinatorClientConfig lets you configure your CoordinatorClient instance.
+type CoordinatorClientConfig struct {
+	Logger log.Logger
+	// HTTPClient is the http.Client used to talk to the CoordinatorServer. Requests locking an identify bucket block until it is available, so it should not have a timeout. Defaults to &http.Client{}.
+	HTTPClient *http.Client
+	// Owner identifies this process to the CoordinatorServer and has to be unique in the cluster. Defaults to the hostname, the pid and a random suffix.
+	Owner string
+}
+
+// CoordinatorClientConfigOpt is a type alias for a function that takes a CoordinatorClientConfig and is used to configure your CoordinatorClient.
+type CoordinatorClientConfigOpt func(config *CoordinatorClientConfig)
+
+// Apply applies the given CoordinatorClientConfigOpt(s) to the CoordinatorClientConfig
+func (c *CoordinatorClientConfig) Apply(opts []CoordinatorClientConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithCoordinatorClientLogger sets the logger for the CoordinatorClient.
+func WithCoordinatorClientLogger(logger log.Logger) CoordinatorClientConfigOpt {
+	return func(config *CoordinatorClientConfig) {
+		config.Logger = logger
+	}
+}
+
+// WithCoordinatorClientHTTPClient sets the http.Client used to talk to the CoordinatorServer.
+func WithCoordinatorClientHTTPClient(httpClient *http.Client) CoordinatorClientConfigOpt {
+	return func(config *CoordinatorClientConfig) {
+		config.HTTPClient = httpClient
+	}
+}
+
+// WithCoordinatorClientOwner sets the unique name of this process in the cluster.
+func WithCoordinatorClientOwner(owner string) CoordinatorClientConfigOpt {
+	return func(config *CoordinatorClientConfig) {
+		config.Owner = owner
+	}
+}
+
+func defaultCoordinatorOwner() string {
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,265 @@
+package sharding
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/json"
+)
+
+var _ http.Handler = (*CoordinatorServer)(nil)
+
+type (
+	coordinatorAcquireRequest struct {
+		Owner      string `json:"owner"`
+		ShardCount int    `json:"shard_count"`
+	}
+	coordinatorAcquireResponse struct {
+		ShardIDs []int `json:"shard_ids"`
+	}
+	coordinatorReleaseRequest struct {
+		Owner string `json:"owner"`
+	}
+	coordinatorLockRequest struct {
+		ShardID int `json:"shard_id"`
+	}
+	coordinatorLockResponse struct {
+		Token string `json:"token"`
+	}
+	coordinatorUnlockRequest struct {
+		Token string `json:"token"`
+	}
+)
+
+// NewCoordinatorServer creates a new CoordinatorServer with the given CoordinatorServerConfigOpt(s).
+// It is a http.Handler, serve it with http.ListenAndServe and point the CoordinatorClient(s) of all processes to it.
+func NewCoordinatorServer(opts ...CoordinatorServerConfigOpt) *CoordinatorServer {
+	config := DefaultCoordinatorServerConfig()
+	config.Apply(opts)
+
+	return &CoordinatorServer{
+		config:  *config,
+		leases:  map[int]*shardLease{},
+		members: map[string]time.Time{},
+		buckets: map[int]*coordinatorBucket{},
+		tokens:  map[string]int{},
+	}
+}
+
+// CoordinatorServer is the reference implementation of a central ShardCoordinator and a cluster wide identify RateLimiter.
+// It keeps its state in memory, so all processes have to use the same CoordinatorServer.
+//
+// Every process gets a fair share of the shards. Processes which have more than their fair share, for example because a new process joined,
+// lose their excess shards with their next AcquireShards call. These shards are handed out to other processes after the owner closed them.
+type CoordinatorServer struct {
+	config CoordinatorServerConfig
+
+	mu         sync.Mutex
+	shardCount int
+	leases     map[int]*shardLease
+	members    map[string]time.Time
+	buckets    map[int]*coordinatorBucket
+	tokens     map[string]int
+}
+
+type shardLease struct {
+	owner   string
+	expires time.Time
+	// draining is set when the owner has to close the shard. The lease is freed with the next AcquireShards call of the owner.
+	draining bool
+}
+
+type coordinatorBucket struct {
+	sem   chan struct{}
+	reset time.Time
+	timer *time.Timer
+}
+
+func (s *CoordinatorServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var (
+		rs  any
+		err error
+	)
+	switch r.URL.Path {
+	case "/shards/acquire":
+		var rq coordinatorAcquireRequest
+		if err = json.NewDecoder(r.Body).Decode(&rq); err == nil {
+			rs = coordinatorAcquireResponse{ShardIDs: s.acquire(rq.Owner, rq.ShardCount, time.Now())}
+		}
+
+	case "/shards/release":
+		var rq coordinatorReleaseRequest
+		if err = json.NewDecoder(r.Body).Decode(&rq); err == nil {
+			s.release(rq.Owner)
+		}
+
+	case "/buckets/lock":
+		var rq coordinatorLockRequest
+		if err = json.NewDecoder(r.Body).Decode(&rq); err == nil {
+			var token string
+			if token, err = s.lock(r.Context(), rq.ShardID); err != nil {
+				// the client is gone
+				return
+			}
+			rs = coordinatorLockResponse{Token: token}
+		}
+
+	case "/buckets/unlock":
+		var rq coordinatorUnlockRequest
+		if err = json.NewDecoder(r.Body).Decode(&rq); err == nil {
+			s.unlock(rq.Token)
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		s.config.Logger.Debug("failed to decode coordinator request: ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if rs == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(rs); err != nil {
+		s.config.Logger.Error("failed to write coordinator response: ", err)
+	}
+}
+
+func (s *CoordinatorServer) acquire(owner string, shardCount int, now time.Time) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if shardCount > 0 {
+		s.shardCount = shardCount
+	}
+
+	for shardID, lease := range s.leases {
+		if shardID >= s.shardCount || now.After(lease.expires) || (lease.owner == owner && lease.draining) {
+			delete(s.leases, shardID)
+		}
+	}
+	for member, expires := range s.members {
+		if now.After(expires) {
+			s.config.Logger.Debugf("shard coordinator member %s expired", member)
+			delete(s.members, member)
+		}
+	}
+	expires := now.Add(s.config.LeaseTTL)
+	s.members[owner] = expires
+
+	fairShare := (s.shardCount + len(s.members) - 1) / len(s.members)
+
+	var shardIDs []int
+	for shardID, lease := range s.leases {
+		if lease.owner == owner {
+			lease.expires = expires
+			shardIDs = append(shardIDs, shardID)
+		}
+	}
+	sort.Ints(shardIDs)
+
+	// hand back the highest shards exceeding the fair share
+	for len(shardIDs) > fairShare {
+		s.leases[shardIDs[len(shardIDs)-1]].draining = true
+		shardIDs = shardIDs[:len(shardIDs)-1]
+	}
+
+	for shardID := 0; shardID < s.shardCount && len(shardIDs) < fairShare; shardID++ {
+		if _, ok := s.leases[shardID]; ok {
+			continue
+		}
+		s.leases[shardID] = &shardLease{owner: owner, expires: expires}
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Ints(shardIDs)
+	return shardIDs
+}
+
+func (s *CoordinatorServer) release(owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for shardID, lease := range s.leases {
+		if lease.owner == owner {
+			delete(s.leases, shardID)
+		}
+	}
+	delete(s.members, owner)
+}
+
+func (s *CoordinatorServer) lock(ctx context.Context, shardID int) (string, error) {
+	key := ShardMaxConcurrencyKey(shardID, s.config.MaxConcurrency)
+
+	s.mu.Lock()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &coordinatorBucket{sem: make(chan struct{}, 1)}
+		s.buckets[key] = b
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case b.sem <- struct{}{}:
+	}
+
+	s.mu.Lock()
+	reset := b.reset
+	s.mu.Unlock()
+
+	if until := time.Until(reset); until > 0 {
+		timer := time.NewTimer(until)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			<-b.sem
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	tokenBytes := make([]byte, 16)
+	_, _ = rand.Read(tokenBytes)
+	token := hex.EncodeToString(tokenBytes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token] = key
+	// unlock the bucket in case the process died while identifying
+	b.timer = time.AfterFunc(s.config.LockTTL, func() {
+		s.config.Logger.Warnf("identify bucket %d was not unlocked in time", key)
+		s.unlock(token)
+	})
+	return token, nil
+}
+
+func (s *CoordinatorServer) unlock(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.tokens[token]
+	if !ok {
+		return
+	}
+	delete(s.tokens, token)
+	b := s.buckets[key]
+	b.timer.Stop()
+	b.reset = time.Now().Add(5 * time.Second)
+	<-b.sem
+}
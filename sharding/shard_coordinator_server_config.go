@@ -0,0 +1,66 @@
+package sharding
+
+import (
+	"time"
+
+	"github.com/disgoorg/log"
+)
+
+// DefaultCoordinatorServerConfig returns a CoordinatorServerConfig with sensible defaults.
+func DefaultCoordinatorServerConfig() *CoordinatorServerConfig {
+	return &CoordinatorServerConfig{
+		Logger:         log.Default(),
+		LeaseTTL:       30 * time.Second,
+		LockTTL:        30 * time.Second,
+		MaxConcurrency: 1,
+	}
+}
+
+// CoordinatorServerConfig lets you configure your CoordinatorServer instance.
+type CoordinatorServerConfig struct {
+	Logger log.Logger
+	// LeaseTTL is how long shard leases are valid without being renewed. Processes which do not renew their leases in time are considered dead. Defaults to 30 seconds.
+	LeaseTTL time.Duration
+	// LockTTL is how long an identify bucket stays locked without being unlocked, in case a process dies while identifying. Defaults to 30 seconds.
+	LockTTL time.Duration
+	// MaxConcurrency is the max concurrency of the session start limit of the bot, which is honored across the whole cluster. Defaults to 1.
+	MaxConcurrency int
+}
+
+// CoordinatorServerConfigOpt is a type alias for a function that takes a CoordinatorServerConfig and is used to configure your CoordinatorServer.
+type CoordinatorServerConfigOpt func(config *CoordinatorServerConfig)
+
+// Apply applies the given CoordinatorServerConfigOpt(s) to the CoordinatorServerConfig
+func (c *CoordinatorServerConfig) Apply(opts []CoordinatorServerConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithCoordinatorServerLogger sets the logger for the CoordinatorServer.
+func WithCoordinatorServerLogger(logger log.Logger) CoordinatorServerConfigOpt {
+	return func(config *CoordinatorServerConfig) {
+		config.Logger = logger
+	}
+}
+
+// WithCoordinatorServerLeaseTTL sets how long shard leases are valid without being renewed.
+func WithCoordinatorServerLeaseTTL(leaseTTL time.Duration) CoordinatorServerConfigOpt {
+	return func(config *CoordinatorServerConfig) {
+		config.LeaseTTL = leaseTTL
+	}
+}
+
+// WithCoordinatorServerLockTTL sets how long an identify bucket stays locked without being unlocked.
+func WithCoordinatorServerLockTTL(lockTTL time.Duration) CoordinatorServerConfigOpt {
+	return func(config *CoordinatorServerConfig) {
+		config.LockTTL = lockTTL
+	}
+}
+
+// WithCoordinatorServerMaxConcurrency sets the max concurrency of the session start limit of the bot.
+func WithCoordinatorServerMaxConcurrency(maxConcurrency int) CoordinatorServerConfigOpt {
+	return func(config *CoordinatorServerConfig) {
+		config.MaxConcurrency = maxConcurrency
+	}
+}
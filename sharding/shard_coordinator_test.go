@@ -0,0 +1,146 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+func TestCoordinatorServerAcquire(t *testing.T) {
+	server := NewCoordinatorServer(WithCoordinatorServerLeaseTTL(time.Minute))
+	now := time.Now()
+
+	assert.Equal(t, []int{0, 1, 2, 3}, server.acquire("a", 4, now))
+
+	// b joins, a hands back its excess shards which are freed with its next call
+	assert.Empty(t, server.acquire("b", 4, now))
+	assert.Equal(t, []int{0, 1}, server.acquire("a", 4, now))
+	assert.Equal(t, []int{0, 1}, server.acquire("a", 4, now))
+	assert.Equal(t, []int{2, 3}, server.acquire("b", 4, now))
+
+	// a dies, b picks up its shards
+	later := now.Add(2 * time.Minute)
+	assert.Equal(t, []int{0, 1, 2, 3}, server.acquire("b", 4, later))
+}
+
+func TestCoordinatorClient(t *testing.T) {
+	server := httptest.NewServer(NewCoordinatorServer())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := NewCoordinatorClient(server.URL, WithCoordinatorClientOwner("a"))
+	b := NewCoordinatorClient(server.URL, WithCoordinatorClientOwner("b"))
+
+	shardIDs, err := a.AcquireShards(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, shardIDs)
+
+	assert.NoError(t, a.ReleaseShards(ctx))
+	shardIDs, err = b.AcquireShards(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, shardIDs)
+
+	// the identify bucket is shared across the cluster
+	assert.NoError(t, a.WaitBucket(ctx, 0))
+	lockCtx, lockCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer lockCancel()
+	assert.Error(t, b.WaitBucket(lockCtx, 1))
+	a.UnlockBucket(0)
+}
+
+type outageCoordinator struct {
+	mu          sync.Mutex
+	unavailable bool
+}
+
+func (c *outageCoordinator) SetUnavailable(unavailable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unavailable = unavailable
+}
+
+func (c *outageCoordinator) AcquireShards(_ context.Context, _ int) ([]int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unavailable {
+		return nil, errors.New("coordinator unavailable")
+	}
+	return []int{0}, nil
+}
+
+func (c *outageCoordinator) ReleaseShards(_ context.Context) error {
+	return nil
+}
+
+type leasedShard struct {
+	gateway.Gateway
+	mu     sync.Mutex
+	closed bool
+}
+
+func (s *leasedShard) ShardID() int                 { return 0 }
+func (s *leasedShard) Open(_ context.Context) error { return nil }
+func (s *leasedShard) Close(_ context.Context)      {}
+func (s *leasedShard) CloseWithCode(_ context.Context, _ int, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+func (s *leasedShard) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func TestShardManagerFencesShardsOnCoordinatorOutage(t *testing.T) {
+	coordinator := &outageCoordinator{}
+	var (
+		shardsMu sync.Mutex
+		shards   []*leasedShard
+	)
+	m := New("", func(gateway.EventType, int, int, gateway.EventData) {},
+		WithShardCount(1),
+		WithRateLimiter(NewNoopRateLimiter()),
+		WithShardCoordinator(coordinator),
+		WithShardCoordinatorInterval(10*time.Millisecond),
+		WithShardCoordinatorLeaseTTL(100*time.Millisecond),
+		WithGatewayCreateFunc(func(string, gateway.EventHandlerFunc, gateway.CloseHandlerFunc, ...gateway.ConfigOpt) gateway.Gateway {
+			shard := &leasedShard{}
+			shardsMu.Lock()
+			shards = append(shards, shard)
+			shardsMu.Unlock()
+			return shard
+		}),
+	)
+	m.Open(context.Background())
+	defer m.Close(context.Background())
+	require.Len(t, m.Shards(), 1)
+
+	// the shard is closed before its lease expires and another process takes it over
+	coordinator.SetUnavailable(true)
+	start := time.Now()
+	assert.Eventually(t, func() bool {
+		return len(m.Shards()) == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	shardsMu.Lock()
+	assert.True(t, shards[0].Closed())
+	shardsMu.Unlock()
+
+	// and opened again once the leases can be renewed
+	coordinator.SetUnavailable(false)
+	assert.Eventually(t, func() bool {
+		return len(m.Shards()) == 1
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,197 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// ShardHealth is the health report of a single shard returned by ShardManager.Health.
+type ShardHealth struct {
+	ShardID int
+	Status  gateway.Status
+	// StatusSince is when the shard was first seen in its current Status.
+	StatusSince time.Time
+	Latency     time.Duration
+	// MissedHeartbeatAcks is the number of heartbeats in a row Discord did not acknowledge, across reconnects. It is reset by the next acknowledged heartbeat.
+	MissedHeartbeatAcks int
+	// LastDispatch is when the shard received its last dispatch. It is zero if it did not receive any dispatch yet.
+	LastDispatch time.Time
+	// Restarts is how often the supervisor restarted the shard.
+	Restarts int
+	Healthy  bool
+	// Reason describes why the shard is unhealthy.
+	Reason string
+}
+
+// shardHealthState is what the ShardManager tracks about a shard in addition to what the gateway.Gateway reports.
+type shardHealthState struct {
+	status       gateway.Status
+	statusSince  time.Time
+	lastDispatch time.Time
+	restarts     int
+	restarting   bool
+}
+
+// trackEvent updates the health state of the shard with the given event.
+func (m *shardManagerImpl) trackEvent(eventType gateway.EventType, shardID int, event gateway.EventData) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+
+	state := m.healthState(shardID)
+	switch eventType {
+	case gateway.EventTypeStatusChange:
+		if statusChange, ok := event.(gateway.EventStatusChange); ok && state.status != statusChange.NewStatus {
+			state.status = statusChange.NewStatus
+			state.statusSince = time.Now()
+		}
+	case gateway.EventTypeRaw, gateway.EventTypeHeartbeatAck, gateway.EventTypeIdentifyBudgetLow:
+	default:
+		state.lastDispatch = time.Now()
+	}
+}
+
+// healthState returns the health state of the given shard. healthMu must be held.
+func (m *shardManagerImpl) healthState(shardID int) *shardHealthState {
+	state, ok := m.health[shardID]
+	if !ok {
+		state = &shardHealthState{statusSince: time.Now()}
+		m.health[shardID] = state
+	}
+	return state
+}
+
+func (m *shardManagerImpl) Health() map[int]ShardHealth {
+	now := time.Now()
+	shards := m.Shards()
+
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	health := make(map[int]ShardHealth, len(shards))
+	for shardID, shard := range shards {
+		health[shardID] = m.checkHealth(shard, m.healthState(shardID), now)
+	}
+	return health
+}
+
+// checkHealth reports the health of the given shard. healthMu must be held.
+func (m *shardManagerImpl) checkHealth(shard gateway.Gateway, state *shardHealthState, now time.Time) ShardHealth {
+	status := shard.Status()
+	if status != state.status {
+		state.status = status
+		state.statusSince = now
+	}
+
+	health := ShardHealth{
+		ShardID:             shard.ShardID(),
+		Status:              status,
+		StatusSince:         state.statusSince,
+		Latency:             shard.Latency(),
+		MissedHeartbeatAcks: shard.MissedHeartbeatAcks(),
+		LastDispatch:        state.lastDispatch,
+		Restarts:            state.restarts,
+		Healthy:             true,
+	}
+
+	unhealthy := func(format string, a ...any) ShardHealth {
+		health.Healthy = false
+		health.Reason = fmt.Sprintf(format, a...)
+		return health
+	}
+
+	inStatus := now.Sub(state.statusSince)
+	switch status {
+	case gateway.StatusUnconnected, gateway.StatusDisconnected, gateway.StatusConnecting, gateway.StatusIdentifying:
+		// not opened yet, waiting to reconnect or waiting for the RateLimiter & IdentifyBudget, which may take long without being stuck
+
+	case gateway.StatusWaitingForHello, gateway.StatusResuming, gateway.StatusWaitingForReady:
+		if m.config.HealthStuckTimeout > 0 && inStatus > m.config.HealthStuckTimeout {
+			return unhealthy("stuck in status %s for %s", status, inStatus.Round(time.Second))
+		}
+
+	case gateway.StatusReady:
+		if m.config.HealthMaxMissedHeartbeatAcks > 0 && health.MissedHeartbeatAcks >= m.config.HealthMaxMissedHeartbeatAcks {
+			return unhealthy("%d heartbeats were not acknowledged", health.MissedHeartbeatAcks)
+		}
+		if m.config.HealthMaxLatency > 0 && health.Latency > m.config.HealthMaxLatency {
+			return unhealthy("latency of %s exceeds %s", health.Latency, m.config.HealthMaxLatency)
+		}
+		if m.config.HealthDispatchTimeout > 0 {
+			lastActivity := state.lastDispatch
+			if state.statusSince.After(lastActivity) {
+				lastActivity = state.statusSince
+			}
+			if silent := now.Sub(lastActivity); silent > m.config.HealthDispatchTimeout {
+				return unhealthy("no dispatch received for %s", silent.Round(time.Second))
+			}
+		}
+	}
+	return health
+}
+
+// supervise checks the health of all shards every HealthCheckInterval and restarts unhealthy shards until the context is done.
+func (m *shardManagerImpl) supervise(ctx context.Context) {
+	ticker := time.NewTicker(m.config.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.superviseShards(ctx)
+		}
+	}
+}
+
+func (m *shardManagerImpl) superviseShards(ctx context.Context) {
+	now := time.Now()
+	shards := m.Shards()
+
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	for shardID, shard := range shards {
+		state := m.healthState(shardID)
+		if state.restarting {
+			continue
+		}
+		health := m.checkHealth(shard, state, now)
+		if health.Healthy {
+			continue
+		}
+		m.config.Logger.Warnf("restarting unhealthy shard %d: %s", shardID, health.Reason)
+		state.restarting = true
+		go m.restartShard(ctx, shard)
+	}
+}
+
+// restartShard closes the shard resumable and opens it again once the RateLimiter allows it.
+func (m *shardManagerImpl) restartShard(ctx context.Context, shard gateway.Gateway) {
+	shardID := shard.ShardID()
+	defer func() {
+		m.healthMu.Lock()
+		defer m.healthMu.Unlock()
+		state := m.healthState(shardID)
+		state.restarting = false
+		state.restarts++
+		state.statusSince = time.Now()
+	}()
+
+	shard.CloseWithCode(ctx, websocket.CloseServiceRestart, "Restarting")
+	if err := m.config.RateLimiter.WaitBucket(ctx, shardID); err != nil {
+		m.config.Logger.Errorf("failed to wait shard bucket %d: %s", shardID, err)
+		return
+	}
+	defer m.config.RateLimiter.UnlockBucket(shardID)
+
+	// the shard might have been closed or replaced in the meantime
+	if m.Shard(shardID) != shard {
+		return
+	}
+	if err := shard.Open(ctx); err != nil {
+		m.config.Logger.Errorf("failed to restart shard %d: %s", shardID, err)
+	}
+}
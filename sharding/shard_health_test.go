@@ -0,0 +1,100 @@
+package sharding
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/disgo/gateway/gatewaytest"
+)
+
+type stuckShard struct {
+	gateway.Gateway
+	mu    sync.Mutex
+	opens int
+}
+
+func (s *stuckShard) ShardID() int                                     { return 0 }
+func (s *stuckShard) Status() gateway.Status                           { return gateway.StatusWaitingForReady }
+func (s *stuckShard) Latency() time.Duration                           { return 0 }
+func (s *stuckShard) MissedHeartbeatAcks() int                         { return 0 }
+func (s *stuckShard) Close(_ context.Context)                          {}
+func (s *stuckShard) CloseWithCode(_ context.Context, _ int, _ string) {}
+func (s *stuckShard) Open(_ context.Context) error                     { s.mu.Lock(); s.opens++; s.mu.Unlock(); return nil }
+
+func (s *stuckShard) Opens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opens
+}
+
+func TestShardManagerRestartsStuckShard(t *testing.T) {
+	shard := &stuckShard{}
+	m := New("", func(gateway.EventType, int, int, gateway.EventData) {},
+		WithShardCount(1),
+		WithShardIDs(0),
+		WithRateLimiter(NewNoopRateLimiter()),
+		WithHealthCheckInterval(10*time.Millisecond),
+		WithHealthStuckTimeout(50*time.Millisecond),
+		WithGatewayCreateFunc(func(string, gateway.EventHandlerFunc, gateway.CloseHandlerFunc, ...gateway.ConfigOpt) gateway.Gateway {
+			return shard
+		}),
+	)
+	m.Open(context.Background())
+	defer m.Close(context.Background())
+
+	health := m.Health()[0]
+	assert.True(t, health.Healthy)
+	assert.Equal(t, gateway.StatusWaitingForReady, health.Status)
+
+	assert.Eventually(t, func() bool {
+		return shard.Opens() > 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return m.Health()[0].Restarts > 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestShardHealthRecoversAfterMissedHeartbeatAcks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// every connection is zombied until heartbeats are acknowledged again
+	server := gatewaytest.NewServer(
+		gatewaytest.WithToken("token"),
+		gatewaytest.WithHeartbeatInterval(50*time.Millisecond),
+		gatewaytest.WithAckHeartbeats(false),
+	)
+	defer server.Close()
+
+	m := New("token", func(gateway.EventType, int, int, gateway.EventData) {},
+		WithShardIDs(0),
+		WithShardCount(1),
+		WithRateLimiter(NewNoopRateLimiter()),
+		WithHealthMaxMissedHeartbeatAcks(3),
+		WithGatewayConfigOpts(
+			gateway.WithURL(server.URL()),
+			gateway.WithReconnectPolicy(&gateway.BackoffReconnectPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}),
+		),
+	)
+	m.Open(ctx)
+	defer m.Close(context.Background())
+
+	assert.Eventually(t, func() bool {
+		health := m.Health()[0]
+		return health.MissedHeartbeatAcks >= 3 && health.Status == gateway.StatusReady && !health.Healthy
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// once Discord acknowledges heartbeats again, the shard is healthy again
+	assert.Eventually(t, func() bool {
+		for _, conn := range server.Conns() {
+			conn.SetAckHeartbeats(true)
+		}
+		health := m.Health()[0]
+		return health.MissedHeartbeatAcks == 0 && health.Healthy
+	}, 5*time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,31 @@
+package sharding
+
+import (
+	"github.com/disgoorg/disgo/discord"
+)
+
+// IdentifyBudgetPolicy decides what an IdentifyBudget does when it is low.
+type IdentifyBudgetPolicy int
+
+const (
+	// IdentifyBudgetPolicyDelay delays identifies with a gateway.IdentifyDelayError until the session start limit resets.
+	IdentifyBudgetPolicyDelay IdentifyBudgetPolicy = iota
+
+	// IdentifyBudgetPolicyRefuse refuses identifies with discord.ErrIdentifyBudgetExhausted until the session start limit resets.
+	// The ShardManager reopens refused shards once it resets.
+	IdentifyBudgetPolicyRefuse
+)
+
+// IdentifyBudget tracks how many sessions can still be started until Discord's session start limit resets.
+// Exhausting the session start limit makes Discord reset the token of the bot.
+type IdentifyBudget interface {
+	// Update replaces the tracked budget with the given discord.SessionStartLimit, for example after fetching it from Discord again.
+	Update(limit discord.SessionStartLimit)
+
+	// Take takes one identify from the budget without blocking.
+	// If only the reserve is left, it returns a gateway.IdentifyDelayError until the budget resets or discord.ErrIdentifyBudgetExhausted depending on the IdentifyBudgetPolicy.
+	Take() error
+
+	// Limit returns the current state of the budget. ResetAfter is relative to now.
+	Limit() discord.SessionStartLimit
+}
@@ -0,0 +1,54 @@
+package sharding
+
+import (
+	"github.com/disgoorg/log"
+)
+
+// DefaultIdentifyBudgetConfig returns an IdentifyBudgetConfig with sensible defaults.
+func DefaultIdentifyBudgetConfig() *IdentifyBudgetConfig {
+	return &IdentifyBudgetConfig{
+		Logger:  log.Default(),
+		Reserve: 5,
+		Policy:  IdentifyBudgetPolicyDelay,
+	}
+}
+
+// IdentifyBudgetConfig lets you configure your IdentifyBudget instance.
+type IdentifyBudgetConfig struct {
+	Logger log.Logger
+	// Reserve is the number of identifies which are never used, so the budget is never exhausted completely. Defaults to 5.
+	Reserve int
+	// Policy decides whether identifies are delayed or refused once only the Reserve is left. Defaults to IdentifyBudgetPolicyDelay.
+	Policy IdentifyBudgetPolicy
+}
+
+// IdentifyBudgetConfigOpt is a type alias for a function that takes an IdentifyBudgetConfig and is used to configure your IdentifyBudget.
+type IdentifyBudgetConfigOpt func(config *IdentifyBudgetConfig)
+
+// Apply applies the given IdentifyBudgetConfigOpt(s) to the IdentifyBudgetConfig
+func (c *IdentifyBudgetConfig) Apply(opts []IdentifyBudgetConfigOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithIdentifyBudgetLogger sets the logger for the IdentifyBudget.
+func WithIdentifyBudgetLogger(logger log.Logger) IdentifyBudgetConfigOpt {
+	return func(config *IdentifyBudgetConfig) {
+		config.Logger = logger
+	}
+}
+
+// WithIdentifyBudgetReserve sets the number of identifies which are never used.
+func WithIdentifyBudgetReserve(reserve int) IdentifyBudgetConfigOpt {
+	return func(config *IdentifyBudgetConfig) {
+		config.Reserve = reserve
+	}
+}
+
+// WithIdentifyBudgetPolicy sets whether identifies are delayed or refused once only the reserve is left.
+func WithIdentifyBudgetPolicy(policy IdentifyBudgetPolicy) IdentifyBudgetConfigOpt {
+	return func(config *IdentifyBudgetConfig) {
+		config.Policy = policy
+	}
+}
@@ -0,0 +1,88 @@
+package sharding
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var _ IdentifyBudget = (*identifyBudgetImpl)(nil)
+
+// NewIdentifyBudget creates a new default IdentifyBudget starting at the given discord.SessionStartLimit with the given IdentifyBudgetConfigOpt(s).
+func NewIdentifyBudget(limit discord.SessionStartLimit, opts ...IdentifyBudgetConfigOpt) IdentifyBudget {
+	config := DefaultIdentifyBudgetConfig()
+	config.Apply(opts)
+
+	b := &identifyBudgetImpl{
+		config: *config,
+	}
+	b.Update(limit)
+	return b
+}
+
+type identifyBudgetImpl struct {
+	mu sync.Mutex
+
+	total          int
+	remaining      int
+	maxConcurrency int
+	resetAt        time.Time
+	config         IdentifyBudgetConfig
+}
+
+func (b *identifyBudgetImpl) Update(limit discord.SessionStartLimit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total = limit.Total
+	b.remaining = limit.Remaining
+	b.maxConcurrency = limit.MaxConcurrency
+	b.resetAt = time.Now().Add(time.Duration(limit.ResetAfter) * time.Millisecond)
+}
+
+// reset refills the budget if the reset time passed. Discord resets the session start limit every 24 hours.
+func (b *identifyBudgetImpl) reset(now time.Time) {
+	if b.resetAt.After(now) {
+		return
+	}
+	b.remaining = b.total
+	for !b.resetAt.After(now) {
+		b.resetAt = b.resetAt.Add(24 * time.Hour)
+	}
+}
+
+func (b *identifyBudgetImpl) Take() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.reset(now)
+	if b.remaining > b.config.Reserve {
+		b.remaining--
+		return nil
+	}
+
+	if b.config.Policy == IdentifyBudgetPolicyRefuse {
+		return discord.ErrIdentifyBudgetExhausted
+	}
+
+	retryAfter := b.resetAt.Sub(now)
+	b.config.Logger.Warnf("identify budget is low, delaying identify until the session start limit resets in %s", retryAfter)
+	return gateway.IdentifyDelayError{
+		RetryAfter: retryAfter,
+		Err:        discord.ErrIdentifyBudgetExhausted,
+	}
+}
+
+func (b *identifyBudgetImpl) Limit() discord.SessionStartLimit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.reset(now)
+	return discord.SessionStartLimit{
+		Total:          b.total,
+		Remaining:      b.remaining,
+		ResetAfter:     int(b.resetAt.Sub(now).Milliseconds()),
+		MaxConcurrency: b.maxConcurrency,
+	}
+}
@@ -0,0 +1,40 @@
+package sharding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+func TestIdentifyBudgetReserve(t *testing.T) {
+	budget := NewIdentifyBudget(discord.SessionStartLimit{
+		Total:      1000,
+		Remaining:  2,
+		ResetAfter: int(time.Hour.Milliseconds()),
+	}, WithIdentifyBudgetReserve(1), WithIdentifyBudgetPolicy(IdentifyBudgetPolicyRefuse))
+
+	assert.NoError(t, budget.Take())
+	assert.Equal(t, 1, budget.Limit().Remaining)
+	assert.ErrorIs(t, budget.Take(), discord.ErrIdentifyBudgetExhausted)
+}
+
+func TestIdentifyBudgetDelayUntilReset(t *testing.T) {
+	budget := NewIdentifyBudget(discord.SessionStartLimit{
+		Total:      1000,
+		Remaining:  0,
+		ResetAfter: 50,
+	}, WithIdentifyBudgetReserve(0))
+
+	var delayErr gateway.IdentifyDelayError
+	if assert.ErrorAs(t, budget.Take(), &delayErr) {
+		assert.ErrorIs(t, delayErr, discord.ErrIdentifyBudgetExhausted)
+		assert.LessOrEqual(t, delayErr.RetryAfter, 50*time.Millisecond)
+		time.Sleep(delayErr.RetryAfter)
+	}
+	assert.NoError(t, budget.Take())
+	assert.Equal(t, 999, budget.Limit().Remaining)
+}
@@ -8,13 +8,19 @@ import (
 	"github.com/disgoorg/disgo/gateway"
 )
 
+// CreateFunc is a type that is used to create a new ShardManager(s).
+type CreateFunc func(token string, eventHandlerFunc gateway.EventHandlerFunc, opts ...ConfigOpt) ShardManager
+
 // ShardManager manages multiple gateway.Gateway connections.
 // For more information on sharding see: https://discord.com/developers/docs/topics/gateway#sharding
 type ShardManager interface {
 	// Open opens all configured shards.
 	Open(ctx context.Context)
-	// Close closes all shards.
+	// Close closes all shards. Their sessions are invalidated, so they identify again when opened.
 	Close(ctx context.Context)
+	// CloseResumable closes all shards with websocket.CloseServiceRestart and keeps their sessions, so they resume when opened again.
+	// Use it together with a gateway.SessionStore to resume the shards in a new process after a deployment.
+	CloseResumable(ctx context.Context)
 
 	// OpenShard opens a specific shard.
 	OpenShard(ctx context.Context, shardID int) error
@@ -22,6 +28,16 @@ type ShardManager interface {
 	// CloseShard closes a specific shard.
 	CloseShard(ctx context.Context, shardID int)
 
+	// Reshard opens a new set of shards with the given shard count next to the current shards without dropping events.
+	// Once all new shards received all of their guilds, the new shards replace the current ones, which are closed.
+	// If the context is done before, the new shards are closed and its error is returned. Without a deadline, the ReshardTimeout of the Config applies.
+	//
+	// Events both shard sets receive are deduplicated by their type and ID for the ReshardDeduplicationWindow, which has some limits:
+	// events without an ID, like typing starts or guild member updates, are only passed on by the old shards before the swap and by the new shards after it,
+	// so the ones which only reach the other shard set around the swap are dropped. Different events of the same type and ID, like two updates of a message,
+	// can't be told apart, so an event only one shard set received might be dropped as a duplicate of the other one.
+	Reshard(ctx context.Context, shardCount int) error
+
 	// ShardByGuildID returns the gateway.Gateway for the shard that contains the given guild.
 	ShardByGuildID(guildId snowflake.ID) gateway.Gateway
 
@@ -30,6 +46,12 @@ type ShardManager interface {
 
 	// Shards returns a copy of all shards as a map.
 	Shards() map[int]gateway.Gateway
+
+	// ShardIDs returns the IDs of all shards the ShardManager manages, including shards which are not open yet.
+	ShardIDs() []int
+
+	// Health returns the health report of all shards as a map.
+	Health() map[int]ShardHealth
 }
 
 // ShardIDByGuild returns the shard ID for the given guildID and shardCount.
@@ -1,17 +1,28 @@
 package sharding
 
 import (
+	"time"
+
 	"github.com/disgoorg/log"
 
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/gateway"
 )
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		Logger:            log.Default(),
-		GatewayCreateFunc: gateway.New,
-		ShardSplitCount:   2,
+		Logger:                       log.Default(),
+		GatewayCreateFunc:            gateway.New,
+		ShardSplitCount:              2,
+		IdentifyBudgetWarnThreshold:  50,
+		ShardCoordinatorInterval:     10 * time.Second,
+		ShardCoordinatorLeaseTTL:     30 * time.Second,
+		ReshardDeduplicationWindow:   time.Minute,
+		ReshardTimeout:               10 * time.Minute,
+		ReshardGuildLoadTimeout:      30 * time.Second,
+		HealthStuckTimeout:           2 * time.Minute,
+		HealthMaxMissedHeartbeatAcks: 3,
 	}
 }
 
@@ -25,16 +36,54 @@ type Config struct {
 	ShardCount int
 	// ShardSplitCount is the count a shard should be split into if it is too large. This is only used if AutoScaling is enabled.
 	ShardSplitCount int
-	// AutoScaling will automatically re-shard shards if they are too large. This is disabled by default.
+	// AutoScaling will automatically re-shard into ShardCount * ShardSplitCount shards if a shard is too large. This is disabled by default.
 	AutoScaling bool
+	// ReshardDeduplicationWindow is how long events are deduplicated after resharding. Defaults to 1 minute.
+	ReshardDeduplicationWindow time.Duration
+	// ReshardTimeout is how long re-sharding started by AutoScaling or ShardManager.Reshard with a context without deadline may take. Defaults to 10 minutes.
+	ReshardTimeout time.Duration
+	// ReshardGuildLoadTimeout is how long the new shards wait for the next guild to load while resharding, unloaded guilds are treated as unavailable after it.
+	// 0 waits forever. Defaults to 30 seconds.
+	ReshardGuildLoadTimeout time.Duration
 	// GatewayCreateFunc is the function which is used by the ShardManager to create a new gateway.Gateway. Defaults to gateway.New.
 	GatewayCreateFunc gateway.CreateFunc
 	// GatewayConfigOpts are the ConfigOpt(s) which are applied to the gateway.Gateway.
 	GatewayConfigOpts []gateway.ConfigOpt
+	// SessionStore is the gateway.SessionStore all shards persist their sessions to. Defaults to nil (no persistence).
+	SessionStore gateway.SessionStore
 	// RateLimiter is the RateLimiter which is used by the ShardManager. Defaults to NewRateLimiter()
 	RateLimiter RateLimiter
 	// RateRateLimiterConfigOpts are the RateLimiterConfigOpt(s) which are applied to the RateLimiter.
 	RateRateLimiterConfigOpts []RateLimiterConfigOpt
+	// SessionStartLimit is the discord.SessionStartLimit returned by Discord. If set, its max concurrency is applied to the default RateLimiter
+	// and the default IdentifyBudget starts at it. Defaults to nil.
+	SessionStartLimit *discord.SessionStartLimit
+	// IdentifyBudget tracks the identifies left until the session start limit resets. Defaults to NewIdentifyBudget() if SessionStartLimit is set, otherwise to nil (no tracking).
+	IdentifyBudget IdentifyBudget
+	// IdentifyBudgetConfigOpts are the IdentifyBudgetConfigOpt(s) which are applied to the IdentifyBudget.
+	IdentifyBudgetConfigOpts []IdentifyBudgetConfigOpt
+	// IdentifyBudgetWarnThreshold is the number of identifies left at which the ShardManager starts emitting gateway.EventIdentifyBudgetLow before each identify. Defaults to 50.
+	IdentifyBudgetWarnThreshold int
+	// ShardCoordinator hands out the shards this ShardManager manages when running multiple processes. ShardIDs is ignored if it is set.
+	// If it is also a RateLimiter, like the CoordinatorClient, it is used as the RateLimiter unless another one is configured. Defaults to nil (manage ShardIDs).
+	ShardCoordinator ShardCoordinator
+	// ShardCoordinatorInterval is how often the ShardManager renews its leases with the ShardCoordinator. It has to be shorter than the lease TTL of the ShardCoordinator. Defaults to 10 seconds.
+	ShardCoordinatorInterval time.Duration
+	// ShardCoordinatorLeaseTTL is the lease TTL of the ShardCoordinator. If the leases could not be renewed and might expire before the next renewal,
+	// all shards are closed, as the ShardCoordinator hands them to other processes. Set it to the LeaseTTL of the CoordinatorServer. Defaults to 30 seconds.
+	ShardCoordinatorLeaseTTL time.Duration
+	// HealthCheckInterval is how often the ShardManager checks the health of all shards and restarts unhealthy ones. 0 disables restarts. Defaults to 0.
+	HealthCheckInterval time.Duration
+	// HealthStuckTimeout is how long a shard may wait for Discord's hello, resumed or ready before it is restarted. 0 disables this check. Defaults to 2 minutes.
+	HealthStuckTimeout time.Duration
+	// HealthDispatchTimeout is how long a ready shard may not receive any dispatch before it is restarted. 0 disables this check. Defaults to 0.
+	HealthDispatchTimeout time.Duration
+	// HealthMaxMissedHeartbeatAcks is how many heartbeats in a row may not be acknowledged before a shard is restarted. 0 disables this check. Defaults to 3.
+	HealthMaxMissedHeartbeatAcks int
+	// HealthMaxLatency is the latency above which a shard is restarted. 0 disables this check. Defaults to 0.
+	HealthMaxLatency time.Duration
+	// ClusterConfigOpts are the ClusterConfigOpt(s) which are applied to the Cluster(s) of a ClusterManager. They are ignored by the default ShardManager.
+	ClusterConfigOpts []ClusterConfigOpt
 }
 
 // ConfigOpt is a type alias for a function that takes a Config and is used to configure your Server.
@@ -45,8 +94,18 @@ func (c *Config) Apply(opts []ConfigOpt) {
 	for _, opt := range opts {
 		opt(c)
 	}
+	if rateLimiter, ok := c.ShardCoordinator.(RateLimiter); ok && c.RateLimiter == nil {
+		c.RateLimiter = rateLimiter
+	}
 	if c.RateLimiter == nil {
-		c.RateLimiter = NewRateLimiter(c.RateRateLimiterConfigOpts...)
+		rateLimiterOpts := c.RateRateLimiterConfigOpts
+		if c.SessionStartLimit != nil && c.SessionStartLimit.MaxConcurrency > 0 {
+			rateLimiterOpts = append([]RateLimiterConfigOpt{WithMaxConcurrency(c.SessionStartLimit.MaxConcurrency)}, rateLimiterOpts...)
+		}
+		c.RateLimiter = NewRateLimiter(rateLimiterOpts...)
+	}
+	if c.IdentifyBudget == nil && c.SessionStartLimit != nil {
+		c.IdentifyBudget = NewIdentifyBudget(*c.SessionStartLimit, c.IdentifyBudgetConfigOpts...)
 	}
 }
 
@@ -91,6 +150,27 @@ func WithAutoScaling(autoScaling bool) ConfigOpt {
 	}
 }
 
+// WithReshardDeduplicationWindow sets how long events are deduplicated after resharding.
+func WithReshardDeduplicationWindow(reshardDeduplicationWindow time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.ReshardDeduplicationWindow = reshardDeduplicationWindow
+	}
+}
+
+// WithReshardGuildLoadTimeout sets how long the new shards wait for the next guild to load while resharding. 0 waits forever.
+func WithReshardGuildLoadTimeout(reshardGuildLoadTimeout time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.ReshardGuildLoadTimeout = reshardGuildLoadTimeout
+	}
+}
+
+// WithReshardTimeout sets how long re-sharding started by AutoScaling or ShardManager.Reshard with a context without deadline may take.
+func WithReshardTimeout(reshardTimeout time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.ReshardTimeout = reshardTimeout
+	}
+}
+
 // WithGatewayCreateFunc sets the function which is used by the ShardManager to create a new gateway.Gateway.
 func WithGatewayCreateFunc(gatewayCreateFunc gateway.CreateFunc) ConfigOpt {
 	return func(config *Config) {
@@ -105,6 +185,13 @@ func WithGatewayConfigOpts(opts ...gateway.ConfigOpt) ConfigOpt {
 	}
 }
 
+// WithSessionStore sets the gateway.SessionStore all shards persist their sessions to, so they can resume after a process restart.
+func WithSessionStore(sessionStore gateway.SessionStore) ConfigOpt {
+	return func(config *Config) {
+		config.SessionStore = sessionStore
+	}
+}
+
 // WithRateLimiter lets you inject your own srate.RateLimiter into the ShardManager.
 func WithRateLimiter(rateLimiter RateLimiter) ConfigOpt {
 	return func(config *Config) {
@@ -118,3 +205,96 @@ func WithRateRateLimiterConfigOpt(opts ...RateLimiterConfigOpt) ConfigOpt {
 		config.RateRateLimiterConfigOpts = append(config.RateRateLimiterConfigOpts, opts...)
 	}
 }
+
+// WithSessionStartLimit sets the discord.SessionStartLimit returned by Discord.
+// Its max concurrency is applied to the default RateLimiter and the default IdentifyBudget starts at it.
+func WithSessionStartLimit(sessionStartLimit discord.SessionStartLimit) ConfigOpt {
+	return func(config *Config) {
+		config.SessionStartLimit = &sessionStartLimit
+	}
+}
+
+// WithIdentifyBudget lets you inject your own IdentifyBudget into the ShardManager.
+func WithIdentifyBudget(identifyBudget IdentifyBudget) ConfigOpt {
+	return func(config *Config) {
+		config.IdentifyBudget = identifyBudget
+	}
+}
+
+// WithIdentifyBudgetConfigOpts lets you configure the default IdentifyBudget used by the ShardManager.
+func WithIdentifyBudgetConfigOpts(opts ...IdentifyBudgetConfigOpt) ConfigOpt {
+	return func(config *Config) {
+		config.IdentifyBudgetConfigOpts = append(config.IdentifyBudgetConfigOpts, opts...)
+	}
+}
+
+// WithIdentifyBudgetWarnThreshold sets the number of identifies left at which the ShardManager starts emitting gateway.EventIdentifyBudgetLow.
+func WithIdentifyBudgetWarnThreshold(identifyBudgetWarnThreshold int) ConfigOpt {
+	return func(config *Config) {
+		config.IdentifyBudgetWarnThreshold = identifyBudgetWarnThreshold
+	}
+}
+
+// WithShardCoordinator sets the ShardCoordinator which hands out the shards this ShardManager manages.
+func WithShardCoordinator(shardCoordinator ShardCoordinator) ConfigOpt {
+	return func(config *Config) {
+		config.ShardCoordinator = shardCoordinator
+	}
+}
+
+// WithShardCoordinatorInterval sets how often the ShardManager renews its leases with the ShardCoordinator.
+func WithShardCoordinatorInterval(shardCoordinatorInterval time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.ShardCoordinatorInterval = shardCoordinatorInterval
+	}
+}
+
+// WithShardCoordinatorLeaseTTL sets the lease TTL of the ShardCoordinator after which shards whose leases could not be renewed are closed.
+func WithShardCoordinatorLeaseTTL(shardCoordinatorLeaseTTL time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.ShardCoordinatorLeaseTTL = shardCoordinatorLeaseTTL
+	}
+}
+
+// WithHealthCheckInterval enables restarting unhealthy shards and sets how often the ShardManager checks the health of all shards. 0 disables restarts.
+func WithHealthCheckInterval(healthCheckInterval time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.HealthCheckInterval = healthCheckInterval
+	}
+}
+
+// WithHealthStuckTimeout sets how long a shard may wait for Discord's hello, resumed or ready before it is restarted.
+func WithHealthStuckTimeout(healthStuckTimeout time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.HealthStuckTimeout = healthStuckTimeout
+	}
+}
+
+// WithHealthDispatchTimeout sets how long a ready shard may not receive any dispatch before it is restarted.
+// Bots in few or quiet guilds might legitimately not receive dispatches for a long time.
+func WithHealthDispatchTimeout(healthDispatchTimeout time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.HealthDispatchTimeout = healthDispatchTimeout
+	}
+}
+
+// WithHealthMaxMissedHeartbeatAcks sets how many heartbeats in a row may not be acknowledged before a shard is restarted.
+func WithHealthMaxMissedHeartbeatAcks(healthMaxMissedHeartbeatAcks int) ConfigOpt {
+	return func(config *Config) {
+		config.HealthMaxMissedHeartbeatAcks = healthMaxMissedHeartbeatAcks
+	}
+}
+
+// WithHealthMaxLatency sets the latency above which a shard is restarted.
+func WithHealthMaxLatency(healthMaxLatency time.Duration) ConfigOpt {
+	return func(config *Config) {
+		config.HealthMaxLatency = healthMaxLatency
+	}
+}
+
+// WithClusterConfigOpts lets you configure the Cluster(s) created by NewClusterManager.
+func WithClusterConfigOpts(opts ...ClusterConfigOpt) ConfigOpt {
+	return func(config *Config) {
+		config.ClusterConfigOpts = append(config.ClusterConfigOpts, opts...)
+	}
+}
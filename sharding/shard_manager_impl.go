@@ -2,11 +2,15 @@ package sharding
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/disgoorg/snowflake/v2"
 	"github.com/gorilla/websocket"
 
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/gateway"
 )
 
@@ -19,6 +23,7 @@ func New(token string, eventHandlerFunc gateway.EventHandlerFunc, opts ...Config
 
 	return &shardManagerImpl{
 		shards:           map[int]gateway.Gateway{},
+		health:           map[int]*shardHealthState{},
 		token:            token,
 		eventHandlerFunc: eventHandlerFunc,
 		config:           *config,
@@ -32,93 +37,356 @@ type shardManagerImpl struct {
 	token            string
 	eventHandlerFunc gateway.EventHandlerFunc
 	config           Config
+
+	coordinatorCancel context.CancelFunc
+	coordinatorDone   chan struct{}
+	// leaseRenewed is when the last successful lease renewal with the ShardCoordinator was started
+	leaseRenewed time.Time
+
+	reshardMu  sync.Mutex
+	reshard    *reshardState
+	generation int
+
+	healthMu         sync.Mutex
+	health           map[int]*shardHealthState
+	supervisorCancel context.CancelFunc
+	supervisorDone   chan struct{}
+}
+
+// createShard creates a new gateway.Gateway for the given shardID and shardCount which belongs to the shard set of the given generation.
+func (m *shardManagerImpl) createShard(shardID int, shardCount int, generation int) gateway.Gateway {
+	opts := make([]gateway.ConfigOpt, 0, len(m.config.GatewayConfigOpts)+4)
+	opts = append(opts, m.config.GatewayConfigOpts...)
+	opts = append(opts, gateway.WithShardID(shardID), gateway.WithShardCount(shardCount))
+	if m.config.SessionStore != nil {
+		opts = append(opts, gateway.WithSessionStore(&generationSessionStore{
+			SessionStore: m.config.SessionStore,
+			manager:      m,
+			generation:   generation,
+		}))
+	}
+	if m.config.IdentifyBudget != nil {
+		opts = append(opts, gateway.WithBeforeIdentifyFunc(m.beforeIdentify))
+	}
+	return m.config.GatewayCreateFunc(m.token, m.eventHandler(generation), m.closeHandler, opts...)
+}
+
+// currentGeneration returns the generation of the current shard set. It changes with every Reshard.
+func (m *shardManagerImpl) currentGeneration() int {
+	m.reshardMu.Lock()
+	defer m.reshardMu.Unlock()
+	return m.generation
+}
+
+// eventHandler returns the gateway.EventHandlerFunc for shards of the given generation.
+// While resharding, it drops the events the old and the new shard set both receive.
+func (m *shardManagerImpl) eventHandler(generation int) gateway.EventHandlerFunc {
+	return func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+		m.reshardMu.Lock()
+		reshard, current := m.reshard, m.generation
+		m.reshardMu.Unlock()
+
+		if reshard != nil {
+			if !reshard.allow(generation, eventType, shardID, event) {
+				return
+			}
+		} else if generation != current {
+			// late event of a closed shard set
+			return
+		}
+		if generation == current {
+			m.trackEvent(eventType, shardID, event)
+		}
+		m.eventHandlerFunc(eventType, sequenceNumber, shardID, event)
+	}
+}
+
+// beforeIdentify takes an identify from the IdentifyBudget and warns if it is low.
+func (m *shardManagerImpl) beforeIdentify(_ context.Context, shard gateway.Gateway) error {
+	if limit := m.config.IdentifyBudget.Limit(); limit.Remaining <= m.config.IdentifyBudgetWarnThreshold {
+		m.config.Logger.Warnf("shard %d is identifying with only %d of %d identifies left until the session start limit resets in %s", shard.ShardID(), limit.Remaining, limit.Total, time.Duration(limit.ResetAfter)*time.Millisecond)
+		m.eventHandlerFunc(gateway.EventTypeIdentifyBudgetLow, 0, shard.ShardID(), gateway.EventIdentifyBudgetLow{SessionStartLimit: limit})
+	}
+	return m.config.IdentifyBudget.Take()
 }
 
 func (m *shardManagerImpl) closeHandler(shard gateway.Gateway, err error) {
+	if errors.Is(err, discord.ErrIdentifyBudgetExhausted) {
+		m.reopenAfterBudgetReset(shard)
+		return
+	}
 	if closeError, ok := err.(*websocket.CloseError); !m.config.AutoScaling || !ok || gateway.CloseEventCodeByCode(closeError.Code) != gateway.CloseEventCodeShardingRequired {
 		return
 	}
 	m.config.Logger.Debugf("shard %d requires re-sharding", shard.ShardID())
-	// make sure shard is closed
-	shard.Close(context.TODO())
-
-	m.shardsMu.Lock()
-	defer m.shardsMu.Unlock()
-
-	delete(m.shards, shard.ShardID())
-	delete(m.config.ShardIDs, shard.ShardID())
 
 	newShardCount := shard.ShardCount() * m.config.ShardSplitCount
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.ReshardTimeout)
+		defer cancel()
+		if err := m.Reshard(ctx, newShardCount); err != nil && err != discord.ErrShardManagerResharding {
+			m.config.Logger.Errorf("failed to re-shard into %d shards: %s", newShardCount, err)
+		}
+	}()
+}
 
-	if newShardCount > m.config.ShardCount {
-		m.config.ShardCount = newShardCount
+// reopenAfterBudgetReset reopens a shard whose identify was refused by the IdentifyBudget once the session start limit resets.
+// Shards which were closed or replaced in the meantime are not reopened.
+func (m *shardManagerImpl) reopenAfterBudgetReset(shard gateway.Gateway) {
+	shardID := shard.ShardID()
+	resetAfter := time.Duration(m.config.IdentifyBudget.Limit().ResetAfter) * time.Millisecond
+	m.config.Logger.Warnf("shard %d was refused to identify, reopening it when the session start limit resets in %s", shardID, resetAfter)
+	time.AfterFunc(resetAfter, func() {
+		if m.Shard(shardID) != shard {
+			return
+		}
+		ctx := context.Background()
+		if err := m.config.RateLimiter.WaitBucket(ctx, shardID); err != nil {
+			m.config.Logger.Errorf("failed to wait shard bucket %d: %s", shardID, err)
+			return
+		}
+		defer m.config.RateLimiter.UnlockBucket(shardID)
+		if m.Shard(shardID) != shard {
+			return
+		}
+		if err := shard.Open(ctx); err != nil && err != discord.ErrGatewayAlreadyConnected {
+			m.config.Logger.Errorf("failed to reopen shard %d: %s", shardID, err)
+		}
+	})
+}
+
+func (m *shardManagerImpl) Open(ctx context.Context) {
+	m.startSupervisor()
+	if m.config.ShardCoordinator != nil {
+		m.openCoordinated(ctx)
+		return
 	}
+	var wg sync.WaitGroup
 
-	newShardID := shard.ShardID()
-	var newShardIDs []int
-	for len(newShardIDs) < m.config.ShardSplitCount {
-		newShardIDs = append(newShardIDs, newShardID)
-		newShardID += m.config.ShardSplitCount
+	// don't hold the lock while opening, shards might wait a long time for their identify
+	m.shardsMu.Lock()
+	var shardIDs []int
+	for shardID := range m.config.ShardIDs {
+		if _, ok := m.shards[shardID]; !ok {
+			shardIDs = append(shardIDs, shardID)
+		}
 	}
+	shardCount := m.config.ShardCount
+	m.shardsMu.Unlock()
+	m.config.Logger.Debugf("opening %+v shards...", shardIDs)
 
-	var wg sync.WaitGroup
-	for i := range newShardIDs {
-		shardID := newShardIDs[i]
+	for i := range shardIDs {
+		shardID := shardIDs[i]
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := m.config.RateLimiter.WaitBucket(context.TODO(), shardID); err != nil {
+			if err := m.config.RateLimiter.WaitBucket(ctx, shardID); err != nil {
 				m.config.Logger.Errorf("failed to wait shard bucket %d: %s", shardID, err)
 				return
 			}
 			defer m.config.RateLimiter.UnlockBucket(shardID)
 
-			newShard := m.config.GatewayCreateFunc(m.token, m.eventHandlerFunc, m.closeHandler, append(m.config.GatewayConfigOpts, gateway.WithShardID(shardID), gateway.WithShardCount(newShardCount))...)
-			m.shards[shardID] = newShard
-			if err := newShard.Open(context.TODO()); err != nil {
-				m.config.Logger.Errorf("failed to re shard %d, error: %s", shardID, err)
+			shard := m.createShard(shardID, shardCount, m.currentGeneration())
+			m.shardsMu.Lock()
+			m.shards[shardID] = shard
+			m.shardsMu.Unlock()
+			if err := shard.Open(ctx); err != nil {
+				m.config.Logger.Errorf("failed to open shard %d: %s", shardID, err)
 			}
 		}()
 	}
 	wg.Wait()
-	m.config.Logger.Debugf("re-sharded shard %d into newShards: %d, newShardCount: %d", shard.ShardID(), newShardIDs, newShardCount)
 }
 
-func (m *shardManagerImpl) Open(ctx context.Context) {
-	m.config.Logger.Debugf("opening %+v shards...", m.config.ShardIDs)
+// openCoordinated opens the shards leased from the ShardCoordinator and keeps renewing the leases in the background until the ShardManager is closed.
+// Shards this process loses are closed and shards it gains, for example from a dead process, are opened.
+func (m *shardManagerImpl) openCoordinated(ctx context.Context) {
+	coordinatorCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.shardsMu.Lock()
+	if m.coordinatorCancel != nil {
+		m.shardsMu.Unlock()
+		cancel()
+		return
+	}
+	m.coordinatorCancel = cancel
+	m.coordinatorDone = done
+	m.shardsMu.Unlock()
+
 	var wg sync.WaitGroup
+	if err := m.reconcileShards(ctx, coordinatorCtx, &wg); err != nil {
+		m.config.Logger.Error("failed to acquire shards from shard coordinator: ", err)
+	}
 
-	m.shardsMu.Lock()
-	defer m.shardsMu.Unlock()
-	for shardInt := range m.config.ShardIDs {
-		shardID := shardInt
-		if _, ok := m.shards[shardID]; ok {
-			continue
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(m.config.ShardCoordinatorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-coordinatorCtx.Done():
+				return
+			case <-ticker.C:
+				if err := m.reconcileShards(coordinatorCtx, coordinatorCtx, nil); err != nil {
+					m.config.Logger.Error("failed to renew shards with shard coordinator: ", err)
+					m.fenceShards(coordinatorCtx)
+				}
+			}
 		}
+	}()
+	wg.Wait()
+}
 
+// fenceShards closes all shards once their leases might expire before the next renewal, so no shard is connected by this and another process at once.
+// The shards are closed resumable and are opened again once the leases can be renewed.
+func (m *shardManagerImpl) fenceShards(ctx context.Context) {
+	m.shardsMu.Lock()
+	if len(m.shards) == 0 || time.Since(m.leaseRenewed)+m.config.ShardCoordinatorInterval < m.config.ShardCoordinatorLeaseTTL {
+		m.shardsMu.Unlock()
+		return
+	}
+	shards, leaseRenewed := m.shards, m.leaseRenewed
+	m.shards = map[int]gateway.Gateway{}
+	m.config.ShardIDs = map[int]struct{}{}
+	m.shardsMu.Unlock()
+
+	m.config.Logger.Errorf("shard leases were not renewed for %s, closing %d shards", time.Since(leaseRenewed).Round(time.Second), len(shards))
+	var wg sync.WaitGroup
+	for _, shard := range shards {
 		wg.Add(1)
-		go func() {
+		go func(shard gateway.Gateway) {
 			defer wg.Done()
-			if err := m.config.RateLimiter.WaitBucket(ctx, shardID); err != nil {
-				m.config.Logger.Errorf("failed to wait shard bucket %d: %s", shardID, err)
+			shard.CloseWithCode(ctx, websocket.CloseServiceRestart, "Lease expired")
+		}(shard)
+	}
+	wg.Wait()
+}
+
+// reconcileShards acquires the leased shards from the ShardCoordinator, closes the shards which are no longer leased and starts opening the new ones with openCtx.
+// Opening shards does not block the lease renewal, pass a sync.WaitGroup to wait for them.
+func (m *shardManagerImpl) reconcileShards(ctx context.Context, openCtx context.Context, wg *sync.WaitGroup) error {
+	m.shardsMu.Lock()
+	shardCount := m.config.ShardCount
+	m.shardsMu.Unlock()
+
+	renewed := time.Now()
+	shardIDs, err := m.config.ShardCoordinator.AcquireShards(ctx, shardCount)
+	if err != nil {
+		return err
+	}
+
+	leased := make(map[int]struct{}, len(shardIDs))
+	for _, shardID := range shardIDs {
+		leased[shardID] = struct{}{}
+	}
+
+	m.shardsMu.Lock()
+	m.leaseRenewed = renewed
+	m.config.ShardIDs = leased
+	var closeShards []gateway.Gateway
+	for shardID, shard := range m.shards {
+		if _, ok := leased[shardID]; !ok {
+			closeShards = append(closeShards, shard)
+			delete(m.shards, shardID)
+		}
+	}
+	var openShards []gateway.Gateway
+	for _, shardID := range shardIDs {
+		if _, ok := m.shards[shardID]; !ok {
+			shard := m.createShard(shardID, shardCount, m.currentGeneration())
+			m.shards[shardID] = shard
+			openShards = append(openShards, shard)
+		}
+	}
+	m.shardsMu.Unlock()
+
+	for _, shard := range closeShards {
+		m.config.Logger.Debugf("closing shard %d which is no longer leased", shard.ShardID())
+		shard.Close(ctx)
+	}
+	for i := range openShards {
+		shard := openShards[i]
+		if wg != nil {
+			wg.Add(1)
+		}
+		go func() {
+			if wg != nil {
+				defer wg.Done()
+			}
+			if err := m.config.RateLimiter.WaitBucket(openCtx, shard.ShardID()); err != nil {
+				m.config.Logger.Errorf("failed to wait shard bucket %d: %s", shard.ShardID(), err)
 				return
 			}
-			defer m.config.RateLimiter.UnlockBucket(shardID)
+			defer m.config.RateLimiter.UnlockBucket(shard.ShardID())
 
-			shard := m.config.GatewayCreateFunc(m.token, m.eventHandlerFunc, m.closeHandler, append(m.config.GatewayConfigOpts, gateway.WithShardID(shardID), gateway.WithShardCount(m.config.ShardCount))...)
-			m.shards[shardID] = shard
-			if err := shard.Open(ctx); err != nil {
-				m.config.Logger.Errorf("failed to open shard %d: %s", shardID, err)
+			// the lease might have been lost while waiting
+			if m.Shard(shard.ShardID()) != shard {
+				return
+			}
+			if err := shard.Open(openCtx); err != nil {
+				m.config.Logger.Errorf("failed to open shard %d: %s", shard.ShardID(), err)
 			}
 		}()
 	}
-	wg.Wait()
+	return nil
+}
+
+// startSupervisor starts checking the health of all shards every HealthCheckInterval until the ShardManager is closed.
+func (m *shardManagerImpl) startSupervisor() {
+	if m.config.HealthCheckInterval <= 0 {
+		return
+	}
+	m.shardsMu.Lock()
+	defer m.shardsMu.Unlock()
+	if m.supervisorCancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.supervisorCancel, m.supervisorDone = cancel, done
+	go func() {
+		defer close(done)
+		m.supervise(ctx)
+	}()
 }
 
 func (m *shardManagerImpl) Close(ctx context.Context) {
-	m.config.Logger.Debugf("closing %v shards...", m.config.ShardIDs)
+	m.close(ctx, func(ctx context.Context, shard gateway.Gateway) {
+		shard.Close(ctx)
+	})
+}
+
+func (m *shardManagerImpl) CloseResumable(ctx context.Context) {
+	m.close(ctx, func(ctx context.Context, shard gateway.Gateway) {
+		shard.CloseWithCode(ctx, websocket.CloseServiceRestart, "Restarting")
+	})
+}
+
+// close stops all background goroutines and closes all shards with the given closeFunc.
+func (m *shardManagerImpl) close(ctx context.Context, closeFunc func(ctx context.Context, shard gateway.Gateway)) {
+	m.config.Logger.Debugf("closing %v shards...", m.ShardIDs())
 	var wg sync.WaitGroup
 
+	m.shardsMu.Lock()
+	cancel, done := m.coordinatorCancel, m.coordinatorDone
+	m.coordinatorCancel, m.coordinatorDone = nil, nil
+	supervisorCancel, supervisorDone := m.supervisorCancel, m.supervisorDone
+	m.supervisorCancel, m.supervisorDone = nil, nil
+	m.shardsMu.Unlock()
+	if supervisorCancel != nil {
+		supervisorCancel()
+		<-supervisorDone
+	}
+	if cancel != nil {
+		cancel()
+		<-done
+		defer func() {
+			if err := m.config.ShardCoordinator.ReleaseShards(ctx); err != nil {
+				m.config.Logger.Error("failed to release shards from shard coordinator: ", err)
+			}
+		}()
+	}
+
 	m.shardsMu.Lock()
 	defer m.shardsMu.Unlock()
 	for shardID := range m.shards {
@@ -127,14 +395,17 @@ func (m *shardManagerImpl) Close(ctx context.Context) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			shard.Close(ctx)
+			closeFunc(ctx, shard)
 		}()
 	}
 	wg.Wait()
 }
 
 func (m *shardManagerImpl) OpenShard(ctx context.Context, shardID int) error {
-	return m.openShard(ctx, shardID, m.config.ShardCount)
+	m.shardsMu.Lock()
+	shardCount := m.config.ShardCount
+	m.shardsMu.Unlock()
+	return m.openShard(ctx, shardID, shardCount)
 }
 
 func (m *shardManagerImpl) openShard(ctx context.Context, shardID int, shardCount int) error {
@@ -144,12 +415,12 @@ func (m *shardManagerImpl) openShard(ctx context.Context, shardID int, shardCoun
 		return err
 	}
 	defer m.config.RateLimiter.UnlockBucket(shardID)
-	shard := m.config.GatewayCreateFunc(m.token, m.eventHandlerFunc, m.closeHandler, append(m.config.GatewayConfigOpts, gateway.WithShardID(shardID), gateway.WithShardCount(shardCount))...)
+	shard := m.createShard(shardID, shardCount, m.currentGeneration())
 
 	m.shardsMu.Lock()
-	defer m.shardsMu.Unlock()
 	m.config.ShardIDs[shardID] = struct{}{}
 	m.shards[shardID] = shard
+	m.shardsMu.Unlock()
 	return shard.Open(ctx)
 }
 
@@ -165,9 +436,12 @@ func (m *shardManagerImpl) CloseShard(ctx context.Context, shardID int) {
 }
 
 func (m *shardManagerImpl) ShardByGuildID(guildId snowflake.ID) gateway.Gateway {
+	m.shardsMu.Lock()
 	shardCount := m.config.ShardCount
+	m.shardsMu.Unlock()
+
 	var shard gateway.Gateway
-	for shard == nil || shardCount != 0 {
+	for shard == nil && shardCount != 0 {
 		shard = m.Shard(ShardIDByGuild(guildId, shardCount))
 		shardCount /= m.config.ShardSplitCount
 	}
@@ -187,5 +461,16 @@ func (m *shardManagerImpl) Shards() map[int]gateway.Gateway {
 	for shardID, shard := range m.shards {
 		shards[shardID] = shard
 	}
-	return m.shards
+	return shards
+}
+
+func (m *shardManagerImpl) ShardIDs() []int {
+	m.shardsMu.Lock()
+	defer m.shardsMu.Unlock()
+	shardIDs := make([]int, 0, len(m.config.ShardIDs))
+	for shardID := range m.config.ShardIDs {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Ints(shardIDs)
+	return shardIDs
 }
@@ -0,0 +1,449 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+func (m *shardManagerImpl) Reshard(ctx context.Context, shardCount int) error {
+	if m.config.ShardCoordinator != nil {
+		return discord.ErrReshardNotSupported
+	}
+
+	// don't wait forever for new shards which never receive all of their guilds
+	if _, ok := ctx.Deadline(); !ok && m.config.ReshardTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.ReshardTimeout)
+		defer cancel()
+	}
+
+	m.shardsMu.Lock()
+	oldShardCount := m.config.ShardCount
+	shardIDs, err := reshardShardIDs(m.config.ShardIDs, oldShardCount, shardCount)
+	m.shardsMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	m.reshardMu.Lock()
+	if m.reshard != nil {
+		m.reshardMu.Unlock()
+		return discord.ErrShardManagerResharding
+	}
+	r := newReshardState(m.generation, m.generation+1, len(shardIDs), m.config.ReshardDeduplicationWindow, m.config.ReshardGuildLoadTimeout)
+	m.reshard = r
+	m.reshardMu.Unlock()
+
+	m.config.Logger.Debugf("resharding from %d to %d shards...", oldShardCount, shardCount)
+
+	newShards := make(map[int]gateway.Gateway, len(shardIDs))
+	for _, shardID := range shardIDs {
+		newShards[shardID] = m.createShard(shardID, shardCount, r.newGeneration)
+	}
+
+	abort := func(err error) error {
+		m.config.Logger.Errorf("failed to reshard to %d shards: %s", shardCount, err)
+		r.stop()
+		m.closeShards(context.TODO(), newShards)
+		m.reshardMu.Lock()
+		m.reshard = nil
+		m.reshardMu.Unlock()
+		return err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		openErr error
+	)
+	for i := range shardIDs {
+		shard := newShards[shardIDs[i]]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.config.RateLimiter.WaitBucket(ctx, shard.ShardID()); err != nil {
+				errOnce.Do(func() { openErr = err })
+				return
+			}
+			defer m.config.RateLimiter.UnlockBucket(shard.ShardID())
+			if err := shard.Open(ctx); err != nil {
+				errOnce.Do(func() { openErr = fmt.Errorf("failed to open shard %d: %w", shard.ShardID(), err) })
+			}
+		}()
+	}
+	wg.Wait()
+	if openErr != nil {
+		return abort(openErr)
+	}
+
+	// wait until every new shard received all of its guilds
+	select {
+	case <-ctx.Done():
+		return abort(ctx.Err())
+	case <-r.warm:
+	}
+
+	m.shardsMu.Lock()
+	oldShards := m.shards
+	m.shards = newShards
+	m.config.ShardCount = shardCount
+	m.config.ShardIDs = make(map[int]struct{}, len(shardIDs))
+	for _, shardID := range shardIDs {
+		m.config.ShardIDs[shardID] = struct{}{}
+	}
+	m.shardsMu.Unlock()
+
+	m.reshardMu.Lock()
+	m.generation = r.newGeneration
+	m.reshardMu.Unlock()
+	r.swap()
+
+	m.healthMu.Lock()
+	m.health = map[int]*shardHealthState{}
+	m.healthMu.Unlock()
+
+	m.closeShards(ctx, oldShards)
+	m.config.Logger.Debugf("resharded from %d to %d shards", oldShardCount, shardCount)
+
+	// keep deduplicating events the old shards already dispatched for a while
+	time.AfterFunc(m.config.ReshardDeduplicationWindow, func() {
+		m.reshardMu.Lock()
+		defer m.reshardMu.Unlock()
+		if m.reshard == r {
+			m.reshard = nil
+		}
+	})
+	return nil
+}
+
+// closeShards closes the given shards in parallel.
+func (m *shardManagerImpl) closeShards(ctx context.Context, shards map[int]gateway.Gateway) {
+	var wg sync.WaitGroup
+	for shardID := range shards {
+		shard := shards[shardID]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shard.Close(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// reshardShardIDs returns the shard IDs of the new shard count which contain the guilds of the given shard IDs.
+// If the shard IDs cover all shards, all shards of the new shard count are returned.
+func reshardShardIDs(shardIDs map[int]struct{}, shardCount int, newShardCount int) ([]int, error) {
+	if newShardCount <= 0 {
+		return nil, fmt.Errorf("invalid shard count %d", newShardCount)
+	}
+
+	all := shardCount <= 0 || len(shardIDs) == 0
+	if !all {
+		all = true
+		for shardID := 0; shardID < shardCount; shardID++ {
+			if _, ok := shardIDs[shardID]; !ok {
+				all = false
+				break
+			}
+		}
+	}
+
+	var newShardIDs []int
+	if all {
+		for shardID := 0; shardID < newShardCount; shardID++ {
+			newShardIDs = append(newShardIDs, shardID)
+		}
+		return newShardIDs, nil
+	}
+
+	// guilds of the shard x % shardCount move to the shard x if the new shard count is a multiple of the old one
+	if newShardCount%shardCount != 0 {
+		return nil, fmt.Errorf("new shard count %d has to be a multiple of %d when only managing some shards", newShardCount, shardCount)
+	}
+	for shardID := 0; shardID < newShardCount; shardID++ {
+		if _, ok := shardIDs[shardID%shardCount]; ok {
+			newShardIDs = append(newShardIDs, shardID)
+		}
+	}
+	return newShardIDs, nil
+}
+
+func newReshardState(oldGeneration int, newGeneration int, shards int, deduplicationWindow time.Duration, guildLoadTimeout time.Duration) *reshardState {
+	return &reshardState{
+		oldGeneration:       oldGeneration,
+		newGeneration:       newGeneration,
+		shards:              shards,
+		guildLoadTimeout:    guildLoadTimeout,
+		pendingGuilds:       map[int]map[snowflake.ID]struct{}{},
+		guildTimers:         map[int]*time.Timer{},
+		warmShards:          map[int]struct{}{},
+		warm:                make(chan struct{}),
+		deduplicationWindow: deduplicationWindow,
+		seen:                map[int]map[eventKey]int{},
+	}
+}
+
+// reshardState filters the events of the old and the new shards while both are connected.
+//
+// Until the swap, the old shards keep the caches warm and the events of the new shards are dropped,
+// while the READY & GUILD_CREATE events the new shards receive for their guilds are tracked to know when they are warm.
+// Unavailable guilds do not block the swap, every guild has to load within the guild load timeout.
+//
+// After the swap, the events of each generation are deduplicated against the events the other generation passed on,
+// until the deduplication window has passed. Events are identified by their type and ID, events without an ID are only passed on for the active shards.
+type reshardState struct {
+	oldGeneration int
+	newGeneration int
+	shards        int
+
+	guildLoadTimeout time.Duration
+
+	mu            sync.Mutex
+	swapped       bool
+	pendingGuilds map[int]map[snowflake.ID]struct{}
+	guildTimers   map[int]*time.Timer
+	warmShards    map[int]struct{}
+	warm          chan struct{}
+
+	deduplicationWindow time.Duration
+	seen                map[int]map[eventKey]int
+	seenQueue           []seenEvent
+}
+
+// eventKey identifies an event by its type and ID.
+type eventKey struct {
+	eventType gateway.EventType
+	id        snowflake.ID
+}
+
+type seenEvent struct {
+	generation int
+	key        eventKey
+	time       time.Time
+}
+
+func (r *reshardState) swap() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.swapped = true
+	r.stopGuildTimers()
+}
+
+// stop stops waiting for the guilds of the new shards.
+func (r *reshardState) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopGuildTimers()
+}
+
+func (r *reshardState) stopGuildTimers() {
+	for shardID, timer := range r.guildTimers {
+		timer.Stop()
+		delete(r.guildTimers, shardID)
+	}
+}
+
+// allow returns whether the event of a shard of the given generation should be passed on.
+func (r *reshardState) allow(generation int, eventType gateway.EventType, shardID int, event gateway.EventData) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.oldGeneration && generation != r.newGeneration {
+		return false
+	}
+
+	if !r.swapped {
+		if generation == r.newGeneration {
+			r.trackGuilds(shardID, event)
+			return false
+		}
+		r.markSeen(generation, eventType, event)
+		return true
+	}
+
+	active := generation == r.newGeneration
+	key, ok := newEventKey(eventType, event)
+	if !ok {
+		return active
+	}
+	if r.consumeSeen(r.otherGeneration(generation), key) {
+		return false
+	}
+	r.markSeen(generation, eventType, event)
+	return true
+}
+
+// trackGuilds tracks the guilds the new shard receives until all of them loaded.
+func (r *reshardState) trackGuilds(shardID int, event gateway.EventData) {
+	switch e := event.(type) {
+	case gateway.EventReady:
+		guilds := make(map[snowflake.ID]struct{}, len(e.Guilds))
+		for _, guild := range e.Guilds {
+			guilds[guild.ID] = struct{}{}
+		}
+		r.pendingGuilds[shardID] = guilds
+		r.resetGuildTimer(shardID)
+		r.checkWarm(shardID)
+
+	case gateway.EventGuildCreate:
+		r.guildLoaded(shardID, e.ID)
+
+	case gateway.EventGuildDelete:
+		// unavailable guilds won't load until their outage is over
+		if e.Unavailable {
+			r.guildLoaded(shardID, e.ID)
+		}
+	}
+}
+
+func (r *reshardState) guildLoaded(shardID int, guildID snowflake.ID) {
+	guilds, ok := r.pendingGuilds[shardID]
+	if !ok {
+		return
+	}
+	if _, ok = guilds[guildID]; !ok {
+		return
+	}
+	delete(guilds, guildID)
+	r.resetGuildTimer(shardID)
+	r.checkWarm(shardID)
+}
+
+// resetGuildTimer restarts the guild load timeout of the shard, which treats all pending guilds of the shard as loaded once it passed.
+func (r *reshardState) resetGuildTimer(shardID int) {
+	if timer, ok := r.guildTimers[shardID]; ok {
+		timer.Stop()
+		delete(r.guildTimers, shardID)
+	}
+	if r.guildLoadTimeout <= 0 || len(r.pendingGuilds[shardID]) == 0 {
+		return
+	}
+	guilds := r.pendingGuilds[shardID]
+	r.guildTimers[shardID] = time.AfterFunc(r.guildLoadTimeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.swapped || r.pendingGuilds[shardID] == nil {
+			return
+		}
+		for guildID := range guilds {
+			delete(guilds, guildID)
+		}
+		r.checkWarm(shardID)
+	})
+}
+
+func (r *reshardState) checkWarm(shardID int) {
+	if len(r.pendingGuilds[shardID]) > 0 {
+		return
+	}
+	if _, ok := r.warmShards[shardID]; ok {
+		return
+	}
+	r.warmShards[shardID] = struct{}{}
+	if len(r.warmShards) == r.shards {
+		close(r.warm)
+	}
+}
+
+func (r *reshardState) otherGeneration(generation int) int {
+	if generation == r.newGeneration {
+		return r.oldGeneration
+	}
+	return r.newGeneration
+}
+
+// newEventKey returns the eventKey of the event if it has an ID.
+func newEventKey(eventType gateway.EventType, event gateway.EventData) (eventKey, bool) {
+	switch eventType {
+	case gateway.EventTypeRaw, gateway.EventTypeHeartbeatAck, gateway.EventTypeStatusChange, gateway.EventTypeIdentifyBudgetLow, gateway.EventTypeResumed:
+		// these events are specific to a connection
+		return eventKey{}, false
+	}
+	v := reflect.ValueOf(event)
+	if v.Kind() != reflect.Struct {
+		return eventKey{}, false
+	}
+	field := v.FieldByName("ID")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(snowflake.ID(0)) || field.IsZero() {
+		return eventKey{}, false
+	}
+	return eventKey{eventType: eventType, id: field.Interface().(snowflake.ID)}, true
+}
+
+// markSeen remembers that the event of the given generation was passed on.
+func (r *reshardState) markSeen(generation int, eventType gateway.EventType, event gateway.EventData) {
+	key, ok := newEventKey(eventType, event)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	r.expireSeen(now)
+
+	seen, ok := r.seen[generation]
+	if !ok {
+		seen = map[eventKey]int{}
+		r.seen[generation] = seen
+	}
+	seen[key]++
+	r.seenQueue = append(r.seenQueue, seenEvent{generation: generation, key: key, time: now})
+}
+
+// consumeSeen returns whether the given generation passed on an event with the same key within the deduplication window.
+// Every passed on event only deduplicates a single event of the other generation, so multiple updates of the same entity are not dropped.
+func (r *reshardState) consumeSeen(generation int, key eventKey) bool {
+	r.expireSeen(time.Now())
+	seen := r.seen[generation]
+	if seen[key] == 0 {
+		return false
+	}
+	if seen[key]--; seen[key] == 0 {
+		delete(seen, key)
+	}
+	return true
+}
+
+func (r *reshardState) expireSeen(now time.Time) {
+	for len(r.seenQueue) > 0 && now.Sub(r.seenQueue[0].time) > r.deduplicationWindow {
+		e := r.seenQueue[0]
+		r.seenQueue = r.seenQueue[1:]
+		seen := r.seen[e.generation]
+		if seen[e.key] == 0 {
+			continue
+		}
+		if seen[e.key]--; seen[e.key] == 0 {
+			delete(seen, e.key)
+		}
+	}
+}
+
+var _ gateway.SessionStore = (*generationSessionStore)(nil)
+
+// generationSessionStore only lets the shards of the current generation change the shared gateway.SessionStore,
+// as the old and the new shards with the same ID would overwrite and delete each other's Session while resharding.
+type generationSessionStore struct {
+	gateway.SessionStore
+	manager    *shardManagerImpl
+	generation int
+}
+
+func (s *generationSessionStore) SetSession(shardID int, session gateway.Session) error {
+	if s.manager.currentGeneration() != s.generation {
+		return nil
+	}
+	return s.SessionStore.SetSession(shardID, session)
+}
+
+func (s *generationSessionStore) DeleteSession(shardID int) error {
+	if s.manager.currentGeneration() != s.generation {
+		return nil
+	}
+	return s.SessionStore.DeleteSession(shardID)
+}
@@ -0,0 +1,149 @@
+package sharding
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+type fakeShard struct {
+	gateway.Gateway
+	shardID          int
+	shardCount       int
+	eventHandlerFunc gateway.EventHandlerFunc
+	onOpen           func()
+	closed           bool
+}
+
+func (s *fakeShard) ShardID() int    { return s.shardID }
+func (s *fakeShard) ShardCount() int { return s.shardCount }
+
+func (s *fakeShard) Open(_ context.Context) error {
+	if s.onOpen != nil {
+		s.onOpen()
+	}
+	guildID := snowflake.ID(s.shardID + 1)
+	s.eventHandlerFunc(gateway.EventTypeReady, 1, s.shardID, gateway.EventReady{Guilds: []discord.UnavailableGuild{{ID: guildID}}})
+	var guildCreate gateway.EventGuildCreate
+	guildCreate.ID = guildID
+	s.eventHandlerFunc(gateway.EventTypeGuildCreate, 2, s.shardID, guildCreate)
+	return nil
+}
+
+func (s *fakeShard) Close(_ context.Context) {
+	s.closed = true
+}
+
+func (s *fakeShard) dispatch(messageID snowflake.ID) {
+	s.eventHandlerFunc(gateway.EventTypeMessageDelete, 3, s.shardID, gateway.EventMessageDelete{ID: messageID})
+}
+
+func TestShardManagerReshard(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []gateway.EventType
+		deleted  []snowflake.ID
+		shards   []*fakeShard
+	)
+	m := New("", func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, eventType)
+		if e, ok := event.(gateway.EventMessageDelete); ok {
+			deleted = append(deleted, e.ID)
+		}
+	},
+		WithShardCount(1),
+		WithShardIDs(0),
+		WithRateLimiter(NewNoopRateLimiter()),
+		WithGatewayCreateFunc(func(token string, eventHandlerFunc gateway.EventHandlerFunc, closeHandlerFunc gateway.CloseHandlerFunc, opts ...gateway.ConfigOpt) gateway.Gateway {
+			config := gateway.DefaultConfig()
+			config.Apply(opts)
+			shard := &fakeShard{shardID: config.ShardID, shardCount: config.ShardCount, eventHandlerFunc: eventHandlerFunc}
+			if len(shards) > 0 {
+				// the old shard receives an event while the new shards connect
+				shard.onOpen = func() { shards[0].dispatch(snowflake.ID(shard.shardID + 1)) }
+			}
+			shards = append(shards, shard)
+			return shard
+		}),
+	)
+	m.Open(context.Background())
+	oldShard := shards[0]
+	received = nil
+	deleted = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, m.Reshard(ctx, 2))
+	assert.True(t, oldShard.closed)
+	assert.Len(t, m.Shards(), 2)
+
+	// events are only passed on once by either the old or the new shards
+	shards[1].dispatch(1)
+	shards[1].dispatch(3)
+	oldShard.dispatch(3)
+	oldShard.dispatch(4)
+
+	assert.Equal(t, []gateway.EventType{gateway.EventTypeMessageDelete, gateway.EventTypeMessageDelete, gateway.EventTypeMessageDelete, gateway.EventTypeMessageDelete}, received)
+	assert.ElementsMatch(t, []snowflake.ID{1, 2, 3, 4}, deleted)
+	assert.ErrorIs(t, m.Reshard(ctx, 4), discord.ErrShardManagerResharding)
+}
+
+func TestReshardStateGuildLoadTimeout(t *testing.T) {
+	r := newReshardState(0, 1, 1, time.Minute, 50*time.Millisecond)
+	defer r.stop()
+
+	assert.False(t, r.allow(1, gateway.EventTypeReady, 0, gateway.EventReady{Guilds: []discord.UnavailableGuild{{ID: 1}, {ID: 2}, {ID: 3}}}))
+	var guildCreate gateway.EventGuildCreate
+	guildCreate.ID = 1
+	assert.False(t, r.allow(1, gateway.EventTypeGuildCreate, 0, guildCreate))
+	var guildDelete gateway.EventGuildDelete
+	guildDelete.ID = 2
+	guildDelete.Unavailable = true
+	assert.False(t, r.allow(1, gateway.EventTypeGuildDelete, 0, guildDelete))
+
+	// guild 3 never loads
+	select {
+	case <-r.warm:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shard did not become warm after the guild load timeout")
+	}
+}
+
+// coldShard never receives its guilds.
+type coldShard struct {
+	*fakeShard
+}
+
+func (s coldShard) Open(_ context.Context) error {
+	s.eventHandlerFunc(gateway.EventTypeReady, 1, s.shardID, gateway.EventReady{Guilds: []discord.UnavailableGuild{{ID: snowflake.ID(s.shardID + 1)}}})
+	return nil
+}
+
+func TestShardManagerReshardTimeout(t *testing.T) {
+	m := New("", func(eventType gateway.EventType, sequenceNumber int, shardID int, event gateway.EventData) {},
+		WithShardCount(1),
+		WithShardIDs(0),
+		WithRateLimiter(NewNoopRateLimiter()),
+		WithReshardGuildLoadTimeout(0),
+		WithReshardTimeout(50*time.Millisecond),
+		WithGatewayCreateFunc(func(token string, eventHandlerFunc gateway.EventHandlerFunc, closeHandlerFunc gateway.CloseHandlerFunc, opts ...gateway.ConfigOpt) gateway.Gateway {
+			config := gateway.DefaultConfig()
+			config.Apply(opts)
+			return coldShard{&fakeShard{shardID: config.ShardID, shardCount: config.ShardCount, eventHandlerFunc: eventHandlerFunc}}
+		}),
+	)
+	m.Open(context.Background())
+
+	// the context has no deadline, so the ReshardTimeout applies
+	assert.ErrorIs(t, m.Reshard(context.Background(), 2), context.DeadlineExceeded)
+	assert.Len(t, m.Shards(), 1)
+}
@@ -0,0 +1,66 @@
+package sharding
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/disgo/gateway/gatewaytest"
+)
+
+func TestShardManagerCloseResumable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := gatewaytest.NewServer(gatewaytest.WithToken("token"))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "sessions.json")
+
+	ready := make(chan struct{}, 1)
+	newShardManager := func() ShardManager {
+		// the session store is loaded from the file like after a process restart
+		sessionStore, err := gateway.NewFileSessionStore(path, time.Hour)
+		require.NoError(t, err)
+		return New("token", func(eventType gateway.EventType, _ int, _ int, _ gateway.EventData) {
+			if eventType == gateway.EventTypeReady {
+				ready <- struct{}{}
+			}
+		},
+			WithShardIDs(0),
+			WithShardCount(1),
+			WithRateLimiter(NewNoopRateLimiter()),
+			WithSessionStore(sessionStore),
+			WithGatewayConfigOpts(gateway.WithURL(server.URL())),
+		)
+	}
+
+	m := newShardManager()
+	m.Open(ctx)
+	conn, err := server.Accept(ctx)
+	require.NoError(t, err)
+	_, err = conn.Expect(ctx, gateway.OpcodeIdentify)
+	require.NoError(t, err)
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		t.Fatal("shard did not become ready")
+	}
+	sessionID := conn.SessionID()
+	m.CloseResumable(ctx)
+
+	// a new shard manager, like after a deployment, resumes the session from the store
+	m = newShardManager()
+	m.Open(ctx)
+	defer m.Close(context.Background())
+	conn, err = server.Accept(ctx)
+	require.NoError(t, err)
+	resume, err := conn.Expect(ctx, gateway.OpcodeResume)
+	require.NoError(t, err)
+	assert.Equal(t, sessionID, resume.D.(gateway.MessageDataResume).SessionID)
+}
@@ -69,10 +69,11 @@ func (r *rateLimiterImpl) getBucket(shardID int, create bool) *bucket {
 
 func (r *rateLimiterImpl) WaitBucket(ctx context.Context, shardID int) error {
 	b := r.getBucket(shardID, true)
-	r.config.Logger.Debugf("locking shard bucket: Key: %d, Reset: %s", b.Key, b.Reset)
+	r.config.Logger.Debugf("locking shard bucket: Key: %d", b.Key)
 	if err := b.mu.CLock(ctx); err != nil {
 		return err
 	}
+	r.config.Logger.Debugf("locked shard bucket: Key: %d, Reset: %s", b.Key, b.Reset)
 
 	var until time.Time
 	now := time.Now()